@@ -2,59 +2,237 @@ package main
 
 import (
 	"fmt"
+	"path"
+	"sort"
 	"strings"
 
+	http_client "github.com/getfrontierhq/buf-public-apis/gen/go/http_client"
 	pgs "github.com/lyft/protoc-gen-star/v2"
+	pgsgo "github.com/lyft/protoc-gen-star/v2/lang/go"
 )
 
-// parseClientConfig extracts configuration from the client= parameter.
-// Expected format: "package:subdir"
-// Example: "vendors.iniciador:client"
+// runtimePackage is the import path of the HTTP client runtime shared by all
+// the generated clients.
+const runtimePackage = "github.com/getfrontierhq/buf-public-apis/pkg/gohttp/httpclient"
+
+// parseClientConfigs extracts the configuration of every client root.
+//
+// Roots come from the client= parameter and from the (http_client.client)
+// file option of the given files, so a single run can generate several
+// clients.
+//
+// The client= parameter lists roots separated by semicolons, each in the
+// format "package:subdir".
+// Example: "vendors.iniciador:vendors/iniciador/client;vendors.other:vendors/other/client"
+//
+// The file option roots the client at the file's proto package.
+// Example: option (http_client.client) = {output_dir: "vendors/iniciador/client"};
 //
 // Optional parameter: go_module_path=github.com/org/repo/pkg/go
-// If not provided, will attempt to infer from common conventions.
-func parseClientConfig(params pgs.Parameters) (*ClientConfig, error) {
-	clientParam := params.Str("client")
-	if clientParam == "" {
-		return nil, fmt.Errorf("no client configuration specified (use: client=package:subdir)")
-	}
-
-	// Split on colon: "vendors.iniciador:client"
-	parts := strings.SplitN(clientParam, ":", 2)
-	if len(parts) != 2 {
-		return nil, fmt.Errorf("invalid client format: %s (expected: package:subdir)", clientParam)
-	}
-
-	rootPackage := strings.TrimSpace(parts[0])
-	outputSubdir := strings.TrimSpace(parts[1])
-
-	// Derive client name from last part of package
-	// "vendors.iniciador" -> "IniciadorClient"
-	packageParts := strings.Split(rootPackage, ".")
-	lastPart := packageParts[len(packageParts)-1]
-	clientName := strings.ToUpper(lastPart[:1]) + lastPart[1:] + "Client"
-
-	// Get Go module path (can be overridden with go_module_path parameter)
-	goModulePath := params.Str("go_module_path")
-	if goModulePath == "" {
-		// Default assumption: github.com/getfrontierhq/schema/pkg/go
-		// This should be made configurable via parameter
-		goModulePath = "github.com/getfrontierhq/schema/pkg/go"
-	}
-
-	// Compute HTTP client package path
-	// go_module_path + "/" + output_subdir + "/http"
-	// Example: github.com/getfrontierhq/schema/pkg/go/vendors/iniciador/httpclient/client/http
-	httpClientPkg := fmt.Sprintf("%s/%s/http",
-		goModulePath,
-		outputSubdir,
-	)
-
-	return &ClientConfig{
-		RootPackage:   rootPackage,
-		OutputSubdir:  outputSubdir,
-		ClientName:    clientName,
-		GoModulePath:  goModulePath,
-		HTTPClientPkg: httpClientPkg,
-	}, nil
+// Go import path of the output directory, used only when it cannot be
+// derived from the go_package of the service files (see resolveImportPaths).
+//
+// Optional parameter: mock=true
+// Also generates a clientmock package next to each client, with a
+// programmable fake for every generated interface.
+//
+// Optional parameter: fakeserver=true
+// Also generates a clienttest package next to each client, with an
+// httptest-backed fake server and typed handlers per RPC.
+//
+// Optional parameters: omitempty=false, omitempty_prefix=/rpc
+// Same as protoc-gen-go-http: methods without google.api.http annotation
+// are generated with a POST <omitempty_prefix>/<full.Service>/<Method>
+// route instead of being skipped, so client and server agree on it.
+func parseClientConfigs(params pgs.Parameters, files []pgs.File) ([]*ClientConfig, error) {
+	var cfgs []*ClientConfig
+
+	// Roots from the client= parameter: "vendors.iniciador:client;vendors.other:other"
+	for _, clientParam := range strings.Split(params.Str("client"), ";") {
+		if strings.TrimSpace(clientParam) == "" {
+			continue
+		}
+
+		// Split on colon: "vendors.iniciador:client"
+		parts := strings.SplitN(clientParam, ":", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid client format: %s (expected: package:subdir)", clientParam)
+		}
+
+		cfgs = append(cfgs, &ClientConfig{
+			RootPackage:  strings.TrimSpace(parts[0]),
+			OutputSubdir: strings.TrimSpace(parts[1]),
+		})
+	}
+
+	// Roots from the (http_client.client) file option
+	for _, file := range files {
+		var opts http_client.ClientOptions
+		ok, err := file.Extension(http_client.E_Client, &opts)
+		if err != nil {
+			return nil, fmt.Errorf("%s: invalid http_client.client option: %w", file.InputPath(), err)
+		}
+		if !ok {
+			continue
+		}
+		if opts.GetOutputDir() == "" {
+			return nil, fmt.Errorf("%s: http_client.client option has no output_dir", file.InputPath())
+		}
+
+		cfgs = append(cfgs, &ClientConfig{
+			RootPackage:  file.Package().ProtoName().String(),
+			OutputSubdir: opts.GetOutputDir(),
+			ClientName:   opts.GetName(),
+		})
+	}
+
+	if len(cfgs) == 0 {
+		return nil, fmt.Errorf("no client configuration specified (use: client=package:subdir or the http_client.client file option)")
+	}
+
+	mock, err := params.BoolDefault("mock", false)
+	if err != nil {
+		return nil, fmt.Errorf("invalid mock parameter: %w", err)
+	}
+
+	fakeServer, err := params.BoolDefault("fakeserver", false)
+	if err != nil {
+		return nil, fmt.Errorf("invalid fakeserver parameter: %w", err)
+	}
+
+	omitempty, err := params.BoolDefault("omitempty", true)
+	if err != nil {
+		return nil, fmt.Errorf("invalid omitempty parameter: %w", err)
+	}
+
+	roots := map[string]*ClientConfig{}
+	outputs := map[string]*ClientConfig{}
+	for _, cfg := range cfgs {
+		if cfg.RootPackage == "" || cfg.OutputSubdir == "" {
+			return nil, fmt.Errorf("invalid client %s:%s (expected: package:subdir)", cfg.RootPackage, cfg.OutputSubdir)
+		}
+		if prev, ok := roots[cfg.RootPackage]; ok && prev.OutputSubdir == cfg.OutputSubdir && (prev.ClientName == cfg.ClientName || cfg.ClientName == "") {
+			// Same root declared by several files of the package
+			continue
+		}
+		if _, ok := roots[cfg.RootPackage]; ok {
+			return nil, fmt.Errorf("client root %s is declared twice with different settings", cfg.RootPackage)
+		}
+		if prev, ok := outputs[cfg.OutputSubdir]; ok {
+			return nil, fmt.Errorf("clients %s and %s share the output directory %s", prev.RootPackage, cfg.RootPackage, cfg.OutputSubdir)
+		}
+
+		// Derive client name from last part of package
+		// "vendors.iniciador" -> "IniciadorClient", "vendors.open_finance" -> "OpenFinanceClient"
+		if cfg.ClientName == "" {
+			packageParts := strings.Split(cfg.RootPackage, ".")
+			cfg.ClientName = goName(packageParts[len(packageParts)-1]) + "Client"
+		}
+		if err := validateNaming(cfg.ClientName, ""); err != nil {
+			return nil, fmt.Errorf("client %s: invalid name: %w", cfg.RootPackage, err)
+		}
+
+		cfg.HTTPClientPkg = runtimePackage
+		cfg.GoModulePath = params.Str("go_module_path")
+		cfg.Mock = mock
+		cfg.FakeServer = fakeServer
+		cfg.OmitEmpty = omitempty
+		cfg.OmitEmptyPrefix = params.Str("omitempty_prefix")
+
+		roots[cfg.RootPackage] = cfg
+		outputs[cfg.OutputSubdir] = cfg
+	}
+
+	// Deduplicated roots, sorted for deterministic output
+	cfgs = cfgs[:0]
+	for _, cfg := range roots {
+		cfgs = append(cfgs, cfg)
+	}
+	sort.Slice(cfgs, func(i, j int) bool {
+		return cfgs[i].RootPackage < cfgs[j].RootPackage
+	})
+	return cfgs, nil
+}
+
+// ownsService reports whether the service belongs to this client, i.e. the
+// client has the most specific root containing the service's package among
+// all the clients.
+//
+// Example: with roots vendors.iniciador and vendors.iniciador.investments,
+// services in vendors.iniciador.investments.funds belong to the latter only.
+func (cfg *ClientConfig) ownsService(svc Service, cfgs []*ClientConfig) bool {
+	if !inPackage(svc.Package, cfg.RootPackage) {
+		return false
+	}
+	for _, other := range cfgs {
+		if len(other.RootPackage) > len(cfg.RootPackage) && inPackage(svc.Package, other.RootPackage) {
+			return false
+		}
+	}
+	return true
+}
+
+// inPackage reports whether pkg is rootPkg or one of its sub-packages.
+func inPackage(pkg, rootPkg string) bool {
+	return pkg == rootPkg || strings.HasPrefix(pkg, rootPkg+".")
+}
+
+// resolveImportPaths computes the Go import path of the generated client
+// package (ClientPkg) from the output directory's import path.
+//
+// The client is written to OutputSubdir, relative to the plugin output
+// directory, whose import path depends on the paths mode:
+//   - paths=source_relative: the import path of each service file minus its
+//     proto directory, e.g., go_package "example.com/pkg/go/vendors/iniciador"
+//     for vendors/iniciador/links.proto → "example.com/pkg/go"
+//   - paths=import with module=<prefix>: the module prefix
+//   - paths=import: none, files are written to their full import path, so
+//     OutputSubdir must be an import path itself (e.g., "example.com/pkg/go/client")
+//
+// go_module_path is used only when the import path cannot be derived, i.e.
+// source_relative files whose go_package doesn't mirror their directory.
+func (cfg *ClientConfig) resolveImportPaths(params pgs.Parameters, services []Service) error {
+	outputPkg, err := outputImportPath(params, services)
+	if err != nil {
+		if cfg.GoModulePath == "" {
+			return fmt.Errorf("cannot resolve the Go import path of %s: %w (set go_module_path to the import path of the output directory)", cfg.OutputSubdir, err)
+		}
+		outputPkg = cfg.GoModulePath
+	}
+
+	cfg.ClientPkg = path.Join(outputPkg, cfg.OutputSubdir)
+	return nil
+}
+
+// outputImportPath derives the Go import path of the plugin output directory
+// (see resolveImportPaths).
+func outputImportPath(params pgs.Parameters, services []Service) (string, error) {
+	if pgsgo.Paths(params) != pgsgo.SourceRelative {
+		return params.Str("module"), nil
+	}
+
+	var outputPkg string
+	for _, svc := range services {
+		dir := path.Dir(svc.ProtoFile)
+
+		var pkg string
+		switch {
+		case dir == ".":
+			pkg = svc.GoPackage
+		case strings.HasSuffix(svc.GoPackage, "/"+dir):
+			pkg = strings.TrimSuffix(svc.GoPackage, "/"+dir)
+		default:
+			return "", fmt.Errorf("%s: go_package %q does not end with the file directory %q", svc.ProtoFile, svc.GoPackage, dir)
+		}
+
+		if outputPkg != "" && pkg != outputPkg {
+			return "", fmt.Errorf("%s: go_package %q is not under %q like the other service files", svc.ProtoFile, svc.GoPackage, outputPkg)
+		}
+		outputPkg = pkg
+	}
+
+	if outputPkg == "" {
+		return "", fmt.Errorf("no service files")
+	}
+	return outputPkg, nil
 }
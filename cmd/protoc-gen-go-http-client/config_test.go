@@ -0,0 +1,194 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	pgs "github.com/lyft/protoc-gen-star/v2"
+)
+
+func TestResolveImportPaths(t *testing.T) {
+	links := Service{ProtoFile: "vendors/iniciador/links.proto", GoPackage: "example.com/pkg/go/vendors/iniciador"}
+	funds := Service{ProtoFile: "vendors/iniciador/investments/funds.proto", GoPackage: "example.com/pkg/go/vendors/iniciador/investments"}
+	flat := Service{ProtoFile: "vendors/iniciador/auth.proto", GoPackage: "example.com/sdk/iniciadorpb"}
+
+	tests := []struct {
+		name     string
+		params   pgs.Parameters
+		services []Service
+		expected string // ClientPkg, "" if resolution fails
+	}{
+		{
+			name:     "source relative",
+			params:   pgs.Parameters{"paths": "source_relative"},
+			services: []Service{links, funds},
+			expected: "example.com/pkg/go/vendors/iniciador/client",
+		},
+		{
+			name:     "source relative ignores go_module_path",
+			params:   pgs.Parameters{"paths": "source_relative", "go_module_path": "github.com/other/repo"},
+			services: []Service{links},
+			expected: "example.com/pkg/go/vendors/iniciador/client",
+		},
+		{
+			name:     "source relative falls back to go_module_path",
+			params:   pgs.Parameters{"paths": "source_relative", "go_module_path": "example.com/sdk"},
+			services: []Service{flat},
+			expected: "example.com/sdk/vendors/iniciador/client",
+		},
+		{
+			name:     "source relative without go_module_path",
+			params:   pgs.Parameters{"paths": "source_relative"},
+			services: []Service{flat},
+		},
+		{
+			name:     "source relative with inconsistent go_package",
+			params:   pgs.Parameters{"paths": "source_relative"},
+			services: []Service{links, {ProtoFile: "vendors/iniciador/investments/funds.proto", GoPackage: "example.com/other/vendors/iniciador/investments"}},
+		},
+		{
+			name:     "import with module prefix",
+			params:   pgs.Parameters{"module": "example.com/pkg/go"},
+			services: []Service{flat},
+			expected: "example.com/pkg/go/vendors/iniciador/client",
+		},
+		{
+			name:     "import",
+			params:   pgs.Parameters{},
+			services: []Service{flat},
+			expected: "vendors/iniciador/client",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &ClientConfig{OutputSubdir: "vendors/iniciador/client", GoModulePath: tt.params.Str("go_module_path")}
+			err := cfg.resolveImportPaths(tt.params, tt.services)
+			if tt.expected == "" {
+				if err == nil {
+					t.Fatalf("resolveImportPaths() = %q, want error", cfg.ClientPkg)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cfg.ClientPkg != tt.expected {
+				t.Errorf("resolveImportPaths() = %q, want %q", cfg.ClientPkg, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseClientConfigs(t *testing.T) {
+	tests := []struct {
+		name     string
+		client   string
+		expected []string // "package:subdir:name", "" on error
+	}{
+		{
+			name:     "single root",
+			client:   "vendors.iniciador:vendors/iniciador/client",
+			expected: []string{"vendors.iniciador:vendors/iniciador/client:IniciadorClient"},
+		},
+		{
+			name:   "several roots",
+			client: "vendors.other:vendors/other/client;vendors.iniciador:vendors/iniciador/client",
+			expected: []string{
+				"vendors.iniciador:vendors/iniciador/client:IniciadorClient",
+				"vendors.other:vendors/other/client:OtherClient",
+			},
+		},
+		{
+			name:     "sanitized name",
+			client:   "vendors.open_finance:vendors/open_finance/client",
+			expected: []string{"vendors.open_finance:vendors/open_finance/client:OpenFinanceClient"},
+		},
+		{
+			name:     "shared output directory",
+			client:   "vendors.iniciador:client;vendors.other:client",
+			expected: []string{""},
+		},
+		{
+			name:     "invalid format",
+			client:   "vendors.iniciador",
+			expected: []string{""},
+		},
+		{
+			name:     "no root",
+			expected: []string{""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfgs, err := parseClientConfigs(pgs.Parameters{"client": tt.client}, nil)
+			if tt.expected[0] == "" {
+				if err == nil {
+					t.Fatalf("parseClientConfigs() = %v, want error", cfgs)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var result []string
+			for _, cfg := range cfgs {
+				result = append(result, cfg.RootPackage+":"+cfg.OutputSubdir+":"+cfg.ClientName)
+			}
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("parseClientConfigs() = %v, want %v", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseClientConfigsOmitEmpty(t *testing.T) {
+	client := "vendors.iniciador:vendors/iniciador/client"
+
+	cfgs, err := parseClientConfigs(pgs.Parameters{"client": client}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cfgs[0].OmitEmpty || cfgs[0].OmitEmptyPrefix != "" {
+		t.Errorf("default OmitEmpty, OmitEmptyPrefix = %v, %q, want true, \"\"", cfgs[0].OmitEmpty, cfgs[0].OmitEmptyPrefix)
+	}
+
+	cfgs, err = parseClientConfigs(pgs.Parameters{"client": client, "omitempty": "false", "omitempty_prefix": "/rpc"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfgs[0].OmitEmpty || cfgs[0].OmitEmptyPrefix != "/rpc" {
+		t.Errorf("OmitEmpty, OmitEmptyPrefix = %v, %q, want false, \"/rpc\"", cfgs[0].OmitEmpty, cfgs[0].OmitEmptyPrefix)
+	}
+
+	if _, err := parseClientConfigs(pgs.Parameters{"client": client, "omitempty": "maybe"}, nil); err == nil {
+		t.Error("parseClientConfigs() with omitempty=maybe succeeded, want error")
+	}
+}
+
+func TestOwnsService(t *testing.T) {
+	iniciador := &ClientConfig{RootPackage: "vendors.iniciador"}
+	investments := &ClientConfig{RootPackage: "vendors.iniciador.investments"}
+	cfgs := []*ClientConfig{iniciador, investments}
+
+	tests := []struct {
+		pkg      string
+		expected *ClientConfig
+	}{
+		{"vendors.iniciador", iniciador},
+		{"vendors.iniciador.links", iniciador},
+		{"vendors.iniciador.investments", investments},
+		{"vendors.iniciador.investments.funds", investments},
+		{"vendors.iniciadorx", nil},
+	}
+
+	for _, tt := range tests {
+		for _, cfg := range cfgs {
+			if owns := cfg.ownsService(Service{Package: tt.pkg}, cfgs); owns != (cfg == tt.expected) {
+				t.Errorf("%s.ownsService(%s) = %v", cfg.RootPackage, tt.pkg, owns)
+			}
+		}
+	}
+}
@@ -1,48 +1,99 @@
 package main
 
 import (
+	"fmt"
+	"go/token"
 	"sort"
 	"strings"
 
+	http_client "github.com/getfrontierhq/buf-public-apis/gen/go/http_client"
 	pgs "github.com/lyft/protoc-gen-star/v2"
+	pgsgo "github.com/lyft/protoc-gen-star/v2/lang/go"
 )
 
 // extractServices finds all services under the specified root package.
 // Only services whose package starts with rootPackage are included.
-func extractServices(files []pgs.File, rootPackage string) []Service {
+//
+// Go import paths and type names are resolved through the Go context, so
+// messages from other packages (e.g., google.protobuf.Empty) and nested
+// messages are referenced correctly. It fails when the Go import path of a
+// service or message file cannot be resolved (see goPackage).
+//
+// Methods without google.api.http annotation are skipped, unless omitempty
+// is false: like protoc-gen-go-http, they are then routed to
+// POST <omitemptyPrefix>/<full.Service>/<Method> (see defaultHTTPInfo).
+// Unannotated streaming methods are never routed.
+func extractServices(ctx pgsgo.Context, files []pgs.File, rootPackage string, omitempty bool, omitemptyPrefix string) ([]Service, error) {
 	var services []Service
 
 	for _, file := range files {
 		pkg := file.Package().ProtoName().String()
 
-		// Only include services under the root package
-		if !strings.HasPrefix(pkg, rootPackage) {
+		// Only include services in the root package or its sub-packages
+		if !inPackage(pkg, rootPackage) || len(file.Services()) == 0 {
 			continue
 		}
 
+		goPkg, _, err := goPackage(ctx, file)
+		if err != nil {
+			return nil, err
+		}
+
 		// Extract all services from this file
 		for _, svc := range file.Services() {
 			service := Service{
 				Name:      svc.Name().String(),
 				Package:   pkg,
-				GoPackage: file.InputPath().Base(),
+				ProtoFile: file.InputPath().String(),
+				GoPackage: goPkg,
+			}
+
+			// Name overrides from the (http_client.service) option
+			var naming http_client.ServiceNaming
+			if _, err := svc.Extension(http_client.E_Service, &naming); err != nil {
+				return nil, fmt.Errorf("%s: invalid http_client.service option: %w", svc.FullyQualifiedName(), err)
+			}
+			if err := validateNaming(naming.GetAccessor(), naming.GetFileName()); err != nil {
+				return nil, fmt.Errorf("%s: invalid http_client.service option: %w", svc.FullyQualifiedName(), err)
 			}
+			service.Accessor = naming.GetAccessor()
+			service.FileName = naming.GetFileName()
 
 			// Extract all methods from this service
 			for _, method := range svc.Methods() {
+				input, err := goType(ctx, method.Input())
+				if err != nil {
+					return nil, err
+				}
+				output, err := goType(ctx, method.Output())
+				if err != nil {
+					return nil, err
+				}
+
 				m := Method{
 					Name:       method.Name().String(),
 					InputType:  method.Input().Name().String(),
 					OutputType: method.Output().Name().String(),
+					Input:      input,
+					Output:     output,
 				}
 
 				// Extract HTTP annotation if present
 				httpInfo, err := extractHTTPInfo(method)
-				if err == nil {
+				switch {
+				case err == nil:
 					m.HTTP = httpInfo
+				case !omitempty && !hasHTTPAnnotation(method) && !method.ClientStreaming() && !method.ServerStreaming():
+					m.HTTP = defaultHTTPInfo(method, omitemptyPrefix)
 				}
 				// Note: It's OK if HTTP info is missing - not all methods have HTTP annotations
 
+				if m.HTTP != nil {
+					if m.HTTP.UnwrapResponse, err = unwrapResponse(method); err != nil {
+						return nil, err
+					}
+				}
+
 				service.Methods = append(service.Methods, m)
 			}
 
@@ -63,5 +114,109 @@ func extractServices(files []pgs.File, rootPackage string) []Service {
 		return services[i].Package < services[j].Package
 	})
 
-	return services
+	return services, nil
+}
+
+// goType resolves the Go import path, package name and type name of a message.
+func goType(ctx pgsgo.Context, msg pgs.Message) (GoType, error) {
+	path, name, err := goPackage(ctx, msg.File())
+	if err != nil {
+		return GoType{}, err
+	}
+
+	return GoType{
+		ImportPath:  path,
+		PackageName: name,
+		Name:        ctx.Name(msg).String(),
+	}, nil
+}
+
+// goPackage resolves the Go import path and package name of a proto file the
+// way protoc-gen-go does: an M<file>=<import path> parameter takes precedence
+// over the go_package option (which buf managed mode sets).
+//
+// Files with neither have no importable Go package, which fails the generation
+// rather than producing imports that don't compile.
+func goPackage(ctx pgsgo.Context, file pgs.File) (path, name string, err error) {
+	if mapped, ok := pgsgo.MappedImport(ctx.Params(), file.InputPath().String()); ok {
+		path, name = splitGoPackage(mapped)
+		return path, name, nil
+	}
+
+	if file.Descriptor().GetOptions().GetGoPackage() == "" {
+		return "", "", fmt.Errorf("%s: cannot resolve the Go import path: set option go_package or pass M%s=<import path>",
+			file.InputPath(), file.InputPath())
+	}
+
+	return ctx.ImportPath(file).String(), ctx.PackageName(file).String(), nil
+}
+
+// splitGoPackage splits a go_package value into its import path and package name.
+//
+// Examples:
+//   - "example.com/pkg/go/vendors/iniciador" → "example.com/pkg/go/vendors/iniciador", "iniciador"
+//   - "example.com/pkg/go/vendors/iniciador;iniciadorpb" → "example.com/pkg/go/vendors/iniciador", "iniciadorpb"
+func splitGoPackage(goPackage string) (path, name string) {
+	path, name, ok := strings.Cut(goPackage, ";")
+	if !ok {
+		name = path[strings.LastIndex(path, "/")+1:]
+	}
+
+	// Package names must be identifiers: "go-sdk" → "go_sdk"
+	name = strings.Map(func(r rune) rune {
+		if r == '_' || ('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z') || ('0' <= r && r <= '9') {
+			return r
+		}
+		return '_'
+	}, name)
+	return path, name
+}
+
+// extractGroupNaming collects the (http_client.group) file options, keyed by
+// proto package. Files of the same package must not set different overrides.
+func extractGroupNaming(files []pgs.File) (map[string]GroupNaming, error) {
+	naming := make(map[string]GroupNaming)
+	declared := make(map[string]pgs.File)
+
+	for _, file := range files {
+		var opts http_client.GroupNaming
+		ok, err := file.Extension(http_client.E_Group, &opts)
+		if err != nil {
+			return nil, fmt.Errorf("%s: invalid http_client.group option: %w", file.InputPath(), err)
+		}
+		if !ok {
+			continue
+		}
+		if err := validateNaming(opts.GetAccessor(), opts.GetFileName(), opts.GetTypeName()); err != nil {
+			return nil, fmt.Errorf("%s: invalid http_client.group option: %w", file.InputPath(), err)
+		}
+
+		pkg := file.Package().ProtoName().String()
+		group := GroupNaming{
+			Accessor: opts.GetAccessor(),
+			TypeName: opts.GetTypeName(),
+			FileName: opts.GetFileName(),
+		}
+		if prev, ok := declared[pkg]; ok && naming[pkg] != group {
+			return nil, fmt.Errorf("%s: http_client.group option differs from %s for package %s", file.InputPath(), prev.InputPath(), pkg)
+		}
+		naming[pkg] = group
+		declared[pkg] = file
+	}
+
+	return naming, nil
+}
+
+// validateNaming checks name overrides: identifiers must be exported Go
+// identifiers and the file name a plain name, without directory or extension.
+func validateNaming(accessor, fileName string, typeNames ...string) error {
+	for _, name := range append([]string{accessor}, typeNames...) {
+		if name != "" && (!token.IsIdentifier(name) || !token.IsExported(name)) {
+			return fmt.Errorf("%q is not an exported Go identifier", name)
+		}
+	}
+	if fileName != "" && (strings.ContainsAny(fileName, `/\`) || strings.HasPrefix(fileName, ".")) {
+		return fmt.Errorf("file name %q must not contain a directory", fileName)
+	}
+	return nil
 }
@@ -6,16 +6,18 @@ package main
 import (
 	"bytes"
 	"fmt"
-	"sort"
+	"strconv"
 	"strings"
 	"text/template"
+
+	http_client "github.com/getfrontierhq/buf-public-apis/gen/go/http_client"
 )
 
 // generateService generates Go code for a single service.
 //
 // This function:
-// 1. Determines the import suffix based on package name
-// 2. Processes each method to build path construction code
+// 1. Resolves the Go imports of the method input and output types
+// 2. Processes each method to build its call options
 // 3. Executes the service template
 // 4. Returns the generated Go code
 //
@@ -27,26 +29,23 @@ import (
 //   - Generated Go code as a string
 //   - Error if template execution fails
 func generateService(svc Service, cfg *ClientConfig) (string, error) {
-	// Compute import suffix dynamically based on package difference
-	// "vendors.iniciador" with root "vendors.iniciador" → ""
-	// "vendors.iniciador.investments" with root "vendors.iniciador" → "/investments"
-	importSuffix := computeImportSuffix(svc.Package, cfg.RootPackage)
-
-	// Compute proto package path
-	protoPackage := fmt.Sprintf("%s/%s%s",
-		cfg.GoModulePath,
-		strings.Replace(cfg.RootPackage, ".", "/", -1),
-		importSuffix,
-	)
+	return executeTemplate("service", serviceFileTemplate, buildServiceData(svc, cfg))
+}
+
+// buildServiceData builds the template data of a top-level service,
+// shared by the service and the mock templates.
+func buildServiceData(svc Service, cfg *ClientConfig) ServiceTemplateData {
+	// The service's own package is imported as "pb"; input and output types
+	// from other packages get their own aliases
+	imports := newGoImports(svc.GoPackage)
 
 	data := ServiceTemplateData{
 		ServiceName:   svc.Name,
-		ImportSuffix:  importSuffix,
 		HTTPClientPkg: cfg.HTTPClientPkg,
-		ProtoPackage:  protoPackage,
+		ClientPkg:     cfg.ClientPkg,
 		InterfaceName: generateInterfaceName(svc.Name),
 		ImplName:      generateImplName(svc.Name),
-		PrivateField:  generatePrivateFieldName(svc.Name),
+		PrivateField:  servicePrivateFieldName(svc),
 	}
 
 	// Process methods
@@ -56,24 +55,40 @@ func generateService(svc Service, cfg *ClientConfig) (string, error) {
 			continue
 		}
 
-		methodData := MethodTemplateData{
-			Name:       m.Name,
-			InputType:  m.InputType,
-			OutputType: m.OutputType,
-			HTTP:       m.HTTP,
-		}
+		data.HasTime = data.HasTime || retryHasBackoff(m.HTTP.Retry)
+		data.Methods = append(data.Methods, buildMethodData(svc, m, imports))
+	}
+	data.Imports = imports.list()
 
-		// Build path construction code if method has path parameters
-		if len(m.HTTP.PathParams) > 0 {
-			data.HasFmt = true // Need fmt.Sprintf
-			methodData.PathConstruction = buildPathConstruction(m.HTTP.Path, m.HTTP.PathParams)
-		}
+	return data
+}
+
+// buildMethodData builds the template data of an annotated method, shared by
+// the top-level and the grouped services.
+func buildMethodData(svc Service, m Method, imports *goImports) MethodTemplateData {
+	methodData := MethodTemplateData{
+		Name:       m.Name,
+		InputType:  imports.qualify(m.Input),
+		OutputType: imports.qualify(m.Output),
+		HTTP:       m.HTTP,
+		Operation:  fmt.Sprintf("/%s.%s/%s", svc.Package, svc.Name, m.Name),
+	}
 
-		data.Methods = append(data.Methods, methodData)
+	// Pass the operation name, the per-method retry override and the unwrap pointer as call options
+	methodData.CallOptions = fmt.Sprintf("httpclient.Operation(Operation_%s_%s)", svc.Name, m.Name)
+	if m.HTTP.Retry != nil {
+		methodData.CallOptions += ", " + buildRetryOverride(m.HTTP.Retry)
 	}
+	if m.HTTP.UnwrapResponse != "" {
+		methodData.CallOptions += fmt.Sprintf(", httpclient.UnwrapResponse(%q)", m.HTTP.UnwrapResponse)
+	}
+
+	return methodData
+}
 
-	// Execute template
-	tmpl, err := template.New("service").Parse(serviceFileTemplate)
+// executeTemplate parses and executes a code template.
+func executeTemplate(name, text string, data any) (string, error) {
+	tmpl, err := template.New(name).Parse(text)
 	if err != nil {
 		return "", fmt.Errorf("parse template: %w", err)
 	}
@@ -86,113 +101,120 @@ func generateService(svc Service, cfg *ClientConfig) (string, error) {
 	return buf.String(), nil
 }
 
-// computeImportSuffix calculates the import suffix based on package difference.
+// buildRetryOverride generates a RetryOverride call option from a method's
+// (http_client.retry) annotation.
 //
-// Examples:
-//   - Package: "vendors.iniciador", Root: "vendors.iniciador" → ""
-//   - Package: "vendors.iniciador.investments", Root: "vendors.iniciador" → "/investments"
-//   - Package: "vendors.other.api.v1", Root: "vendors.other" → "/api/v1"
-func computeImportSuffix(pkg, rootPkg string) string {
-	if pkg == rootPkg {
-		return ""
-	}
-
-	// Remove root package prefix
-	if !strings.HasPrefix(pkg, rootPkg+".") {
-		return ""
-	}
-
-	suffix := strings.TrimPrefix(pkg, rootPkg+".")
-	// Convert dots to slashes: "investments.api" → "/investments/api"
-	return "/" + strings.Replace(suffix, ".", "/", -1)
-}
-
-// buildPathConstruction generates Go code to construct a URL path with parameters.
-//
-// This function:
-// 1. Replaces {param} placeholders with %s for fmt.Sprintf
-// 2. Converts snake_case parameter names to PascalCase field names
-// 3. Generates the fmt.Sprintf call with proper field access
+// Only fields set in the annotation are emitted, so the remaining ones
+// inherit the client retry policy at runtime.
 //
 // Examples:
 //
-//   - Path: "/v1/data/links/{id}", Params: ["id"]
-//     Returns: `fmt.Sprintf("/v1/data/links/%s", req.Id)`
+//   - {max_attempts: 5}
+//     Returns: `httpclient.RetryOverride(&httpclient.RetryPolicy{MaxAttempts: 5})`
 //
-//   - Path: "/v1/data/links/{link_id}/investments/{investment_id}", Params: ["link_id", "investment_id"]
-//     Returns: `fmt.Sprintf("/v1/data/links/%s/investments/%s", req.LinkId, req.InvestmentId)`
+//   - {disabled: true}
+//     Returns: `httpclient.RetryOverride(&httpclient.RetryPolicy{MaxAttempts: 1})`
 //
-// Parameters:
-//   - path: URL path template with {param} placeholders
-//   - params: List of parameter names (snake_case)
-//
-// Returns:
-//   - Go code string for fmt.Sprintf call
-func buildPathConstruction(path string, params []string) string {
-	// Replace {param} with %s for fmt.Sprintf template
-	template := path
-	for _, param := range params {
-		template = strings.Replace(template, "{"+param+"}", "%s", 1)
+//   - {initial_backoff_ms: 200, idempotency_key: true}
+//     Returns: `httpclient.RetryOverride(&httpclient.RetryPolicy{InitialBackoff: 200 * time.Millisecond, IdempotencyKey: true})`
+func buildRetryOverride(retry *http_client.RetryPolicy) string {
+	var fields []string
+
+	switch {
+	case retry.GetDisabled():
+		fields = append(fields, "MaxAttempts: 1")
+	case retry.GetMaxAttempts() > 0:
+		fields = append(fields, fmt.Sprintf("MaxAttempts: %d", retry.GetMaxAttempts()))
 	}
-
-	// Build argument list: req.Param1, req.Param2, ...
-	var args []string
-	for _, param := range params {
-		// Convert snake_case to PascalCase: link_id → LinkId
-		fieldName := snakeToPascal(param)
-		args = append(args, "req."+fieldName)
+	if retry.GetInitialBackoffMs() > 0 {
+		fields = append(fields, fmt.Sprintf("InitialBackoff: %d * time.Millisecond", retry.GetInitialBackoffMs()))
 	}
+	if retry.GetMaxBackoffMs() > 0 {
+		fields = append(fields, fmt.Sprintf("MaxBackoff: %d * time.Millisecond", retry.GetMaxBackoffMs()))
+	}
+	if retry.GetBackoffMultiplier() > 0 {
+		fields = append(fields, fmt.Sprintf("BackoffMultiplier: %s", strconv.FormatFloat(retry.GetBackoffMultiplier(), 'g', -1, 64)))
+	}
+	if codes := retry.GetRetryableStatusCodes(); len(codes) > 0 {
+		var values []string
+		for _, code := range codes {
+			values = append(values, strconv.FormatUint(uint64(code), 10))
+		}
+		fields = append(fields, fmt.Sprintf("RetryableStatusCodes: []int{%s}", strings.Join(values, ", ")))
+	}
+	if retry.GetIdempotent() {
+		fields = append(fields, "Idempotent: true")
+	}
+	if retry.GetIdempotencyKey() {
+		fields = append(fields, "IdempotencyKey: true")
+	}
+
+	return fmt.Sprintf("httpclient.RetryOverride(&httpclient.RetryPolicy{%s})", strings.Join(fields, ", "))
+}
 
-	// Generate: fmt.Sprintf("template", args...)
-	return fmt.Sprintf(`fmt.Sprintf("%s", %s)`, template, strings.Join(args, ", "))
+// retryHasBackoff reports whether the retry override uses time.Duration fields,
+// in which case the generated file must import "time".
+func retryHasBackoff(retry *http_client.RetryPolicy) bool {
+	return retry.GetInitialBackoffMs() > 0 || retry.GetMaxBackoffMs() > 0
 }
 
-// generateNestedServices generates Go code for multiple services in a category.
+// generateNestedServices generates Go code for a service group.
 //
 // This function creates a file with:
-// 1. A grouping client (e.g., InvestmentsClient)
-// 2. All service structs in the category (e.g., TreasureTitlesService, FundsService)
+// 1. A grouping client (e.g., InvestmentsClient), with accessors for the
+// group's services and sub-groups
+// 2. All service structs in the group (e.g., TreasureTitlesService, FundsService)
 // 3. All methods for each service
 //
 // Parameters:
-//   - category: Category name (e.g., "investments")
-//   - services: All services in this category
+//   - group: Service group (e.g., ["investments"] or ["investments", "funds"])
 //   - cfg: Client configuration with paths
 //
 // Returns:
 //   - Generated Go code as a string
 //   - Error if template execution fails
-func generateNestedServices(category string, services []Service, cfg *ClientConfig) (string, error) {
-	// Capitalize category for struct names: "investments" -> "Investments"
-	categoryCapitalized := strings.ToUpper(category[:1]) + category[1:]
-
-	importSuffix := "/" + category
+func generateNestedServices(group *ServiceGroup, cfg *ClientConfig) (string, error) {
+	return executeTemplate("nested", nestedServicesFileTemplate, buildNestedServicesData(group, cfg))
+}
 
-	// Compute proto package path for nested services
-	protoPackage := fmt.Sprintf("%s/%s%s",
-		cfg.GoModulePath,
-		strings.Replace(cfg.RootPackage, ".", "/", -1),
-		importSuffix,
-	)
+// buildNestedServicesData builds the template data of a service group,
+// shared by the nested services and the mock templates.
+func buildNestedServicesData(group *ServiceGroup, cfg *ClientConfig) NestedServicesTemplateData {
+	// Struct names from the package path: ["investments", "funds"] -> "InvestmentsFundsClient"
+	typeName := group.TypeName()
+	category := strings.TrimSuffix(typeName, "Client")
+
+	// The services of a group share a file: the first service's package
+	// is imported as "pb"
+	var imports *goImports
+	if len(group.Services) > 0 {
+		imports = newGoImports(group.Services[0].GoPackage)
+	} else {
+		imports = newGoImports("")
+	}
 
 	data := NestedServicesTemplateData{
-		Category:      categoryCapitalized,
-		CategoryLower: category,
-		ImportSuffix:  importSuffix,
+		Category:      category,
+		CategoryLower: strings.Join(group.Path, "."),
 		HTTPClientPkg: cfg.HTTPClientPkg,
-		ProtoPackage:  protoPackage,
-		InterfaceName: generateInterfaceName(categoryCapitalized + "Client"),
-		ImplName:      generateImplName(categoryCapitalized + "Client"),
-		PrivateField:  generatePrivateFieldName(categoryCapitalized + "Client"),
+		ClientPkg:     cfg.ClientPkg,
+		InterfaceName: generateInterfaceName(typeName),
+		ImplName:      generateImplName(typeName),
+		PrivateField:  generatePrivateFieldName(typeName),
+	}
+
+	for _, sub := range group.Groups {
+		data.Groups = append(data.Groups, nestedClientInfo(sub))
 	}
 
-	// Process each service in the category
-	for _, svc := range services {
+	// Process each service in the group
+	for _, svc := range group.Services {
 		serviceData := ServiceTemplateData{
 			ServiceName:   svc.Name,
+			Accessor:      serviceAccessorName(svc, false),
 			InterfaceName: generateInterfaceName(svc.Name),
 			ImplName:      generateImplName(svc.Name),
-			PrivateField:  generatePrivateFieldName(svc.Name),
+			PrivateField:  servicePrivateFieldName(svc),
 		}
 
 		// Process methods
@@ -201,37 +223,15 @@ func generateNestedServices(category string, services []Service, cfg *ClientConf
 				continue
 			}
 
-			methodData := MethodTemplateData{
-				Name:       m.Name,
-				InputType:  m.InputType,
-				OutputType: m.OutputType,
-				HTTP:       m.HTTP,
-			}
-
-			// Build path construction if method has path parameters
-			if len(m.HTTP.PathParams) > 0 {
-				data.HasFmt = true
-				methodData.PathConstruction = buildPathConstruction(m.HTTP.Path, m.HTTP.PathParams)
-			}
-
-			serviceData.Methods = append(serviceData.Methods, methodData)
+			data.HasTime = data.HasTime || retryHasBackoff(m.HTTP.Retry)
+			serviceData.Methods = append(serviceData.Methods, buildMethodData(svc, m, imports))
 		}
 
 		data.Services = append(data.Services, serviceData)
 	}
+	data.Imports = imports.list()
 
-	// Execute template
-	tmpl, err := template.New("nested").Parse(nestedServicesFileTemplate)
-	if err != nil {
-		return "", fmt.Errorf("parse template: %w", err)
-	}
-
-	var buf bytes.Buffer
-	if err := tmpl.Execute(&buf, data); err != nil {
-		return "", fmt.Errorf("execute template: %w", err)
-	}
-
-	return buf.String(), nil
+	return data
 }
 
 // generateRootClient generates the root client file that wires everything together.
@@ -239,88 +239,141 @@ func generateNestedServices(category string, services []Service, cfg *ClientConf
 // This function creates a client.go file with:
 // 1. The root client struct (e.g., IniciadorClient)
 // 2. A constructor function (e.g., NewIniciadorClient)
-// 3. Initialization of all services and nested clients
+// 3. Initialization of all services and first-level groups (which
+// initialize their own sub-groups)
 //
 // The generated client follows an immutable pattern - to change the token,
 // users must create a new client instance.
 //
 // Parameters:
 //   - clientName: Name of the root client (e.g., "IniciadorClient")
-//   - topLevel: Top-level services (e.g., AuthService, LinksService)
-//   - nested: Map of category -> services (e.g., "investments" -> [TreasureTitlesService, ...])
+//   - root: Root of the service tree (see buildServiceTree)
 //
 // Returns:
 //   - Generated Go code as a string
 //   - Error if template execution fails
-func generateRootClient(clientName string, topLevel []Service, nested map[string][]Service, cfg *ClientConfig) (string, error) {
+func generateRootClient(clientName string, root *ServiceGroup, cfg *ClientConfig) (string, error) {
+	return executeTemplate("rootclient", rootClientTemplate, buildRootClientData(clientName, root, cfg))
+}
+
+// buildRootClientData builds the template data of the root client,
+// shared by the root client and the mock templates.
+func buildRootClientData(clientName string, root *ServiceGroup, cfg *ClientConfig) RootClientTemplateData {
 	data := RootClientTemplateData{
 		ClientName:    clientName,
 		HTTPClientPkg: cfg.HTTPClientPkg,
+		ClientPkg:     cfg.ClientPkg,
 		InterfaceName: generateInterfaceName(clientName),
 		ImplName:      generateImplName(clientName),
 	}
 
-	// Process top-level services (already sorted in caller)
-	for _, svc := range topLevel {
-		// AuthService -> Auth
-		fieldName := strings.TrimSuffix(svc.Name, "Service")
+	// Process top-level services (sorted by buildServiceTree)
+	for _, svc := range root.Services {
 		data.TopLevelServices = append(data.TopLevelServices, ServiceInfo{
-			FieldName:     fieldName,
+			FieldName:     serviceAccessorName(svc, true), // AuthService -> Auth
 			TypeName:      svc.Name,
 			ImplName:      generateImplName(svc.Name),
 			InterfaceName: generateInterfaceName(svc.Name),
-			PrivateField:  generatePrivateFieldName(svc.Name),
+			PrivateField:  servicePrivateFieldName(svc),
 		})
 	}
 
-	// Process nested clients - sort category keys for deterministic output
-	var categories []string
-	for category := range nested {
-		categories = append(categories, category)
+	// Process first-level groups (sorted by buildServiceTree)
+	for _, group := range root.Groups {
+		data.NestedClients = append(data.NestedClients, nestedClientInfo(group))
 	}
-	sort.Strings(categories)
 
-	for _, category := range categories {
-		services := nested[category]
-		// Sort services within category for deterministic output
-		sort.Slice(services, func(i, j int) bool {
-			return services[i].Name < services[j].Name
-		})
-		// "investments" -> "Investments"
-		categoryCapitalized := strings.ToUpper(category[:1]) + category[1:]
-
-		nestedClient := NestedClientInfo{
-			FieldName:     categoryCapitalized,
-			TypeName:      categoryCapitalized + "Client",
-			ImplName:      generateImplName(categoryCapitalized + "Client"),
-			InterfaceName: generateInterfaceName(categoryCapitalized + "Client"),
-			PrivateField:  generatePrivateFieldName(categoryCapitalized + "Client"),
+	return data
+}
+
+// nestedClientInfo describes a group to its parent.
+//
+// Example: ["investments", "funds"] → GetFunds() InvestmentsFundsClient
+func nestedClientInfo(group *ServiceGroup) NestedClientInfo {
+	typeName := group.TypeName()
+	return NestedClientInfo{
+		FieldName:     group.AccessorName(),
+		TypeName:      typeName,
+		ImplName:      generateImplName(typeName),
+		InterfaceName: generateInterfaceName(typeName),
+		PrivateField:  generatePrivateFieldName(typeName),
+	}
+}
+
+// generateMocks generates the clientmock package files, keyed by file name
+// relative to the clientmock directory.
+//
+// The files mirror the client package: one file per top-level service, one
+// per service group and client.gen.go for the root client fake.
+func generateMocks(clientName string, root *ServiceGroup, cfg *ClientConfig) (map[string]string, error) {
+	if err := validateMockNames(root, clientName); err != nil {
+		return nil, err
+	}
+
+	files := make(map[string]string)
+
+	for _, svc := range root.Services {
+		code, err := executeTemplate("servicemock", serviceMockTemplate, buildServiceData(svc, cfg))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", svc.Name, err)
 		}
+		files[serviceFileName(svc)] = code
+	}
 
-		// Add all services in this category
-		for _, svc := range services {
-			nestedClient.Services = append(nestedClient.Services, ServiceInfo{
-				FieldName:     svc.Name,
-				TypeName:      svc.Name,
-				ImplName:      generateImplName(svc.Name),
-				InterfaceName: generateInterfaceName(svc.Name),
-				PrivateField:  generatePrivateFieldName(svc.Name),
-			})
+	for _, group := range root.Descendants() {
+		code, err := executeTemplate("nestedmock", nestedServicesMockTemplate, buildNestedServicesData(group, cfg))
+		if err != nil {
+			return nil, fmt.Errorf("%s services: %w", strings.Join(group.Path, "."), err)
 		}
+		files[group.FileName()] = code
+	}
 
-		data.NestedClients = append(data.NestedClients, nestedClient)
+	code, err := executeTemplate("rootmock", rootClientMockTemplate, buildRootClientData(clientName, root, cfg))
+	if err != nil {
+		return nil, fmt.Errorf("root client: %w", err)
 	}
+	files["client.gen.go"] = code
+
+	return files, nil
+}
 
-	// Execute template
-	tmpl, err := template.New("rootclient").Parse(rootClientTemplate)
+// generateFakeServer generates the clienttest package files, keyed by file
+// name relative to the clienttest directory.
+//
+// server.gen.go holds the Server embedding the fake server runtime; the other
+// files mirror the client package with the typed handler registration
+// methods. Groups without services have no handlers, hence no file.
+func generateFakeServer(clientName string, root *ServiceGroup, cfg *ClientConfig) (map[string]string, error) {
+	code, err := executeTemplate("fakeserver", fakeServerTemplate, clientTestRuntimePackage)
 	if err != nil {
-		return "", fmt.Errorf("parse template: %w", err)
+		return nil, fmt.Errorf("fake server: %w", err)
 	}
+	files := map[string]string{"server.gen.go": code}
 
-	var buf bytes.Buffer
-	if err := tmpl.Execute(&buf, data); err != nil {
-		return "", fmt.Errorf("execute template: %w", err)
+	for _, svc := range root.Services {
+		code, err := executeTemplate("servicefakeserver", serviceFakeServerTemplate, buildServiceData(svc, cfg))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", svc.Name, err)
+		}
+		files[serviceFileName(svc)] = code
 	}
 
-	return buf.String(), nil
+	for _, group := range root.Descendants() {
+		if len(group.Services) == 0 {
+			continue
+		}
+		code, err := executeTemplate("nestedfakeserver", nestedServicesFakeServerTemplate, buildNestedServicesData(group, cfg))
+		if err != nil {
+			return nil, fmt.Errorf("%s services: %w", strings.Join(group.Path, "."), err)
+		}
+		files[group.FileName()] = code
+	}
+
+	code, err = executeTemplate("rootfakeserver", rootClientFakeServerTemplate, buildRootClientData(clientName, root, cfg))
+	if err != nil {
+		return nil, fmt.Errorf("root client: %w", err)
+	}
+	files["client.gen.go"] = code
+
+	return files, nil
 }
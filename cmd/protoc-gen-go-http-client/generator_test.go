@@ -0,0 +1,407 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+
+	http_client "github.com/getfrontierhq/buf-public-apis/gen/go/http_client"
+)
+
+func TestBuildRetryOverride(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    *http_client.RetryPolicy
+		expected string
+	}{
+		{
+			name:     "max attempts",
+			input:    &http_client.RetryPolicy{MaxAttempts: 5},
+			expected: `httpclient.RetryOverride(&httpclient.RetryPolicy{MaxAttempts: 5})`,
+		},
+		{
+			name:     "disabled wins over max attempts",
+			input:    &http_client.RetryPolicy{MaxAttempts: 5, Disabled: true},
+			expected: `httpclient.RetryOverride(&httpclient.RetryPolicy{MaxAttempts: 1})`,
+		},
+		{
+			name: "backoff",
+			input: &http_client.RetryPolicy{
+				InitialBackoffMs:  200,
+				MaxBackoffMs:      3000,
+				BackoffMultiplier: 1.5,
+			},
+			expected: `httpclient.RetryOverride(&httpclient.RetryPolicy{InitialBackoff: 200 * time.Millisecond, MaxBackoff: 3000 * time.Millisecond, BackoffMultiplier: 1.5})`,
+		},
+		{
+			name: "status codes and idempotency",
+			input: &http_client.RetryPolicy{
+				RetryableStatusCodes: []uint32{409, 503},
+				Idempotent:           true,
+				IdempotencyKey:       true,
+			},
+			expected: `httpclient.RetryOverride(&httpclient.RetryPolicy{RetryableStatusCodes: []int{409, 503}, Idempotent: true, IdempotencyKey: true})`,
+		},
+		{
+			name:     "empty",
+			input:    &http_client.RetryPolicy{},
+			expected: `httpclient.RetryOverride(&httpclient.RetryPolicy{})`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := buildRetryOverride(tt.input)
+			if result != tt.expected {
+				t.Errorf("buildRetryOverride(%v) = %q, want %q",
+					tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
+// TestGenerateTestHelpers builds the client, clientmock and clienttest
+// packages of a service tree spanning top-level services and nested groups.
+func TestGenerateTestHelpers(t *testing.T) {
+	cfg := &ClientConfig{
+		RootPackage:   "vendors.iniciador",
+		OutputSubdir:  "client",
+		GoModulePath:  testModule,
+		HTTPClientPkg: runtimePackage,
+		ClientPkg:     testModule + "/client",
+	}
+	getLink := Method{
+		Name:       "GetLink",
+		InputType:  "StringValue",
+		OutputType: "Struct",
+		Input:      GoType{ImportPath: "google.golang.org/protobuf/types/known/wrapperspb", PackageName: "wrapperspb", Name: "StringValue"},
+		Output:     GoType{ImportPath: "google.golang.org/protobuf/types/known/structpb", PackageName: "structpb", Name: "Struct"},
+		HTTP:       &HTTPInfo{Method: "GET", Path: "/v1/links/{value}", PathParams: []string{"value"}},
+	}
+	copyLink := Method{
+		Name:       "CopyLink",
+		InputType:  "StringValue",
+		OutputType: "Struct",
+		Input:      getLink.Input,
+		Output:     getLink.Output,
+		HTTP: &HTTPInfo{
+			Method:         "POST",
+			Path:           "/v1/links/{value}/copies",
+			PathParams:     []string{"value"},
+			UnwrapResponse: "/data",
+			Retry:          &http_client.RetryPolicy{InitialBackoffMs: 100, IdempotencyKey: true},
+		},
+	}
+	ping := Method{
+		Name:       "Ping",
+		InputType:  "Empty",
+		OutputType: "Empty",
+		Input:      GoType{ImportPath: "google.golang.org/protobuf/types/known/emptypb", PackageName: "emptypb", Name: "Empty"},
+		Output:     GoType{ImportPath: "google.golang.org/protobuf/types/known/emptypb", PackageName: "emptypb", Name: "Empty"},
+		HTTP:       &HTTPInfo{Method: "GET", Path: "/v1/ping"},
+	}
+	root := buildServiceTree([]Service{
+		{Name: "LinksService", Package: "vendors.iniciador", GoPackage: "google.golang.org/protobuf/types/known/wrapperspb", Methods: []Method{getLink, copyLink}},
+		{Name: "FundsService", Package: "vendors.iniciador.investments", GoPackage: "google.golang.org/protobuf/types/known/structpb", Methods: []Method{getLink, ping}},
+		{Name: "QuotesService", Package: "vendors.iniciador.investments.funds", GoPackage: "google.golang.org/protobuf/types/known/emptypb", Methods: []Method{ping}},
+		{Name: "PixService", Package: "vendors.iniciador.banking.pix", GoPackage: "google.golang.org/protobuf/types/known/emptypb", Methods: []Method{ping, copyLink}},
+	}, "vendors.iniciador", nil)
+	if err := validateServiceTree(root, "IniciadorClient"); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		dir      string
+		generate func(string, *ServiceGroup, *ClientConfig) (map[string]string, error)
+		files    []string
+	}{
+		{
+			dir:      "client",
+			generate: generateClientFiles,
+			files:    []string{"links.gen.go", "investments.gen.go", "investments_funds.gen.go", "banking.gen.go", "banking_pix.gen.go", "client.gen.go"},
+		},
+		{
+			dir:      "clientmock",
+			generate: generateMocks,
+			files:    []string{"links.gen.go", "investments.gen.go", "investments_funds.gen.go", "banking.gen.go", "banking_pix.gen.go", "client.gen.go"},
+		},
+		{
+			dir:      "clienttest",
+			generate: generateFakeServer,
+			files:    []string{"server.gen.go", "links.gen.go", "investments.gen.go", "investments_funds.gen.go", "banking_pix.gen.go", "client.gen.go"},
+		},
+	}
+
+	generated := make(map[string]string)
+	for _, tt := range tests {
+		files, err := tt.generate("IniciadorClient", root, cfg)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.dir, err)
+		}
+		for _, name := range tt.files {
+			code, ok := files[name]
+			if !ok {
+				t.Errorf("%s: missing %s", tt.dir, name)
+				continue
+			}
+			generated[filepath.Join(tt.dir, name)] = code
+		}
+	}
+
+	goVet(t, generated)
+}
+
+// testModule is the module path of the code built by goVet.
+const testModule = "example.com/httpclienttest"
+
+// goVet runs go vet on the generated files, keyed by path relative to a
+// module depending on this repository, which type-checks them.
+func goVet(t *testing.T, files map[string]string) {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("compiles the generated code")
+	}
+	goCmd := filepath.Join(runtime.GOROOT(), "bin", "go")
+	if _, err := os.Stat(goCmd); err != nil {
+		t.Skip("go command not found")
+	}
+
+	root, err := filepath.Abs(filepath.Join("..", ".."))
+	if err != nil {
+		t.Fatal(err)
+	}
+	goMod, err := os.ReadFile(filepath.Join(root, "go.mod"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	goSum, err := os.ReadFile(filepath.Join(root, "go.sum"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The module has the requirements of this repository, so go.sum is complete
+	dir := t.TempDir()
+	mod := strings.Replace(string(goMod), "module github.com/getfrontierhq/buf-public-apis", "module "+testModule, 1)
+	mod += fmt.Sprintf(`
+require github.com/getfrontierhq/buf-public-apis v0.0.0
+
+replace github.com/getfrontierhq/buf-public-apis => %s
+`, root)
+	files["go.mod"] = mod
+	files["go.sum"] = string(goSum)
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cmd := exec.Command(goCmd, "vet", "./...")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod", "GOWORK=off")
+	var out bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &out, &out
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("go vet: %v\n%s", err, out.String())
+	}
+}
+
+func TestGoImports(t *testing.T) {
+	imports := newGoImports("example.com/pkg/go/vendors/iniciador")
+
+	tests := []struct {
+		input    GoType
+		expected string
+	}{
+		{GoType{ImportPath: "example.com/pkg/go/vendors/iniciador", PackageName: "iniciador", Name: "Link"}, "pb.Link"},
+		{GoType{ImportPath: "google.golang.org/protobuf/types/known/emptypb", PackageName: "emptypb", Name: "Empty"}, "emptypb.Empty"},
+		{GoType{ImportPath: "example.com/pkg/go/common", PackageName: "common", Name: "Money"}, "common.Money"},
+		{GoType{ImportPath: "example.com/pkg/go/other/common", PackageName: "common", Name: "Page_Token"}, "common2.Page_Token"},
+		{GoType{ImportPath: "example.com/pkg/go/http", PackageName: "http", Name: "Header"}, "http2.Header"},
+		{GoType{ImportPath: "example.com/pkg/go/common", PackageName: "common", Name: "Currency"}, "common.Currency"},
+	}
+
+	for _, tt := range tests {
+		if result := imports.qualify(tt.input); result != tt.expected {
+			t.Errorf("qualify(%v) = %q, want %q", tt.input, result, tt.expected)
+		}
+	}
+
+	expected := []GoImport{
+		{Alias: "common", Path: "example.com/pkg/go/common"},
+		{Alias: "http2", Path: "example.com/pkg/go/http"},
+		{Alias: "common2", Path: "example.com/pkg/go/other/common"},
+		{Alias: "pb", Path: "example.com/pkg/go/vendors/iniciador"},
+		{Alias: "emptypb", Path: "google.golang.org/protobuf/types/known/emptypb"},
+	}
+	if result := imports.list(); !reflect.DeepEqual(result, expected) {
+		t.Errorf("list() = %v, want %v", result, expected)
+	}
+}
+
+// generateClientFiles generates the client package files like the plugin does.
+func generateClientFiles(clientName string, root *ServiceGroup, cfg *ClientConfig) (map[string]string, error) {
+	files := make(map[string]string)
+	for _, svc := range root.Services {
+		code, err := generateService(svc, cfg)
+		if err != nil {
+			return nil, err
+		}
+		files[serviceFileName(svc)] = code
+	}
+	for _, group := range root.Descendants() {
+		code, err := generateNestedServices(group, cfg)
+		if err != nil {
+			return nil, err
+		}
+		files[group.FileName()] = code
+	}
+	code, err := generateRootClient(clientName, root, cfg)
+	if err != nil {
+		return nil, err
+	}
+	files["client.gen.go"] = code
+	return files, nil
+}
+
+func TestValidateServiceTree(t *testing.T) {
+	tests := []struct {
+		name     string
+		services []Service
+		expected string // error substring, "" if valid
+	}{
+		{
+			name: "nested groups",
+			services: []Service{
+				{Name: "AuthService", Package: "vendors.iniciador"},
+				{Name: "FundsService", Package: "vendors.iniciador.investments"},
+				{Name: "QuotesService", Package: "vendors.iniciador.investments.funds"},
+			},
+		},
+		{
+			name: "service and group accessors",
+			services: []Service{
+				{Name: "InvestmentsService", Package: "vendors.iniciador"},
+				{Name: "FundsService", Package: "vendors.iniciador.investments"},
+			},
+			expected: "GetInvestments",
+		},
+		{
+			name: "accessor override",
+			services: []Service{
+				{Name: "InvestmentsService", Package: "vendors.iniciador", Accessor: "InvestmentsAPI", FileName: "investments_api"},
+				{Name: "FundsService", Package: "vendors.iniciador.investments"},
+			},
+		},
+		{
+			name: "same service name in two packages",
+			services: []Service{
+				{Name: "FundsService", Package: "vendors.iniciador.investments"},
+				{Name: "FundsService", Package: "vendors.iniciador.pension"},
+			},
+			expected: "type FundsService",
+		},
+		{
+			name: "service and root client files",
+			services: []Service{
+				{Name: "ClientService", Package: "vendors.iniciador"},
+			},
+			expected: "file client.gen.go",
+		},
+		{
+			name: "group type names",
+			services: []Service{
+				{Name: "QuotesService", Package: "vendors.iniciador.open_finance"},
+				{Name: "RatesService", Package: "vendors.iniciador.open.finance"},
+			},
+			expected: "type OpenFinanceClient",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateServiceTree(buildServiceTree(tt.services, "vendors.iniciador", nil), "IniciadorClient")
+			switch {
+			case tt.expected == "" && err != nil:
+				t.Fatalf("validateServiceTree() = %v, want nil", err)
+			case tt.expected != "" && (err == nil || !strings.Contains(err.Error(), tt.expected)):
+				t.Fatalf("validateServiceTree() = %v, want error containing %q", err, tt.expected)
+			}
+		})
+	}
+}
+
+func TestValidateMockNames(t *testing.T) {
+	method := func(name string) Method {
+		return Method{Name: name, HTTP: &HTTPInfo{Method: "GET", Path: "/v1/" + name}}
+	}
+
+	tests := []struct {
+		name     string
+		services []Service
+		expected string // error substring, "" if valid
+	}{
+		{
+			name: "valid",
+			services: []Service{
+				{Name: "LinksService", Package: "vendors.iniciador", Methods: []Method{method("GetLink"), method("ListLinks")}},
+			},
+		},
+		{
+			name: "Reset method",
+			services: []Service{
+				{Name: "LinksService", Package: "vendors.iniciador", Methods: []Method{method("Reset")}},
+			},
+			expected: "method Reset and the Reset helper",
+		},
+		{
+			name: "stub field",
+			services: []Service{
+				{Name: "LinksService", Package: "vendors.iniciador", Methods: []Method{method("Get"), method("GetFunc")}},
+			},
+			expected: "method GetFunc and the GetFunc helper of method Get",
+		},
+		{
+			name: "calls method",
+			services: []Service{
+				{Name: "LinksService", Package: "vendors.iniciador", Methods: []Method{method("GetCalls"), method("Get")}},
+			},
+			expected: "method GetCalls and the GetCalls helper of method Get",
+		},
+		{
+			name: "unannotated method",
+			services: []Service{
+				{Name: "LinksService", Package: "vendors.iniciador", Methods: []Method{method("Get"), {Name: "GetFunc"}}},
+			},
+		},
+		{
+			name: "call type",
+			services: []Service{
+				{Name: "LinksService", Package: "vendors.iniciador", Methods: []Method{method("Get")}},
+				{Name: "LinksServiceGetCall", Package: "vendors.iniciador"},
+			},
+			expected: "type LinksServiceGetCall",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateMockNames(buildServiceTree(tt.services, "vendors.iniciador", nil), "IniciadorClient")
+			switch {
+			case tt.expected == "" && err != nil:
+				t.Fatalf("validateMockNames() = %v, want nil", err)
+			case tt.expected != "" && (err == nil || !strings.Contains(err.Error(), tt.expected)):
+				t.Fatalf("validateMockNames() = %v, want error containing %q", err, tt.expected)
+			}
+		})
+	}
+}
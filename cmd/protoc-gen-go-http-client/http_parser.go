@@ -8,6 +8,7 @@ import (
 	pgs "github.com/lyft/protoc-gen-star/v2"
 	"google.golang.org/genproto/googleapis/api/annotations"
 	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
 
 	http_client "github.com/getfrontierhq/buf-public-apis/gen/go/http_client"
 )
@@ -59,6 +60,39 @@ func extractHTTPInfo(method pgs.Method) (*HTTPInfo, error) {
 	// Extract path parameters from the path template
 	info.PathParams = extractPathParams(info.Path)
 
+	extractClientOptions(opts, info)
+
+	return info, nil
+}
+
+// hasHTTPAnnotation reports whether a method has a google.api.http annotation.
+func hasHTTPAnnotation(method pgs.Method) bool {
+	opts := method.Descriptor().GetOptions()
+	return opts != nil && proto.HasExtension(opts, annotations.E_Http)
+}
+
+// defaultHTTPInfo returns the route of a method without google.api.http
+// annotation, following the protoc-gen-go-http convention for omitempty=false:
+// POST <prefix>/<full.Service>/<Method>, with the request as body.
+// Example: "/rpc/vendors.iniciador.LinksService/Ping" for prefix "/rpc".
+func defaultHTTPInfo(method pgs.Method, prefix string) *HTTPInfo {
+	service := strings.TrimPrefix(method.Service().FullyQualifiedName(), ".")
+	info := &HTTPInfo{
+		Method: "POST",
+		Path:   fmt.Sprintf("%s/%s/%s", prefix, service, method.Name()),
+	}
+
+	extractClientOptions(method.Descriptor().GetOptions(), info)
+
+	return info
+}
+
+// extractClientOptions reads the http_client method options into info.
+func extractClientOptions(opts *descriptorpb.MethodOptions, info *HTTPInfo) {
+	if opts == nil {
+		return
+	}
+
 	// Extract wrap_response_into option if present
 	if proto.HasExtension(opts, http_client.E_WrapResponseInto) {
 		ext := proto.GetExtension(opts, http_client.E_WrapResponseInto)
@@ -67,7 +101,36 @@ func extractHTTPInfo(method pgs.Method) (*HTTPInfo, error) {
 		}
 	}
 
-	return info, nil
+	// Extract retry option if present
+	if proto.HasExtension(opts, http_client.E_Retry) {
+		ext := proto.GetExtension(opts, http_client.E_Retry)
+		if retry, ok := ext.(*http_client.RetryPolicy); ok && retry != nil {
+			info.Retry = retry
+		}
+	}
+}
+
+// unwrapResponse resolves the unwrap_response JSON pointer of a method: the
+// method option when set, even to "", otherwise the default_unwrap_response
+// option of its file. Pointers must be empty or start with "/" (RFC 6901).
+func unwrapResponse(method pgs.Method) (string, error) {
+	var pointer string
+	ok, err := method.Extension(http_client.E_UnwrapResponse, &pointer)
+	if err != nil {
+		return "", fmt.Errorf("%s: invalid http_client.unwrap_response option: %w", method.FullyQualifiedName(), err)
+	}
+	option := "unwrap_response"
+	if !ok {
+		if _, err := method.File().Extension(http_client.E_DefaultUnwrapResponse, &pointer); err != nil {
+			return "", fmt.Errorf("%s: invalid http_client.default_unwrap_response option: %w", method.File().InputPath(), err)
+		}
+		option = "default_unwrap_response"
+	}
+
+	if pointer != "" && !strings.HasPrefix(pointer, "/") {
+		return "", fmt.Errorf("%s: invalid http_client.%s option: JSON pointer %q must start with /", method.FullyQualifiedName(), option, pointer)
+	}
+	return pointer, nil
 }
 
 // extractPathParams finds all path parameters in a URL template.
@@ -88,18 +151,3 @@ func extractPathParams(path string) []string {
 
 	return params
 }
-
-// snakeToPascal converts snake_case to PascalCase.
-// Examples:
-//
-//	"link_id" -> "LinkId"
-//	"investment_id" -> "InvestmentId"
-func snakeToPascal(s string) string {
-	parts := strings.Split(s, "_")
-	for i, part := range parts {
-		if len(part) > 0 {
-			parts[i] = strings.ToUpper(part[:1]) + part[1:]
-		}
-	}
-	return strings.Join(parts, "")
-}
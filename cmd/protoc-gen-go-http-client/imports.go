@@ -0,0 +1,86 @@
+// Package main contains the import management of generated files.
+//
+// RPC input and output messages may live in any Go package (e.g.,
+// google.protobuf.Empty in emptypb), so each generated file collects the
+// packages it references and assigns them non-conflicting aliases.
+package main
+
+import (
+	"fmt"
+	"sort"
+)
+
+// reservedImportNames are the identifiers used by the templates, which proto
+// packages must not shadow.
+var reservedImportNames = []string{
+	"client", "context", "errors", "fmt", "http", "httpclient", "proto", "sync", "testing", "time",
+	"ctx", "req", "resp", "opts", "err", "s", "f", "c", "h", "stub",
+}
+
+// GoImport is an aliased import of a generated file.
+type GoImport struct {
+	Alias string // e.g., "pb", "emptypb"
+	Path  string // e.g., "google.golang.org/protobuf/types/known/emptypb"
+}
+
+// goImports assigns the aliases of the proto Go packages imported by a file.
+//
+// The primary package (the package of the file's services) is imported as
+// "pb"; other packages use their Go package name, suffixed with a number on
+// conflict (e.g., "common", "common2").
+type goImports struct {
+	primary string
+	aliases map[string]string // by import path
+	taken   map[string]bool
+}
+
+// newGoImports returns the imports of a file whose services live in the
+// primary Go package.
+func newGoImports(primary string) *goImports {
+	g := &goImports{
+		primary: primary,
+		aliases: make(map[string]string),
+		taken:   map[string]bool{"pb": true},
+	}
+	for _, name := range reservedImportNames {
+		g.taken[name] = true
+	}
+	return g
+}
+
+// qualify returns the qualified Go expression of t (e.g., "pb.Link"),
+// importing its package if needed.
+func (g *goImports) qualify(t GoType) string {
+	alias, ok := g.aliases[t.ImportPath]
+	if !ok {
+		alias = g.alias(t)
+		g.aliases[t.ImportPath] = alias
+	}
+	return alias + "." + t.Name
+}
+
+// alias picks the alias of a newly imported package.
+func (g *goImports) alias(t GoType) string {
+	if t.ImportPath == g.primary {
+		return "pb"
+	}
+
+	alias := t.PackageName
+	for i := 2; g.taken[alias]; i++ {
+		alias = fmt.Sprintf("%s%d", t.PackageName, i)
+	}
+	g.taken[alias] = true
+	return alias
+}
+
+// list returns the imported packages, sorted by path.
+func (g *goImports) list() []GoImport {
+	imports := make([]GoImport, 0, len(g.aliases))
+	for path, alias := range g.aliases {
+		imports = append(imports, GoImport{Alias: alias, Path: path})
+	}
+	sort.Slice(imports, func(i, j int) bool {
+		return imports[i].Path < imports[j].Path
+	})
+	return imports
+}
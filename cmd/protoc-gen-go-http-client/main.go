@@ -3,16 +3,26 @@
 package main
 
 import (
+	"fmt"
 	"path/filepath"
 	"sort"
 	"strings"
 
 	pgs "github.com/lyft/protoc-gen-star/v2"
+	pgsgo "github.com/lyft/protoc-gen-star/v2/lang/go"
 )
 
 // HTTPClientModule is the main plugin module.
 type HTTPClientModule struct {
 	*pgs.ModuleBase
+	pgsgo.Context
+}
+
+// InitContext initializes the module and the Go context used to resolve
+// the Go import paths and names of messages.
+func (m *HTTPClientModule) InitContext(c pgs.BuildContext) {
+	m.ModuleBase.InitContext(c)
+	m.Context = pgsgo.InitContext(c.Parameters())
 }
 
 // Name returns the name of this module.
@@ -21,18 +31,9 @@ func (m *HTTPClientModule) Name() string {
 }
 
 // Execute is called by protoc-gen-star with all proto files.
-// With strategy=all, this is called once with ALL files.
+// With strategy=all, this is called once with ALL files, and generates every
+// client root along with their shared http base package.
 func (m *HTTPClientModule) Execute(targets map[string]pgs.File, pkgs map[string]pgs.Package) []pgs.Artifact {
-	// Parse configuration
-	cfg, err := parseClientConfig(m.Parameters())
-	if err != nil {
-		m.Fail(err.Error())
-		return m.Artifacts()
-	}
-
-	m.Logf("Generating client for package: %s", cfg.RootPackage)
-	m.Logf("Output subdirectory: %s", cfg.OutputSubdir)
-
 	// Convert map to slice and sort by name for deterministic output
 	var files []pgs.File
 	for _, f := range targets {
@@ -42,8 +43,57 @@ func (m *HTTPClientModule) Execute(targets map[string]pgs.File, pkgs map[string]
 		return files[i].Name().String() < files[j].Name().String()
 	})
 
-	// Extract services
-	services := extractServices(files, cfg.RootPackage)
+	// Parse configuration of every client root
+	cfgs, err := parseClientConfigs(m.Parameters(), files)
+	if err != nil {
+		m.Fail(err.Error())
+		return m.Artifacts()
+	}
+
+	// Extract the services of each client and resolve the Go import paths
+	// of the generated packages
+	services := make([][]Service, len(cfgs))
+	for i, cfg := range cfgs {
+		all, err := extractServices(m.Context, files, cfg.RootPackage, cfg.OmitEmpty, cfg.OmitEmptyPrefix)
+		if err != nil {
+			m.Fail(err.Error())
+			return m.Artifacts()
+		}
+
+		// Services under a more specific root belong to that client only
+		for _, svc := range all {
+			if cfg.ownsService(svc, cfgs) {
+				services[i] = append(services[i], svc)
+			}
+		}
+
+		if err := cfg.resolveImportPaths(m.Parameters(), services[i]); err != nil {
+			m.Fail(err.Error())
+			return m.Artifacts()
+		}
+	}
+
+	// Collect the group name overrides of all the packages
+	naming, err := extractGroupNaming(files)
+	if err != nil {
+		m.Fail(err.Error())
+		return m.Artifacts()
+	}
+
+	for i, cfg := range cfgs {
+		if err := m.generateClient(cfg, services[i], naming); err != nil {
+			m.Fail(err.Error())
+			return m.Artifacts()
+		}
+	}
+
+	return m.Artifacts()
+}
+
+// generateClient generates the client of a root from its services.
+func (m *HTTPClientModule) generateClient(cfg *ClientConfig, services []Service, naming map[string]GroupNaming) error {
+	m.Logf("Generating client for package: %s", cfg.RootPackage)
+	m.Logf("Output subdirectory: %s", cfg.OutputSubdir)
 
 	m.Logf("Found %d services:", len(services))
 	for _, svc := range services {
@@ -55,21 +105,14 @@ func (m *HTTPClientModule) Execute(targets map[string]pgs.File, pkgs map[string]
 		}
 	}
 
-	// Generate base HTTP client (static file)
-	httpClientPath := filepath.Join(cfg.OutputSubdir, "http", "client.gen.go")
-	m.AddGeneratorFile(httpClientPath, httpClientBaseCode)
-	m.Logf("Generated %s", httpClientPath)
-
-	// Phase 4: Group services by package level
-	topLevel, nested := groupServices(services, cfg.RootPackage)
-
-	// Sort top-level services by name for deterministic output
-	sort.Slice(topLevel, func(i, j int) bool {
-		return topLevel[i].Name < topLevel[j].Name
-	})
+	// Phase 4: Build the service tree mirroring the package hierarchy
+	root := buildServiceTree(services, cfg.RootPackage, naming)
+	if err := validateServiceTree(root, cfg.ClientName); err != nil {
+		return fmt.Errorf("client %s: %w", cfg.RootPackage, err)
+	}
 
 	// Generate top-level services (Auth, Links)
-	for _, svc := range topLevel {
+	for _, svc := range root.Services {
 		code, err := generateService(svc, cfg)
 		if err != nil {
 			m.Logf("Error generating %s: %v", svc.Name, err)
@@ -77,40 +120,27 @@ func (m *HTTPClientModule) Execute(targets map[string]pgs.File, pkgs map[string]
 		}
 
 		// Service name to filename: AuthService -> auth.gen.go
-		serviceName := strings.ToLower(strings.TrimSuffix(svc.Name, "Service"))
-		filename := filepath.Join(cfg.OutputSubdir, serviceName+".gen.go")
+		filename := filepath.Join(cfg.OutputSubdir, serviceFileName(svc))
 		m.AddGeneratorFile(filename, code)
 		m.Logf("Generated %s (%d bytes)", filename, len(code))
 	}
 
-	// Generate nested services (e.g., investments.go with InvestmentsClient)
-	// Sort category names for deterministic output
-	var categories []string
-	for category := range nested {
-		categories = append(categories, category)
-	}
-	sort.Strings(categories)
-
-	for _, category := range categories {
-		categoryServices := nested[category]
-		// Sort services within category for deterministic output
-		sort.Slice(categoryServices, func(i, j int) bool {
-			return categoryServices[i].Name < categoryServices[j].Name
-		})
-
-		code, err := generateNestedServices(category, categoryServices, cfg)
+	// Generate service groups at any depth (e.g., investments.gen.go with
+	// InvestmentsClient, investments_funds.gen.go with InvestmentsFundsClient)
+	for _, group := range root.Descendants() {
+		code, err := generateNestedServices(group, cfg)
 		if err != nil {
-			m.Logf("Error generating %s services: %v", category, err)
+			m.Logf("Error generating %s services: %v", strings.Join(group.Path, "."), err)
 			continue
 		}
 
-		filename := filepath.Join(cfg.OutputSubdir, strings.ToLower(category)+".gen.go")
+		filename := filepath.Join(cfg.OutputSubdir, group.FileName())
 		m.AddGeneratorFile(filename, code)
 		m.Logf("Generated %s (%d bytes)", filename, len(code))
 	}
 
 	// Phase 5: Generate root client (client.gen.go)
-	clientCode, err := generateRootClient(cfg.ClientName, topLevel, nested, cfg)
+	clientCode, err := generateRootClient(cfg.ClientName, root, cfg)
 	if err != nil {
 		m.Logf("Error generating root client: %v", err)
 	} else {
@@ -119,42 +149,45 @@ func (m *HTTPClientModule) Execute(targets map[string]pgs.File, pkgs map[string]
 		m.Logf("Generated %s (%d bytes)", clientFilename, len(clientCode))
 	}
 
-	return m.Artifacts()
-}
-
-// groupServices separates services into top-level and nested categories.
-//
-// Top-level services are those directly in the root package (e.g., vendors.iniciador).
-// Nested services are grouped by their sub-package (e.g., vendors.iniciador.investments).
-//
-// Returns:
-//   - topLevel: Services in the root package
-//   - nested: Map of category -> services (e.g., "investments" -> [TreasureTitlesService, ...])
-func groupServices(services []Service, rootPkg string) (topLevel []Service, nested map[string][]Service) {
-	nested = make(map[string][]Service)
+	// Phase 6: Generate fakes of the client interfaces (clientmock/*.gen.go, opt-in)
+	if cfg.Mock {
+		mocks, err := generateMocks(cfg.ClientName, root, cfg)
+		if err != nil {
+			return fmt.Errorf("generate mocks: %w", err)
+		}
+		m.addGeneratorFiles(filepath.Join(cfg.OutputSubdir, "clientmock"), mocks)
+	}
 
-	for _, svc := range services {
-		if svc.Package == rootPkg {
-			// Top-level service (same as root package)
-			topLevel = append(topLevel, svc)
-		} else {
-			// Nested service - extract category from package name
-			// "vendors.iniciador.investments" -> "investments"
-			parts := strings.Split(svc.Package, ".")
-			if len(parts) > len(strings.Split(rootPkg, ".")) {
-				// Get the next level after root package
-				categoryIndex := len(strings.Split(rootPkg, "."))
-				category := parts[categoryIndex]
-				nested[category] = append(nested[category], svc)
-			}
+	// Phase 7: Generate the httptest fake server (clienttest/*.gen.go, opt-in)
+	if cfg.FakeServer {
+		fakeServer, err := generateFakeServer(cfg.ClientName, root, cfg)
+		if err != nil {
+			return fmt.Errorf("generate fake server: %w", err)
 		}
+		m.addGeneratorFiles(filepath.Join(cfg.OutputSubdir, "clienttest"), fakeServer)
 	}
 
-	return
+	return nil
+}
+
+// addGeneratorFiles adds the files to dir, sorted by name for deterministic output.
+func (m *HTTPClientModule) addGeneratorFiles(dir string, files map[string]string) {
+	var names []string
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		filename := filepath.Join(dir, name)
+		m.AddGeneratorFile(filename, files[name])
+		m.Logf("Generated %s (%d bytes)", filename, len(files[name]))
+	}
 }
 
 func main() {
 	pgs.Init(pgs.DebugEnv("DEBUG")).
 		RegisterModule(&HTTPClientModule{ModuleBase: &pgs.ModuleBase{}}).
+		RegisterPostProcessor(pgsgo.GoFmt()).
 		Render()
 }
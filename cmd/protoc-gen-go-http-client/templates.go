@@ -7,14 +7,17 @@ package main
 //
 // Template data structure:
 //   - ServiceName: Name of the service (e.g., "AuthService")
-//   - ImportSuffix: Package suffix for proto imports (e.g., "" or "/investments")
-//   - HasFmt: Whether fmt package is needed (for path parameters)
+//   - Imports: Aliased proto Go packages (the service's own package is "pb")
+//   - HasTime: Whether time package is needed (for retry backoff overrides)
 //   - Methods: Array of MethodTemplateData
 //
+// The default call options (operation name, retry override) come before the
+// caller's call options, so callers can override them.
+//
 // Each method generates a function that:
 // 1. Creates response proto
-// 2. Builds the path (with or without parameters)
-// 3. Calls the appropriate HTTP method (Get/Post)
+// 2. Expands the path template with the request fields
+// 3. Calls the appropriate HTTP method (Get/Post) with the default call options
 // 4. Returns response and error
 const serviceFileTemplate = `// Code generated by protoc-gen-go-http-client. DO NOT EDIT.
 
@@ -22,40 +25,57 @@ package client
 
 import (
 	"context"
-	{{if .HasFmt}}"fmt"
+	{{if .HasTime}}"time"
 	{{end}}
 	"{{.HTTPClientPkg}}"
-	pb "{{.ProtoPackage}}"
+{{range .Imports}}	{{.Alias}} "{{.Path}}"
+{{end}})
+
+// Operation names of {{.ServiceName}} methods, passed to the client Instrumentation
+const (
+{{- range .Methods}}
+	Operation_{{$.ServiceName}}_{{.Name}} = "{{.Operation}}"
+{{- end}}
+)
+
+// Path templates of {{.ServiceName}} methods, expanded with the request fields
+const (
+{{- range .Methods}}
+	{{$.ServiceName}}_{{.Name}}_Path = "{{.HTTP.Path}}"
+{{- end}}
 )
 
 // {{.InterfaceName}} defines the interface for {{.ServiceName}}
 type {{.InterfaceName}} interface {
 {{range .Methods}}	// {{.Name}} makes a {{.HTTP.Method}} request to {{.HTTP.Path}}
-	{{.Name}}(ctx context.Context, req *pb.{{.InputType}}) (*pb.{{.OutputType}}, error)
+	{{.Name}}(ctx context.Context, req *{{.InputType}}, opts ...httpclient.CallOption) (*{{.OutputType}}, error)
 {{end}}}
 
 // {{.ImplName}} provides {{.ServiceName}} operations
 type {{.ImplName}} struct {
 	{{.InterfaceName}}
-	client *http.HTTPClient
+	client *httpclient.HTTPClient
 }
 
 {{range .Methods}}
 // {{.Name}} makes a {{.HTTP.Method}} request to {{.HTTP.Path}}
-func (s *{{$.ImplName}}) {{.Name}}(ctx context.Context, req *pb.{{.InputType}}) (*pb.{{.OutputType}}, error) {
-	resp := &pb.{{.OutputType}}{}
-	{{if .PathConstruction}}path := {{.PathConstruction}}
-	{{else}}path := "{{.HTTP.Path}}"
-	{{end}}{{if eq .HTTP.Method "POST"}}{{if .HTTP.WrapResponseInto}}err := s.client.PostWithWrap(ctx, path, req, resp, "{{.HTTP.WrapResponseInto}}")
-	{{else}}err := s.client.Post(ctx, path, req, resp)
-	{{end}}{{else if eq .HTTP.Method "PUT"}}{{if .HTTP.WrapResponseInto}}err := s.client.PutWithWrap(ctx, path, req, resp, "{{.HTTP.WrapResponseInto}}")
-	{{else}}err := s.client.Put(ctx, path, req, resp)
-	{{end}}{{else if eq .HTTP.Method "PATCH"}}{{if .HTTP.WrapResponseInto}}err := s.client.PatchWithWrap(ctx, path, req, resp, "{{.HTTP.WrapResponseInto}}")
-	{{else}}err := s.client.Patch(ctx, path, req, resp)
-	{{end}}{{else if eq .HTTP.Method "DELETE"}}{{if .HTTP.WrapResponseInto}}err := s.client.DeleteWithWrap(ctx, path, req, resp, "{{.HTTP.WrapResponseInto}}")
-	{{else}}err := s.client.Delete(ctx, path, req, resp)
-	{{end}}{{else}}{{if .HTTP.WrapResponseInto}}err := s.client.GetWithWrap(ctx, path, resp, "{{.HTTP.WrapResponseInto}}")
-	{{else}}err := s.client.Get(ctx, path, resp)
+func (s *{{$.ImplName}}) {{.Name}}(ctx context.Context, req *{{.InputType}}, opts ...httpclient.CallOption) (*{{.OutputType}}, error) {
+	resp := &{{.OutputType}}{}
+	path, err := httpclient.ExpandPath({{$.ServiceName}}_{{.Name}}_Path, req)
+	if err != nil {
+		return nil, err
+	}
+	{{if .CallOptions}}opts = append({{printf "[]httpclient.CallOption{%s}" .CallOptions}}, opts...)
+	{{end}}{{if eq .HTTP.Method "POST"}}{{if .HTTP.WrapResponseInto}}err = s.client.PostWithWrap(ctx, path, req, resp, "{{.HTTP.WrapResponseInto}}", opts...)
+	{{else}}err = s.client.Post(ctx, path, req, resp, opts...)
+	{{end}}{{else if eq .HTTP.Method "PUT"}}{{if .HTTP.WrapResponseInto}}err = s.client.PutWithWrap(ctx, path, req, resp, "{{.HTTP.WrapResponseInto}}", opts...)
+	{{else}}err = s.client.Put(ctx, path, req, resp, opts...)
+	{{end}}{{else if eq .HTTP.Method "PATCH"}}{{if .HTTP.WrapResponseInto}}err = s.client.PatchWithWrap(ctx, path, req, resp, "{{.HTTP.WrapResponseInto}}", opts...)
+	{{else}}err = s.client.Patch(ctx, path, req, resp, opts...)
+	{{end}}{{else if eq .HTTP.Method "DELETE"}}{{if .HTTP.WrapResponseInto}}err = s.client.DeleteWithWrap(ctx, path, req, resp, "{{.HTTP.WrapResponseInto}}", opts...)
+	{{else}}err = s.client.Delete(ctx, path, req, resp, opts...)
+	{{end}}{{else}}{{if .HTTP.WrapResponseInto}}err = s.client.GetWithWrap(ctx, path, resp, "{{.HTTP.WrapResponseInto}}", opts...)
+	{{else}}err = s.client.Get(ctx, path, resp, opts...)
 	{{end}}{{end}}return resp, err
 }
 {{end}}`
@@ -63,70 +83,105 @@ func (s *{{$.ImplName}}) {{.Name}}(ctx context.Context, req *pb.{{.InputType}})
 // nestedServicesFileTemplate generates a file with a grouping client and multiple services.
 //
 // Template data structure:
-//   - Category: Category name (e.g., "Investments")
-//   - CategoryLower: Lowercase category (e.g., "investments")
-//   - ImportSuffix: Package suffix for proto imports (e.g., "/investments")
-//   - HasFmt: Whether fmt package is needed (for path parameters)
-//   - Services: Array of ServiceTemplateData (one for each service in the category)
+//   - Category: Group name prefix (e.g., "Investments", "InvestmentsFunds")
+//   - CategoryLower: Package path from the root (e.g., "investments", "investments.funds")
+//   - Imports: Aliased proto Go packages (the first service's package is "pb")
+//   - HasTime: Whether time package is needed (for retry backoff overrides)
+//   - Services: Array of ServiceTemplateData (one for each service in the group)
+//   - Groups: Array of NestedClientInfo (one for each sub-group)
+//
+// The grouping client is built by new<Impl>, which also builds its sub-groups,
+// so the root client only builds the first-level groups.
 const nestedServicesFileTemplate = `// Code generated by protoc-gen-go-http-client. DO NOT EDIT.
 
 package client
 
 import (
-	"context"
-	{{if .HasFmt}}"fmt"
+	{{if .Services}}"context"
+	{{end}}{{if .HasTime}}"time"
 	{{end}}
 	"{{.HTTPClientPkg}}"
-	pb "{{.ProtoPackage}}"
-)
+{{range .Imports}}	{{.Alias}} "{{.Path}}"
+{{end}})
 
 // {{.InterfaceName}} defines the interface for {{.Category}} services
 type {{.InterfaceName}} interface {
-{{range .Services}}	Get{{.ServiceName}}() {{.InterfaceName}}
+{{range .Services}}	Get{{.Accessor}}() {{.InterfaceName}}
+{{end}}{{range .Groups}}	Get{{.FieldName}}() {{.InterfaceName}}
 {{end}}}
 
 // {{.ImplName}} groups {{.CategoryLower}} services
 type {{.ImplName}} struct {
 	{{.InterfaceName}}
 {{range .Services}}	{{.PrivateField}} *{{.ImplName}}
+{{end}}{{range .Groups}}	{{.PrivateField}} *{{.ImplName}}
 {{end}}}
 
+// new{{.ImplName}} creates the {{.CategoryLower}} services and sub-groups
+func new{{.ImplName}}(client *httpclient.HTTPClient) *{{.ImplName}} {
+	return &{{.ImplName}}{
+{{range .Services}}		{{.PrivateField}}: &{{.ImplName}}{client: client},
+{{end}}{{range .Groups}}		{{.PrivateField}}: new{{.ImplName}}(client),
+{{end}}	}
+}
 {{range .Services}}
-// Get{{.ServiceName}} returns the {{.ServiceName}}
-func (c *{{$.ImplName}}) Get{{.ServiceName}}() {{.InterfaceName}} {
+// Get{{.Accessor}} returns the {{.ServiceName}}
+func (c *{{$.ImplName}}) Get{{.Accessor}}() {{.InterfaceName}} {
+	return c.{{.PrivateField}}
+}
+{{end}}{{range .Groups}}
+// Get{{.FieldName}} returns the {{.TypeName}}
+func (c *{{$.ImplName}}) Get{{.FieldName}}() {{.InterfaceName}} {
 	return c.{{.PrivateField}}
 }
 {{end}}
 
 {{range $svc := .Services}}
+// Operation names of {{$svc.ServiceName}} methods, passed to the client Instrumentation
+const (
+{{- range $svc.Methods}}
+	Operation_{{$svc.ServiceName}}_{{.Name}} = "{{.Operation}}"
+{{- end}}
+)
+
+// Path templates of {{$svc.ServiceName}} methods, expanded with the request fields
+const (
+{{- range $svc.Methods}}
+	{{$svc.ServiceName}}_{{.Name}}_Path = "{{.HTTP.Path}}"
+{{- end}}
+)
+
 // {{$svc.InterfaceName}} defines the interface for {{$svc.ServiceName}}
 type {{$svc.InterfaceName}} interface {
 {{range $svc.Methods}}	// {{.Name}} makes a {{.HTTP.Method}} request to {{.HTTP.Path}}
-	{{.Name}}(ctx context.Context, req *pb.{{.InputType}}) (*pb.{{.OutputType}}, error)
+	{{.Name}}(ctx context.Context, req *{{.InputType}}, opts ...httpclient.CallOption) (*{{.OutputType}}, error)
 {{end}}}
 
 // {{$svc.ImplName}} provides {{$svc.ServiceName}} operations
 type {{$svc.ImplName}} struct {
 	{{$svc.InterfaceName}}
-	client *http.HTTPClient
+	client *httpclient.HTTPClient
 }
 
 {{range $svc.Methods}}
 // {{.Name}} makes a {{.HTTP.Method}} request to {{.HTTP.Path}}
-func (s *{{$svc.ImplName}}) {{.Name}}(ctx context.Context, req *pb.{{.InputType}}) (*pb.{{.OutputType}}, error) {
-	resp := &pb.{{.OutputType}}{}
-	{{if .PathConstruction}}path := {{.PathConstruction}}
-	{{else}}path := "{{.HTTP.Path}}"
-	{{end}}{{if eq .HTTP.Method "POST"}}{{if .HTTP.WrapResponseInto}}err := s.client.PostWithWrap(ctx, path, req, resp, "{{.HTTP.WrapResponseInto}}")
-	{{else}}err := s.client.Post(ctx, path, req, resp)
-	{{end}}{{else if eq .HTTP.Method "PUT"}}{{if .HTTP.WrapResponseInto}}err := s.client.PutWithWrap(ctx, path, req, resp, "{{.HTTP.WrapResponseInto}}")
-	{{else}}err := s.client.Put(ctx, path, req, resp)
-	{{end}}{{else if eq .HTTP.Method "PATCH"}}{{if .HTTP.WrapResponseInto}}err := s.client.PatchWithWrap(ctx, path, req, resp, "{{.HTTP.WrapResponseInto}}")
-	{{else}}err := s.client.Patch(ctx, path, req, resp)
-	{{end}}{{else if eq .HTTP.Method "DELETE"}}{{if .HTTP.WrapResponseInto}}err := s.client.DeleteWithWrap(ctx, path, req, resp, "{{.HTTP.WrapResponseInto}}")
-	{{else}}err := s.client.Delete(ctx, path, req, resp)
-	{{end}}{{else}}{{if .HTTP.WrapResponseInto}}err := s.client.GetWithWrap(ctx, path, resp, "{{.HTTP.WrapResponseInto}}")
-	{{else}}err := s.client.Get(ctx, path, resp)
+func (s *{{$svc.ImplName}}) {{.Name}}(ctx context.Context, req *{{.InputType}}, opts ...httpclient.CallOption) (*{{.OutputType}}, error) {
+	resp := &{{.OutputType}}{}
+	path, err := httpclient.ExpandPath({{$svc.ServiceName}}_{{.Name}}_Path, req)
+	if err != nil {
+		return nil, err
+	}
+	{{if .CallOptions}}opts = append({{printf "[]httpclient.CallOption{%s}" .CallOptions}}, opts...)
+	{{end}}{{if eq .HTTP.Method "POST"}}{{if .HTTP.WrapResponseInto}}err = s.client.PostWithWrap(ctx, path, req, resp, "{{.HTTP.WrapResponseInto}}", opts...)
+	{{else}}err = s.client.Post(ctx, path, req, resp, opts...)
+	{{end}}{{else if eq .HTTP.Method "PUT"}}{{if .HTTP.WrapResponseInto}}err = s.client.PutWithWrap(ctx, path, req, resp, "{{.HTTP.WrapResponseInto}}", opts...)
+	{{else}}err = s.client.Put(ctx, path, req, resp, opts...)
+	{{end}}{{else if eq .HTTP.Method "PATCH"}}{{if .HTTP.WrapResponseInto}}err = s.client.PatchWithWrap(ctx, path, req, resp, "{{.HTTP.WrapResponseInto}}", opts...)
+	{{else}}err = s.client.Patch(ctx, path, req, resp, opts...)
+	{{end}}{{else if eq .HTTP.Method "DELETE"}}{{if .HTTP.WrapResponseInto}}err = s.client.DeleteWithWrap(ctx, path, req, resp, "{{.HTTP.WrapResponseInto}}", opts...)
+	{{else}}err = s.client.Delete(ctx, path, req, resp, opts...)
+	{{end}}{{else}}{{if .HTTP.WrapResponseInto}}err = s.client.GetWithWrap(ctx, path, resp, "{{.HTTP.WrapResponseInto}}", opts...)
+	{{else}}err = s.client.Get(ctx, path, resp, opts...)
 	{{end}}{{end}}return resp, err
 }
 {{end}}
@@ -137,10 +192,11 @@ func (s *{{$svc.ImplName}}) {{.Name}}(ctx context.Context, req *pb.{{.InputType}
 // Template data structure:
 //   - ClientName: Name of the root client (e.g., "IniciadorClient")
 //   - TopLevelServices: Array of ServiceInfo for top-level services
-//   - NestedClients: Array of NestedClientInfo for nested service groups
+//   - NestedClients: Array of NestedClientInfo for the first-level service groups
 //
 // The generated client follows an immutable pattern - to change the token,
 // create a new client instance rather than mutating the existing one.
+// Configuration is passed to the constructor as httpclient.ClientOption values.
 // Note: Root client template doesn't need HTTPClientPkg since it's passed directly to generateRootClient
 // and must be added dynamically based on configuration
 const rootClientTemplate = `// Code generated by protoc-gen-go-http-client. DO NOT EDIT.
@@ -149,7 +205,6 @@ package client
 
 import (
 	"net/http"
-	"time"
 
 	httpclient "{{.HTTPClientPkg}}"
 )
@@ -185,46 +240,41 @@ func (c *{{$.ImplName}}) Get{{.FieldName}}() {{.InterfaceName}} {
 //
 // Parameters:
 //   - baseURL: API base URL (e.g., "https://data.sandbox.iniciador.com.br")
-func New{{.ClientName}}(baseURL string) *{{.ImplName}} {
-	return New{{.ClientName}}WithHTTPClient(baseURL, &http.Client{Timeout: 30 * time.Second})
-}
-
-// New{{.ClientName}}WithHTTPClient creates a new HTTP client with a custom http.Client.
+//   - opts: Client options (timeout, transport, headers, retries, hooks, ...)
 //
-// This constructor allows you to provide a custom http.Client with middleware,
-// custom transports, timeouts, etc. This is useful for:
-//   - Adding OpenTelemetry tracing via middleware
-//   - Implementing authentication via custom transport/middleware
-//   - Adding retry logic for transient failures
-//   - Custom timeout or connection pooling settings
+// Example:
 //
-// Parameters:
-//   - baseURL: API base URL (e.g., "https://data.sandbox.iniciador.com.br")
-//   - customHTTPClient: Custom *http.Client with your desired configuration
+//	client := New{{.ClientName}}(baseURL,
+//		httpclient.WithTimeout(10*time.Second),
+//		httpclient.WithUserAgent("my-service/1.0"),
+//		httpclient.WithHeader("X-Tenant", tenant),
+//		httpclient.WithRetryPolicy(httpclient.DefaultRetryPolicy()),
+//		httpclient.WithMiddleware(
+//			httpclient.RequestIDMiddleware(""),
+//			httpclient.RedactionMiddleware("X-Api-Key"),
+//			httpclient.LoggingMiddleware(slog.Default()),
+//		),
+//	)
 //
-// Example with middleware:
+// Middlewares can also be composed explicitly with httpclient.Chain and passed
+// with httpclient.WithTransport.
 //
-//	customClient := &http.Client{
-//		Transport: http_client.Chain(
-//			http.DefaultTransport,
-//			http_client.OTelMiddleware(),
-//			iniciador_client.IniciadorAuthMiddleware(tokenManager),
-//		),
-//		Timeout: 30 * time.Second,
-//	}
-//	client := New{{.ClientName}}WithHTTPClient(baseURL, customClient)
-func New{{.ClientName}}WithHTTPClient(baseURL string, customHTTPClient *http.Client) *{{.ImplName}} {
-	httpClient := &httpclient.HTTPClient{
-		BaseURL:    baseURL,
-		HTTPClient: customHTTPClient,
-	}
+// Service groups can target a different host with httpclient.WithServiceBaseURL,
+// using the accessor name without the "Get" prefix (e.g., "Investments" for GetInvestments).
+func New{{.ClientName}}(baseURL string, opts ...httpclient.ClientOption) *{{.ImplName}} {
+	httpClient := httpclient.NewHTTPClient(baseURL, opts...)
 
 	return &{{.ImplName}}{
 		httpClient: httpClient,
-{{range .TopLevelServices}}		{{.PrivateField}}: &{{.ImplName}}{client: httpClient},
-{{end}}{{range .NestedClients}}		{{.PrivateField}}: &{{.ImplName}}{
-{{range .Services}}			{{.PrivateField}}: &{{.ImplName}}{client: httpClient},
-{{end}}		},
+{{range .TopLevelServices}}		{{.PrivateField}}: &{{.ImplName}}{client: httpClient.ForService("{{.FieldName}}")},
+{{end}}{{range .NestedClients}}		{{.PrivateField}}: new{{.ImplName}}(httpClient.ForService("{{.FieldName}}")),
 {{end}}	}
 }
+
+// New{{.ClientName}}WithHTTPClient creates a new HTTP client with a custom http.Client.
+//
+// Deprecated: Use New{{.ClientName}}(baseURL, httpclient.WithHTTPClient(customHTTPClient)).
+func New{{.ClientName}}WithHTTPClient(baseURL string, customHTTPClient *http.Client) *{{.ImplName}} {
+	return New{{.ClientName}}(baseURL, httpclient.WithHTTPClient(customHTTPClient))
+}
 `
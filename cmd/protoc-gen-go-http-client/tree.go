@@ -0,0 +1,288 @@
+// Package main contains the client hierarchy logic.
+//
+// This file builds the tree of client groups mirroring the proto packages
+// under the root package, and checks that the generated names don't collide.
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// buildServiceTree groups services into a tree mirroring their packages.
+//
+// Examples, with root package "vendors.iniciador":
+//   - vendors.iniciador.AuthService → root group
+//   - vendors.iniciador.investments.FundsService → group ["investments"]
+//   - vendors.iniciador.investments.funds.QuotesService → group ["investments", "funds"]
+//
+// Intermediate packages without services still get a group. Groups take
+// their name overrides from naming, keyed by proto package (see
+// extractGroupNaming).
+func buildServiceTree(services []Service, rootPkg string, naming map[string]GroupNaming) *ServiceGroup {
+	root := &ServiceGroup{}
+
+	for _, svc := range services {
+		group := root
+		if svc.Package != rootPkg {
+			for _, segment := range strings.Split(strings.TrimPrefix(svc.Package, rootPkg+"."), ".") {
+				group = group.child(segment)
+				group.Naming = naming[rootPkg+"."+strings.Join(group.Path, ".")]
+			}
+		}
+		group.Services = append(group.Services, svc)
+	}
+
+	root.sort()
+	return root
+}
+
+// child returns the sub-group named segment, creating it if needed.
+func (g *ServiceGroup) child(segment string) *ServiceGroup {
+	for _, sub := range g.Groups {
+		if sub.Path[len(sub.Path)-1] == segment {
+			return sub
+		}
+	}
+
+	path := append(append([]string(nil), g.Path...), segment)
+	sub := &ServiceGroup{Path: path}
+	g.Groups = append(g.Groups, sub)
+	return sub
+}
+
+// sort sorts services and sub-groups by name, recursively, for deterministic output.
+func (g *ServiceGroup) sort() {
+	sort.Slice(g.Services, func(i, j int) bool {
+		return g.Services[i].Name < g.Services[j].Name
+	})
+	sort.Slice(g.Groups, func(i, j int) bool {
+		return g.Groups[i].Name() < g.Groups[j].Name()
+	})
+	for _, sub := range g.Groups {
+		sub.sort()
+	}
+}
+
+// Name returns the last package segment of the group (e.g., "funds").
+func (g *ServiceGroup) Name() string {
+	if len(g.Path) == 0 {
+		return ""
+	}
+	return g.Path[len(g.Path)-1]
+}
+
+// Descendants returns all sub-groups, depth first, parents before children.
+func (g *ServiceGroup) Descendants() []*ServiceGroup {
+	var groups []*ServiceGroup
+	for _, sub := range g.Groups {
+		groups = append(groups, sub)
+		groups = append(groups, sub.Descendants()...)
+	}
+	return groups
+}
+
+// TypeName returns the interface name of the group, named after its package
+// path unless overridden.
+//
+// Examples:
+//   - ["investments"] → "InvestmentsClient"
+//   - ["investments", "funds"] → "InvestmentsFundsClient"
+//   - ["open_finance"] → "OpenFinanceClient"
+func (g *ServiceGroup) TypeName() string {
+	if g.Naming.TypeName != "" {
+		return g.Naming.TypeName
+	}
+
+	var name string
+	for _, segment := range g.Path {
+		name += goName(segment)
+	}
+	return name + "Client"
+}
+
+// AccessorName returns the accessor name of the group on its parent without
+// the "Get" prefix (e.g., "Funds" for ["investments", "funds"]).
+func (g *ServiceGroup) AccessorName() string {
+	if g.Naming.Accessor != "" {
+		return g.Naming.Accessor
+	}
+	return goName(g.Name())
+}
+
+// FileName returns the file name of the group (e.g., "investments_funds.gen.go").
+func (g *ServiceGroup) FileName() string {
+	if g.Naming.FileName != "" {
+		return g.Naming.FileName + ".gen.go"
+	}
+	return strings.ToLower(strings.Join(g.Path, "_")) + ".gen.go"
+}
+
+// serviceFileName returns the file name of a top-level service (e.g., "auth.gen.go").
+func serviceFileName(svc Service) string {
+	if svc.FileName != "" {
+		return svc.FileName + ".gen.go"
+	}
+	return strings.ToLower(strings.TrimSuffix(svc.Name, "Service")) + ".gen.go"
+}
+
+// serviceAccessorName returns the accessor name of a service without the
+// "Get" prefix, unless overridden: top-level services drop the "Service"
+// suffix (e.g., "Auth"), services in groups keep their name (e.g., "FundsService").
+func serviceAccessorName(svc Service, topLevel bool) string {
+	switch {
+	case svc.Accessor != "":
+		return svc.Accessor
+	case topLevel && svc.Name != "Service":
+		return strings.TrimSuffix(svc.Name, "Service")
+	default:
+		return svc.Name
+	}
+}
+
+// servicePrivateFieldName returns the private field holding a service,
+// derived from its accessor when overridden.
+func servicePrivateFieldName(svc Service) string {
+	if svc.Accessor != "" {
+		return generatePrivateFieldName(svc.Accessor)
+	}
+	return generatePrivateFieldName(svc.Name)
+}
+
+// validateServiceTree checks that the generated Go names and files don't collide.
+//
+// The client package is flat, so type names must be unique across the tree;
+// accessors and fields must be unique within each group, and each group and
+// top-level service needs its own file.
+func validateServiceTree(root *ServiceGroup, clientName string) error {
+	types := map[string]string{} // Go type name -> what declares it
+	declare := func(name, what string) error {
+		for _, typeName := range []string{generateInterfaceName(name), generateImplName(name)} {
+			if prev, ok := types[typeName]; ok {
+				return fmt.Errorf("name collision: %s and %s both generate type %s", prev, what, typeName)
+			}
+			types[typeName] = what
+		}
+		return nil
+	}
+
+	files := map[string]string{"client.gen.go": "root client " + clientName}
+	addFile := func(name, what string) error {
+		if prev, ok := files[name]; ok {
+			return fmt.Errorf("name collision: %s and %s both generate file %s", prev, what, name)
+		}
+		files[name] = what
+		return nil
+	}
+
+	if err := declare(clientName, "root client"); err != nil {
+		return err
+	}
+
+	for _, group := range append([]*ServiceGroup{root}, root.Descendants()...) {
+		topLevel := len(group.Path) == 0
+		where := "package " + strings.Join(group.Path, ".")
+		if topLevel {
+			where = "root package"
+		} else {
+			if err := declare(group.TypeName(), "group "+strings.Join(group.Path, ".")); err != nil {
+				return err
+			}
+			if err := addFile(group.FileName(), "group "+strings.Join(group.Path, ".")); err != nil {
+				return err
+			}
+		}
+
+		accessors := map[string]string{} // accessor or private field -> what declares it
+		if topLevel {
+			accessors["httpClient"] = "root client " + clientName
+		}
+		access := func(accessor, field, what string) error {
+			for _, name := range []string{"Get" + accessor, field} {
+				if prev, ok := accessors[name]; ok {
+					return fmt.Errorf("name collision in %s: %s and %s both generate %s", where, prev, what, name)
+				}
+				accessors[name] = what
+			}
+			return nil
+		}
+
+		for _, svc := range group.Services {
+			what := "service " + svc.Package + "." + svc.Name
+			if err := declare(svc.Name, what); err != nil {
+				return err
+			}
+			if err := access(serviceAccessorName(svc, topLevel), servicePrivateFieldName(svc), what); err != nil {
+				return err
+			}
+			if topLevel {
+				if err := addFile(serviceFileName(svc), what); err != nil {
+					return err
+				}
+			}
+		}
+		for _, sub := range group.Groups {
+			what := "group " + strings.Join(sub.Path, ".")
+			if err := access(sub.AccessorName(), generatePrivateFieldName(sub.TypeName()), what); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateMockNames checks that the helpers of the clientmock fakes don't
+// collide with the methods of the service they fake.
+//
+// Each fake declares Reset, a <Method>Func stub field and a <Method>Calls
+// method per method, and a <Service><Method>Call type in the flat clientmock
+// package next to the fakes themselves.
+func validateMockNames(root *ServiceGroup, clientName string) error {
+	types := map[string]string{generateInterfaceName(clientName): "root client " + clientName}
+	for _, group := range root.Descendants() {
+		types[generateInterfaceName(group.TypeName())] = "group " + strings.Join(group.Path, ".")
+	}
+
+	groups := append([]*ServiceGroup{root}, root.Descendants()...)
+	for _, group := range groups {
+		for _, svc := range group.Services {
+			types[generateInterfaceName(svc.Name)] = "service " + svc.Package + "." + svc.Name
+		}
+	}
+
+	for _, group := range groups {
+		for _, svc := range group.Services {
+			fake := "fake " + generateInterfaceName(svc.Name)
+			methods := map[string]bool{}
+			for _, m := range svc.Methods {
+				if m.HTTP != nil {
+					methods[m.Name] = true
+				}
+			}
+
+			for _, m := range svc.Methods {
+				if m.HTTP == nil {
+					continue
+				}
+				if m.Name == "Reset" {
+					return fmt.Errorf("name collision in %s: method Reset and the Reset helper", fake)
+				}
+				for _, helper := range []string{m.Name + "Func", m.Name + "Calls"} {
+					if methods[helper] {
+						return fmt.Errorf("name collision in %s: method %s and the %s helper of method %s", fake, helper, helper, m.Name)
+					}
+				}
+
+				call := generateInterfaceName(svc.Name) + m.Name + "Call"
+				if prev, ok := types[call]; ok {
+					return fmt.Errorf("name collision in clientmock: %s and the calls of %s.%s both generate type %s", prev, svc.Name, m.Name, call)
+				}
+				types[call] = "the calls of " + svc.Name + "." + m.Name
+			}
+		}
+	}
+
+	return nil
+}
@@ -1,49 +1,98 @@
 package main
 
-import "strings"
+import (
+	"go/token"
+	"strings"
+	"unicode"
 
-// ClientConfig holds the parsed configuration from the client= parameter.
+	http_client "github.com/getfrontierhq/buf-public-apis/gen/go/http_client"
+)
+
+// ClientConfig holds the parsed configuration of a client root, from the
+// client= parameter or the (http_client.client) file option.
 // Format: "package:subdir" (e.g., "vendors.iniciador:client")
 type ClientConfig struct {
 	RootPackage   string // Proto package prefix (e.g., "vendors.iniciador")
 	OutputSubdir  string // Output subdirectory (e.g., "client")
 	ClientName    string // Root client name (e.g., "IniciadorClient", derived from package)
-	GoModulePath  string // Go module path (e.g., "github.com/getfrontierhq/schema/pkg/go")
-	HTTPClientPkg string // Full path to HTTP client package (computed from above)
+	GoModulePath  string // Fallback import path of the output directory (go_module_path)
+	HTTPClientPkg string // Import path of the HTTP client runtime (runtimePackage)
+	ClientPkg     string // Full path to the generated client package (see resolveImportPaths)
+	Mock          bool   // Generate the clientmock package (mock=true)
+	FakeServer    bool   // Generate the clienttest package (fakeserver=true)
+
+	OmitEmpty       bool   // Skip methods without google.api.http annotation (omitempty, default true)
+	OmitEmptyPrefix string // Path prefix of the default routes (omitempty_prefix)
 }
 
 // Service represents a parsed proto service with its methods.
 type Service struct {
 	Name      string   // Service name (e.g., "AuthService")
 	Package   string   // Full proto package (e.g., "vendors.iniciador")
-	GoPackage string   // Go package path for imports
+	ProtoFile string   // Proto file path (e.g., "vendors/iniciador/links.proto")
+	GoPackage string   // Go import path of the service's file (e.g., "github.com/org/repo/gen/go/vendors/iniciador")
+	Accessor  string   // Accessor name override, without "Get" (http_client.service)
+	FileName  string   // File name override, without ".gen.go" (http_client.service)
 	Methods   []Method // Service methods
 }
 
+// ServiceGroup is a node of the client hierarchy, mirroring a proto package
+// under the root package.
+//
+// Example: services in vendors.iniciador.investments.funds, with root
+// vendors.iniciador, belong to the group at Path ["investments", "funds"].
+type ServiceGroup struct {
+	Path     []string        // Package segments from the root package (empty for the root)
+	Services []Service       // Services in this package, sorted by name
+	Groups   []*ServiceGroup // Sub-packages, sorted by name
+	Naming   GroupNaming     // Name overrides (http_client.group)
+}
+
+// GroupNaming holds the name overrides of a service group, from the
+// (http_client.group) file option. Empty fields keep the derived names.
+type GroupNaming struct {
+	Accessor string // Accessor name, without "Get" (e.g., "OpenFinance")
+	TypeName string // Interface name (e.g., "OpenFinanceClient")
+	FileName string // File name, without ".gen.go" (e.g., "open_finance")
+}
+
 // Method represents a single RPC method in a service.
 type Method struct {
 	Name       string    // Method name (e.g., "Authenticate")
 	InputType  string    // Request message type (e.g., "AuthenticateRequest")
 	OutputType string    // Response message type (e.g., "AuthenticateResponse")
+	Input      GoType    // Go type of the request message
+	Output     GoType    // Go type of the response message
 	HTTP       *HTTPInfo // HTTP annotation (nil if not present)
 }
 
+// GoType identifies a generated Go message type.
+type GoType struct {
+	ImportPath  string // e.g., "google.golang.org/protobuf/types/known/emptypb"
+	PackageName string // e.g., "emptypb"
+	Name        string // e.g., "Empty", or "Outer_Inner" for nested messages
+}
+
 // HTTPInfo contains parsed google.api.http annotation data.
 type HTTPInfo struct {
 	Method           string   // HTTP method: "GET", "POST", "PUT", "DELETE", "PATCH"
 	Path             string   // URL path template (e.g., "/v1/data/links/{id}")
 	PathParams       []string // Extracted path parameters (e.g., ["id", "link_id"])
 	WrapResponseInto string   // Field name to wrap response array into (e.g., "response")
+	UnwrapResponse   string   // JSON pointer of the response in its envelope (e.g., "/data")
+
+	Retry *http_client.RetryPolicy // Per-method retry override (nil if not annotated)
 }
 
 // ServiceTemplateData holds data for generating a service file.
 type ServiceTemplateData struct {
 	ServiceName   string               // e.g., "AuthService"
-	ImportSuffix  string               // "" for top-level, "/investments" for nested
-	HasFmt        bool                 // true if any method has path parameters
+	Accessor      string               // Accessor name in a group without "Get" (e.g., "FundsService")
+	HasTime       bool                 // true if any method has a retry backoff override
 	Methods       []MethodTemplateData // all methods in the service
 	HTTPClientPkg string               // Full path to HTTP client package
-	ProtoPackage  string               // Full path to proto package
+	ClientPkg     string               // Full path to the generated client package (for mocks)
+	Imports       []GoImport           // Aliased imports of the proto Go packages
 	// Interface generation fields
 	InterfaceName string // e.g., "AuthService" (interface = base name)
 	ImplName      string // e.g., "AuthServiceImpl" (struct = Impl suffix)
@@ -52,22 +101,24 @@ type ServiceTemplateData struct {
 
 // MethodTemplateData holds data for generating a single method.
 type MethodTemplateData struct {
-	Name             string    // e.g., "Authenticate"
-	InputType        string    // e.g., "AuthenticateRequest"
-	OutputType       string    // e.g., "AuthenticateResponse"
-	HTTP             *HTTPInfo // HTTP method, path, and parameters
-	PathConstruction string    // Go code to build the path (if has parameters)
+	Name        string    // e.g., "Authenticate"
+	InputType   string    // Qualified Go type (e.g., "pb.AuthenticateRequest", "emptypb.Empty")
+	OutputType  string    // Qualified Go type (e.g., "pb.AuthenticateResponse")
+	HTTP        *HTTPInfo // HTTP method, path, and parameters
+	Operation   string    // Full RPC name (e.g., "/vendors.iniciador.AuthService/Authenticate")
+	CallOptions string    // Default call options (e.g., "httpclient.Operation(...), httpclient.RetryOverride(...)")
 }
 
 // NestedServicesTemplateData holds data for generating a nested services file.
 type NestedServicesTemplateData struct {
-	Category      string                // e.g., "Investments"
-	CategoryLower string                // e.g., "investments"
-	ImportSuffix  string                // e.g., "/investments"
-	HasFmt        bool                  // true if any method has path parameters
+	Category      string                // e.g., "Investments", "InvestmentsFunds"
+	CategoryLower string                // e.g., "investments", "investments.funds"
+	HasTime       bool                  // true if any method has a retry backoff override
 	Services      []ServiceTemplateData // all services in this category
+	Groups        []NestedClientInfo    // sub-groups of this category
 	HTTPClientPkg string                // Full path to HTTP client package
-	ProtoPackage  string                // Full path to proto package (with suffix)
+	ClientPkg     string                // Full path to the generated client package (for mocks)
+	Imports       []GoImport            // Aliased imports of the proto Go packages
 	// Interface generation fields
 	InterfaceName string // e.g., "InvestmentsClient" (interface = base name)
 	ImplName      string // e.g., "InvestmentsClientImpl" (struct = Impl suffix)
@@ -85,18 +136,18 @@ type ServiceInfo struct {
 
 // NestedClientInfo holds information about a nested client group.
 type NestedClientInfo struct {
-	FieldName     string        // e.g., "Investments"
-	TypeName      string        // e.g., "InvestmentsClient" (interface name)
-	ImplName      string        // e.g., "InvestmentsClientImpl" (struct name)
-	InterfaceName string        // e.g., "InvestmentsClient" (same as TypeName for compatibility)
-	PrivateField  string        // e.g., "investments"
-	Services      []ServiceInfo // Services within this client
+	FieldName     string // e.g., "Investments" (accessor name without "Get")
+	TypeName      string // e.g., "InvestmentsClient" (interface name)
+	ImplName      string // e.g., "InvestmentsClientImpl" (struct name)
+	InterfaceName string // e.g., "InvestmentsClient" (same as TypeName for compatibility)
+	PrivateField  string // e.g., "investments"
 }
 
 // RootClientTemplateData holds data for generating the root client file.
 type RootClientTemplateData struct {
 	ClientName       string             // e.g., "IniciadorClient"
 	HTTPClientPkg    string             // Full path to HTTP client package
+	ClientPkg        string             // Full path to the generated client package (for mocks)
 	InterfaceName    string             // e.g., "IniciadorClient" (interface = base name)
 	ImplName         string             // e.g., "IniciadorClientImpl" (struct = Impl suffix)
 	TopLevelServices []ServiceInfo      // Top-level services (Auth, Links)
@@ -106,7 +157,8 @@ type RootClientTemplateData struct {
 // generateInterfaceName creates an interface name from a service/client name
 // The interface gets the base name (no suffix)
 // Examples: "AccountsService" -> "AccountsService"
-//           "IniciadorClient" -> "IniciadorClient"
+//
+//	"IniciadorClient" -> "IniciadorClient"
 func generateInterfaceName(serviceName string) string {
 	return serviceName
 }
@@ -114,15 +166,17 @@ func generateInterfaceName(serviceName string) string {
 // generateImplName creates an implementation struct name from a service/client name
 // The implementation gets "Impl" suffix
 // Examples: "AccountsService" -> "AccountsServiceImpl"
-//           "IniciadorClient" -> "IniciadorClientImpl"
+//
+//	"IniciadorClient" -> "IniciadorClientImpl"
 func generateImplName(serviceName string) string {
 	return serviceName + "Impl"
 }
 
 // generatePrivateFieldName creates a private field name from a service name
 // Examples: "AccountsService" -> "accounts"
-//           "IniciadorClient" -> "iniciador"
-//           "TreasureTitlesService" -> "treasureTitles"
+//
+//	"IniciadorClient" -> "iniciador"
+//	"TreasureTitlesService" -> "treasureTitles"
 func generatePrivateFieldName(serviceName string) string {
 	if serviceName == "" {
 		return ""
@@ -134,7 +188,41 @@ func generatePrivateFieldName(serviceName string) string {
 
 	// Lowercase first letter
 	if len(name) > 0 {
-		return strings.ToLower(name[:1]) + name[1:]
+		name = strings.ToLower(name[:1]) + name[1:]
+	}
+
+	// "TypeService" -> "type_", not the keyword
+	if token.IsKeyword(name) {
+		name += "_"
 	}
 	return name
 }
+
+// goName converts a proto name to an exported Go identifier.
+// Examples: "iniciador" -> "Iniciador"
+//
+//	"open_finance" -> "OpenFinance"
+//	"open-finance.v2" -> "OpenFinanceV2"
+//	"3ds" -> "X3ds"
+func goName(name string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range name {
+		switch {
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r):
+			// Separators ("_", "-", ".") start a new word
+			upper = true
+		case upper:
+			b.WriteRune(unicode.ToUpper(r))
+			upper = false
+		default:
+			b.WriteRune(r)
+		}
+	}
+
+	ident := b.String()
+	if ident == "" || unicode.IsDigit([]rune(ident)[0]) {
+		ident = "X" + ident
+	}
+	return ident
+}
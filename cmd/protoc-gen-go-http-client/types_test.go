@@ -63,6 +63,7 @@ func TestGeneratePrivateFieldName(t *testing.T) {
 		{"InvestmentsClient", "investments"},
 		{"AuthService", "auth"},
 		{"LinksService", "links"},
+		{"TypeService", "type_"},
 		{"", ""},
 	}
 
@@ -76,3 +77,27 @@ func TestGeneratePrivateFieldName(t *testing.T) {
 		})
 	}
 }
+
+func TestGoName(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"iniciador", "Iniciador"},
+		{"open_finance", "OpenFinance"},
+		{"open-finance.v2", "OpenFinanceV2"},
+		{"Investments", "Investments"},
+		{"3ds", "X3ds"},
+		{"", "X"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result := goName(tt.input)
+			if result != tt.expected {
+				t.Errorf("goName(%q) = %q, want %q",
+					tt.input, result, tt.expected)
+			}
+		})
+	}
+}
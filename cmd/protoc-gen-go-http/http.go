@@ -20,6 +20,7 @@ const (
 	potPackage     = protogen.GoImportPath("github.com/getfrontierhq/buf-public-apis/pkg/gohttp")
 	binderPackage  = protogen.GoImportPath("github.com/getfrontierhq/buf-public-apis/pkg/gohttp/binder")
 	optionPackage  = protogen.GoImportPath("github.com/getfrontierhq/buf-public-apis/pkg/gohttp/option")
+	clientPackage  = protogen.GoImportPath("github.com/getfrontierhq/buf-public-apis/pkg/gohttp/httpclient")
 
 	deprecationComment = "// Deprecated: Do not use."
 )
@@ -70,6 +71,7 @@ func generateFileContent(gen *protogen.Plugin, file *protogen.File, g *protogen.
 	g.P("var _ = new(", potPackage.Ident("ServiceDescriptor"), ")")
 	g.P("var _ = new(", binderPackage.Ident("RequestDecoder"), ")")
 	g.P("var _ = new(", optionPackage.Ident("BinderOptions"), ")")
+	g.P("var _ = new(", clientPackage.Ident("HTTPClient"), ")")
 
 	for _, service := range file.Services {
 		genService(gen, file, g, service, omitempty, omitemptyPrefix)
@@ -21,12 +21,12 @@ type {{$svcType}}HTTPServer interface {
 {{- end}}
 }
 
-func Register{{$svcType}}HTTPServer(srv {{$svcType}}HTTPServer) http.Handler {
-  return gohttp.RegisterService(&_{{$svcType}}_HTTP_ServiceDesc, srv)
+func Register{{$svcType}}HTTPServer(srv {{$svcType}}HTTPServer, opts ...option.ServerOption) http.Handler {
+  return gohttp.RegisterService(&_{{$svcType}}_HTTP_ServiceDesc, srv, opts...)
 }
 
-func Register{{$svcType}}HTTPServerWithChi(srv {{$svcType}}HTTPServer, router v5.Router) http.Handler {
-  return gohttp.RegisterServiceWithChi(&_{{$svcType}}_HTTP_ServiceDesc, srv, router)
+func Register{{$svcType}}HTTPServerWithChi(srv {{$svcType}}HTTPServer, router v5.Router, opts ...option.ServerOption) http.Handler {
+  return gohttp.RegisterServiceWithChi(&_{{$svcType}}_HTTP_ServiceDesc, srv, router, opts...)
 }
 
 {{range .Methods}}
@@ -52,6 +52,7 @@ var _{{$svcType}}_HTTP_ServiceDesc = gohttp.ServiceDescriptor{
     {{- range .Methods}}
     {
       MethodName: "{{.Name}}",
+      Operation: Operation_{{$svcType}}_{{.OriginalName}},
       HttpMethod: "{{.Method}}",
       HttpPath: "{{.Path}}",
       Handler: _{{$svcType}}_{{.Name}}{{.Num}}_HTTP_Handler,
@@ -67,49 +68,44 @@ type {{$svcType}}HTTPClient interface {
 }
 
 type {{$svcType}}HTTPClientImpl struct{
-  baseUrl string
-	client  *http.Client
+  client *httpclient.HTTPClient
 }
 
 func New{{$svcType}}HTTPClient (opts ...option.ClientOption) {{$svcType}}HTTPClient {
   options := option.NewClientOptions(opts...)
-	return &{{$svcType}}HTTPClientImpl{
-    baseUrl: options.BaseURL,
-    client: &http.Client{
-      Timeout: options.Timeout,
-    },
+  return New{{$svcType}}HTTPClientWithRuntime(httpclient.NewHTTPClient(options.BaseURL, httpclient.WithTimeout(options.Timeout)))
+}
+
+// New{{$svcType}}HTTPClientWithRuntime returns a client sending its requests
+// through client, e.g. to share its retries, middlewares and instrumentation.
+func New{{$svcType}}HTTPClientWithRuntime(client *httpclient.HTTPClient) {{$svcType}}HTTPClient {
+  return &{{$svcType}}HTTPClientImpl{
+    client: client,
   }
 }
 
 {{range .MethodSets}}
 func (c *{{$svcType}}HTTPClientImpl) {{.Name}}(ctx context.Context, in *{{.Request}}, opts ...option.BinderOption) (*{{.Reply}}, error) {
-	out := new({{.Reply}})
-  url := fmt.Sprintf("%s%s", c.baseUrl, {{$svcType}}_{{.OriginalName}}_Path)
-  req, err := http.NewRequest({{$svcType}}_{{.OriginalName}}_Method, url, nil)
+  out := new({{.Reply}})
+  path, err := httpclient.ExpandPath({{$svcType}}_{{.OriginalName}}_Path, in)
   if err != nil {
     return nil, err
   }
   opts = append(opts, option.WithOperation(Operation_{{$svcType}}_{{.OriginalName}}))
-  if err = binder.NewRequestEncoder(req, opts...).Bind(in); err != nil {
-      return nil, err
+  callOpts, err := httpclient.BinderCallOptions(opts...)
+  if err != nil {
+    return nil, err
   }
-  req = req.WithContext(ctx)
-  res, err := c.client.Do(req)
-	if err != nil {
-		return nil, err
-	}
-  defer res.Body.Close()
-	dec := &binder.ResponseDecoder{Response: res}
-	if err := errors.ErrorMap[res.StatusCode]; err != nil {
-		customErr := new(errors.Error)
-    if err := dec.BindBody(customErr); err != nil {
-      return nil, err
-    }
-		return nil, customErr
-	}
-	if err := dec.BindBody(out); err != nil {
+{{- if eq .Method "GET"}}
+  query, err := binder.EncodeQuery(in)
+  if err != nil {
+    return nil, err
+  }
+  callOpts = append(callOpts, httpclient.Query(query))
+{{- end}}
+  if err := c.client.Do(ctx, {{$svcType}}_{{.OriginalName}}_Method, path, {{if eq .Method "GET"}}nil{{else}}in{{end}}, out, callOpts...); err != nil {
     return nil, err
   }
-	return out, nil
+  return out, nil
 }
 {{end}}
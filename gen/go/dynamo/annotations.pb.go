@@ -53,13 +53,40 @@
 //     [(dynamo.key) = {column_name: "email"}]  // No key type
 //
 // (dynamo.gsi) - Global Secondary Index (repeatable)
-//   Use IndexConfig message to specify index name and key type.
+//   Use IndexConfig message to specify index name and key type, and
+//   optionally the projection and a sparse index.
 //   Example: [(dynamo.gsi) = {name: "email-index", key: KEY_TYPE_HASH}]
+//   Example: [(dynamo.gsi) = {name: "status-index", key: KEY_TYPE_HASH, sparse: true, projection_type: PROJECTION_TYPE_KEYS_ONLY}]
 //   Multiple GSIs: Use multiple (dynamo.gsi) annotations
 //
 // (dynamo.lsi) - Local Secondary Index (repeatable)
 //   Same format as GSI.
 //   Example: [(dynamo.lsi) = {name: "timestamp-index", key: KEY_TYPE_RANGE}]
+//
+// (dynamo.attribute) - Attribute encoding
+//   Use AttributeConfig message to omit empty values, store repeated fields
+//   as sets or timestamps as Unix seconds, or mark the version attribute of
+//   optimistic locking.
+//   Example: [(dynamo.attribute) = {omitempty: true, set: SET_TYPE_STRING}]
+//   Example: [(dynamo.attribute) = {version: true}]
+//
+// (dynamo.composite_key) - Composite key attributes (message option, repeatable)
+//   Use CompositeKeyConfig message to derive a table or index key attribute
+//   from a template over the message fields. The plugin generates functions
+//   computing and parsing the attribute, and key conditions.
+//   Example:
+//     option (dynamo.composite_key) = {name: "PK", template: "USER#{id}", key: KEY_TYPE_HASH};
+//
+// (dynamo.table) - Table configuration (message option)
+//   Use TableConfig message to specify the table name, billing mode, TTL
+//   attribute and index settings. The plugin generates the table schema, and
+//   omits the TTL attribute when unset.
+//   Example:
+//     option (dynamo.table) = {
+//       name: "users"
+//       ttl_attribute: "expires_at"
+//       indexes: {name: "email-index", projection_type: PROJECTION_TYPE_KEYS_ONLY}
+//     };
 
 // Code generated by protoc-gen-go. DO NOT EDIT.
 // versions:
@@ -138,6 +165,173 @@ func (KeyType) EnumDescriptor() ([]byte, []int) {
 	return file_dynamo_annotations_proto_rawDescGZIP(), []int{0}
 }
 
+// SetType specifies the DynamoDB set type of a repeated field.
+type SetType int32
+
+const (
+	// Unspecified set type, the field is stored as a list.
+	SetType_SET_TYPE_UNSPECIFIED SetType = 0
+	// String set (SS), for repeated string fields.
+	SetType_SET_TYPE_STRING SetType = 1
+	// Number set (NS), for repeated number fields.
+	SetType_SET_TYPE_NUMBER SetType = 2
+	// Binary set (BS), for repeated bytes fields.
+	SetType_SET_TYPE_BINARY SetType = 3
+)
+
+// Enum value maps for SetType.
+var (
+	SetType_name = map[int32]string{
+		0: "SET_TYPE_UNSPECIFIED",
+		1: "SET_TYPE_STRING",
+		2: "SET_TYPE_NUMBER",
+		3: "SET_TYPE_BINARY",
+	}
+	SetType_value = map[string]int32{
+		"SET_TYPE_UNSPECIFIED": 0,
+		"SET_TYPE_STRING":      1,
+		"SET_TYPE_NUMBER":      2,
+		"SET_TYPE_BINARY":      3,
+	}
+)
+
+func (x SetType) Enum() *SetType {
+	p := new(SetType)
+	*p = x
+	return p
+}
+
+func (x SetType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (SetType) Descriptor() protoreflect.EnumDescriptor {
+	return file_dynamo_annotations_proto_enumTypes[1].Descriptor()
+}
+
+func (SetType) Type() protoreflect.EnumType {
+	return &file_dynamo_annotations_proto_enumTypes[1]
+}
+
+func (x SetType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use SetType.Descriptor instead.
+func (SetType) EnumDescriptor() ([]byte, []int) {
+	return file_dynamo_annotations_proto_rawDescGZIP(), []int{1}
+}
+
+// BillingMode specifies how reads and writes of a table are charged.
+type BillingMode int32
+
+const (
+	// Unspecified billing mode, same as BILLING_MODE_PAY_PER_REQUEST.
+	BillingMode_BILLING_MODE_UNSPECIFIED BillingMode = 0
+	// On-demand capacity.
+	BillingMode_BILLING_MODE_PAY_PER_REQUEST BillingMode = 1
+	// Provisioned capacity (see Throughput).
+	BillingMode_BILLING_MODE_PROVISIONED BillingMode = 2
+)
+
+// Enum value maps for BillingMode.
+var (
+	BillingMode_name = map[int32]string{
+		0: "BILLING_MODE_UNSPECIFIED",
+		1: "BILLING_MODE_PAY_PER_REQUEST",
+		2: "BILLING_MODE_PROVISIONED",
+	}
+	BillingMode_value = map[string]int32{
+		"BILLING_MODE_UNSPECIFIED":     0,
+		"BILLING_MODE_PAY_PER_REQUEST": 1,
+		"BILLING_MODE_PROVISIONED":     2,
+	}
+)
+
+func (x BillingMode) Enum() *BillingMode {
+	p := new(BillingMode)
+	*p = x
+	return p
+}
+
+func (x BillingMode) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (BillingMode) Descriptor() protoreflect.EnumDescriptor {
+	return file_dynamo_annotations_proto_enumTypes[2].Descriptor()
+}
+
+func (BillingMode) Type() protoreflect.EnumType {
+	return &file_dynamo_annotations_proto_enumTypes[2]
+}
+
+func (x BillingMode) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use BillingMode.Descriptor instead.
+func (BillingMode) EnumDescriptor() ([]byte, []int) {
+	return file_dynamo_annotations_proto_rawDescGZIP(), []int{2}
+}
+
+// ProjectionType specifies the attributes copied into an index.
+type ProjectionType int32
+
+const (
+	// Unspecified projection, same as PROJECTION_TYPE_ALL.
+	ProjectionType_PROJECTION_TYPE_UNSPECIFIED ProjectionType = 0
+	// All the attributes of the item.
+	ProjectionType_PROJECTION_TYPE_ALL ProjectionType = 1
+	// The table and index keys only.
+	ProjectionType_PROJECTION_TYPE_KEYS_ONLY ProjectionType = 2
+	// The keys and the non_key_attributes.
+	ProjectionType_PROJECTION_TYPE_INCLUDE ProjectionType = 3
+)
+
+// Enum value maps for ProjectionType.
+var (
+	ProjectionType_name = map[int32]string{
+		0: "PROJECTION_TYPE_UNSPECIFIED",
+		1: "PROJECTION_TYPE_ALL",
+		2: "PROJECTION_TYPE_KEYS_ONLY",
+		3: "PROJECTION_TYPE_INCLUDE",
+	}
+	ProjectionType_value = map[string]int32{
+		"PROJECTION_TYPE_UNSPECIFIED": 0,
+		"PROJECTION_TYPE_ALL":         1,
+		"PROJECTION_TYPE_KEYS_ONLY":   2,
+		"PROJECTION_TYPE_INCLUDE":     3,
+	}
+)
+
+func (x ProjectionType) Enum() *ProjectionType {
+	p := new(ProjectionType)
+	*p = x
+	return p
+}
+
+func (x ProjectionType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ProjectionType) Descriptor() protoreflect.EnumDescriptor {
+	return file_dynamo_annotations_proto_enumTypes[3].Descriptor()
+}
+
+func (ProjectionType) Type() protoreflect.EnumType {
+	return &file_dynamo_annotations_proto_enumTypes[3]
+}
+
+func (x ProjectionType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ProjectionType.Descriptor instead.
+func (ProjectionType) EnumDescriptor() ([]byte, []int) {
+	return file_dynamo_annotations_proto_rawDescGZIP(), []int{3}
+}
+
 // KeyConfig specifies the configuration for primary table keys.
 type KeyConfig struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
@@ -200,7 +394,16 @@ type IndexConfig struct {
 	// Index name (required).
 	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
 	// Key type in this index (HASH or RANGE).
-	Key           KeyType `protobuf:"varint,2,opt,name=key,proto3,enum=dynamo.KeyType" json:"key,omitempty"`
+	Key KeyType `protobuf:"varint,2,opt,name=key,proto3,enum=dynamo.KeyType" json:"key,omitempty"`
+	// Attributes copied into the index, declared once per index, here or in
+	// the indexes of (dynamo.table).
+	ProjectionType ProjectionType `protobuf:"varint,3,opt,name=projection_type,json=projectionType,proto3,enum=dynamo.ProjectionType" json:"projection_type,omitempty"`
+	// Proto field names of the attributes copied into the index with
+	// PROJECTION_TYPE_INCLUDE.
+	NonKeyAttributes []string `protobuf:"bytes,4,rep,name=non_key_attributes,json=nonKeyAttributes,proto3" json:"non_key_attributes,omitempty"`
+	// Sparse index: the field is omitted from items when it has its zero
+	// value, so that only items with a value are in the index.
+	Sparse        bool `protobuf:"varint,5,opt,name=sparse,proto3" json:"sparse,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -249,7 +452,415 @@ func (x *IndexConfig) GetKey() KeyType {
 	return KeyType_KEY_TYPE_UNSPECIFIED
 }
 
+func (x *IndexConfig) GetProjectionType() ProjectionType {
+	if x != nil {
+		return x.ProjectionType
+	}
+	return ProjectionType_PROJECTION_TYPE_UNSPECIFIED
+}
+
+func (x *IndexConfig) GetNonKeyAttributes() []string {
+	if x != nil {
+		return x.NonKeyAttributes
+	}
+	return nil
+}
+
+func (x *IndexConfig) GetSparse() bool {
+	if x != nil {
+		return x.Sparse
+	}
+	return false
+}
+
+// AttributeConfig specifies how a field is encoded as an item attribute.
+type AttributeConfig struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Omit the attribute when the field has its zero value.
+	Omitempty bool `protobuf:"varint,1,opt,name=omitempty,proto3" json:"omitempty,omitempty"`
+	// Store a repeated field as a set instead of a list.
+	Set SetType `protobuf:"varint,2,opt,name=set,proto3,enum=dynamo.SetType" json:"set,omitempty"`
+	// Store a google.protobuf.Timestamp field as Unix seconds (number).
+	// Requires marshal=true: struct tags store a Timestamp as a map.
+	Unixtime bool `protobuf:"varint,3,opt,name=unixtime,proto3" json:"unixtime,omitempty"`
+	// Use an integer field as the version of the item, for optimistic locking:
+	// generated writes succeed only if the stored version is the version of the
+	// message, and increment it. At most one per message.
+	Version       bool `protobuf:"varint,4,opt,name=version,proto3" json:"version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AttributeConfig) Reset() {
+	*x = AttributeConfig{}
+	mi := &file_dynamo_annotations_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AttributeConfig) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AttributeConfig) ProtoMessage() {}
+
+func (x *AttributeConfig) ProtoReflect() protoreflect.Message {
+	mi := &file_dynamo_annotations_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AttributeConfig.ProtoReflect.Descriptor instead.
+func (*AttributeConfig) Descriptor() ([]byte, []int) {
+	return file_dynamo_annotations_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *AttributeConfig) GetOmitempty() bool {
+	if x != nil {
+		return x.Omitempty
+	}
+	return false
+}
+
+func (x *AttributeConfig) GetSet() SetType {
+	if x != nil {
+		return x.Set
+	}
+	return SetType_SET_TYPE_UNSPECIFIED
+}
+
+func (x *AttributeConfig) GetUnixtime() bool {
+	if x != nil {
+		return x.Unixtime
+	}
+	return false
+}
+
+func (x *AttributeConfig) GetVersion() bool {
+	if x != nil {
+		return x.Version
+	}
+	return false
+}
+
+// CompositeKeyConfig specifies a key attribute computed from message fields,
+// for single-table designs.
+type CompositeKeyConfig struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Attribute name (required), e.g. "PK".
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// Template of the attribute value (required): literals and {field} proto
+	// field names of string, integer or enum fields, without optional or oneof.
+	// Integer fields may be zero-padded to a width so that non-negative values
+	// sort as strings: {created_at:20}. Negative values do not: their sign
+	// precedes the padding. Fields must be separated by literals so the
+	// attribute can be parsed back.
+	// Example: "ORDER#{created_at:20}#{id}"
+	Template string `protobuf:"bytes,2,opt,name=template,proto3" json:"template,omitempty"`
+	// Key type of the attribute (HASH or RANGE).
+	Key KeyType `protobuf:"varint,3,opt,name=key,proto3,enum=dynamo.KeyType" json:"key,omitempty"`
+	// Index of the key; empty for the table key.
+	Index         string `protobuf:"bytes,4,opt,name=index,proto3" json:"index,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CompositeKeyConfig) Reset() {
+	*x = CompositeKeyConfig{}
+	mi := &file_dynamo_annotations_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CompositeKeyConfig) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CompositeKeyConfig) ProtoMessage() {}
+
+func (x *CompositeKeyConfig) ProtoReflect() protoreflect.Message {
+	mi := &file_dynamo_annotations_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CompositeKeyConfig.ProtoReflect.Descriptor instead.
+func (*CompositeKeyConfig) Descriptor() ([]byte, []int) {
+	return file_dynamo_annotations_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *CompositeKeyConfig) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *CompositeKeyConfig) GetTemplate() string {
+	if x != nil {
+		return x.Template
+	}
+	return ""
+}
+
+func (x *CompositeKeyConfig) GetKey() KeyType {
+	if x != nil {
+		return x.Key
+	}
+	return KeyType_KEY_TYPE_UNSPECIFIED
+}
+
+func (x *CompositeKeyConfig) GetIndex() string {
+	if x != nil {
+		return x.Index
+	}
+	return ""
+}
+
+// Throughput specifies the provisioned capacity of a table or index.
+type Throughput struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Read capacity units.
+	ReadCapacityUnits int64 `protobuf:"varint,1,opt,name=read_capacity_units,json=readCapacityUnits,proto3" json:"read_capacity_units,omitempty"`
+	// Write capacity units.
+	WriteCapacityUnits int64 `protobuf:"varint,2,opt,name=write_capacity_units,json=writeCapacityUnits,proto3" json:"write_capacity_units,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *Throughput) Reset() {
+	*x = Throughput{}
+	mi := &file_dynamo_annotations_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Throughput) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Throughput) ProtoMessage() {}
+
+func (x *Throughput) ProtoReflect() protoreflect.Message {
+	mi := &file_dynamo_annotations_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Throughput.ProtoReflect.Descriptor instead.
+func (*Throughput) Descriptor() ([]byte, []int) {
+	return file_dynamo_annotations_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Throughput) GetReadCapacityUnits() int64 {
+	if x != nil {
+		return x.ReadCapacityUnits
+	}
+	return 0
+}
+
+func (x *Throughput) GetWriteCapacityUnits() int64 {
+	if x != nil {
+		return x.WriteCapacityUnits
+	}
+	return 0
+}
+
+// IndexSettings specifies the table-level settings of an index declared with
+// (dynamo.gsi) or (dynamo.lsi).
+type IndexSettings struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Index name (required).
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// Attributes copied into the index.
+	ProjectionType ProjectionType `protobuf:"varint,2,opt,name=projection_type,json=projectionType,proto3,enum=dynamo.ProjectionType" json:"projection_type,omitempty"`
+	// Proto field names of the attributes copied into the index with
+	// PROJECTION_TYPE_INCLUDE.
+	NonKeyAttributes []string `protobuf:"bytes,3,rep,name=non_key_attributes,json=nonKeyAttributes,proto3" json:"non_key_attributes,omitempty"`
+	// Provisioned capacity of a GSI. Defaults to the table throughput.
+	Throughput    *Throughput `protobuf:"bytes,4,opt,name=throughput,proto3" json:"throughput,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *IndexSettings) Reset() {
+	*x = IndexSettings{}
+	mi := &file_dynamo_annotations_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *IndexSettings) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IndexSettings) ProtoMessage() {}
+
+func (x *IndexSettings) ProtoReflect() protoreflect.Message {
+	mi := &file_dynamo_annotations_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IndexSettings.ProtoReflect.Descriptor instead.
+func (*IndexSettings) Descriptor() ([]byte, []int) {
+	return file_dynamo_annotations_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *IndexSettings) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *IndexSettings) GetProjectionType() ProjectionType {
+	if x != nil {
+		return x.ProjectionType
+	}
+	return ProjectionType_PROJECTION_TYPE_UNSPECIFIED
+}
+
+func (x *IndexSettings) GetNonKeyAttributes() []string {
+	if x != nil {
+		return x.NonKeyAttributes
+	}
+	return nil
+}
+
+func (x *IndexSettings) GetThroughput() *Throughput {
+	if x != nil {
+		return x.Throughput
+	}
+	return nil
+}
+
+// TableConfig specifies the configuration of the table of a message.
+type TableConfig struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Table name (required).
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// Billing mode of the table.
+	BillingMode BillingMode `protobuf:"varint,2,opt,name=billing_mode,json=billingMode,proto3,enum=dynamo.BillingMode" json:"billing_mode,omitempty"`
+	// Provisioned capacity, required with BILLING_MODE_PROVISIONED.
+	Throughput *Throughput `protobuf:"bytes,3,opt,name=throughput,proto3" json:"throughput,omitempty"`
+	// Proto field name of the attribute holding the expiration time, if any:
+	// an integer field (Unix seconds) or, with marshal=true, a
+	// google.protobuf.Timestamp field stored as Unix seconds. The attribute is
+	// omitted when unset.
+	TtlAttribute string `protobuf:"bytes,4,opt,name=ttl_attribute,json=ttlAttribute,proto3" json:"ttl_attribute,omitempty"`
+	// Settings of the indexes. Indexes without settings project all attributes.
+	Indexes       []*IndexSettings `protobuf:"bytes,5,rep,name=indexes,proto3" json:"indexes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TableConfig) Reset() {
+	*x = TableConfig{}
+	mi := &file_dynamo_annotations_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TableConfig) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TableConfig) ProtoMessage() {}
+
+func (x *TableConfig) ProtoReflect() protoreflect.Message {
+	mi := &file_dynamo_annotations_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TableConfig.ProtoReflect.Descriptor instead.
+func (*TableConfig) Descriptor() ([]byte, []int) {
+	return file_dynamo_annotations_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *TableConfig) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *TableConfig) GetBillingMode() BillingMode {
+	if x != nil {
+		return x.BillingMode
+	}
+	return BillingMode_BILLING_MODE_UNSPECIFIED
+}
+
+func (x *TableConfig) GetThroughput() *Throughput {
+	if x != nil {
+		return x.Throughput
+	}
+	return nil
+}
+
+func (x *TableConfig) GetTtlAttribute() string {
+	if x != nil {
+		return x.TtlAttribute
+	}
+	return ""
+}
+
+func (x *TableConfig) GetIndexes() []*IndexSettings {
+	if x != nil {
+		return x.Indexes
+	}
+	return nil
+}
+
 var file_dynamo_annotations_proto_extTypes = []protoimpl.ExtensionInfo{
+	{
+		ExtendedType:  (*descriptorpb.MessageOptions)(nil),
+		ExtensionType: (*TableConfig)(nil),
+		Field:         50003,
+		Name:          "dynamo.table",
+		Tag:           "bytes,50003,opt,name=table",
+		Filename:      "dynamo/annotations.proto",
+	},
+	{
+		ExtendedType:  (*descriptorpb.MessageOptions)(nil),
+		ExtensionType: ([]*CompositeKeyConfig)(nil),
+		Field:         50005,
+		Name:          "dynamo.composite_key",
+		Tag:           "bytes,50005,rep,name=composite_key",
+		Filename:      "dynamo/annotations.proto",
+	},
 	{
 		ExtendedType:  (*descriptorpb.FieldOptions)(nil),
 		ExtensionType: (*KeyConfig)(nil),
@@ -274,22 +885,46 @@ var file_dynamo_annotations_proto_extTypes = []protoimpl.ExtensionInfo{
 		Tag:           "bytes,50002,rep,name=lsi",
 		Filename:      "dynamo/annotations.proto",
 	},
+	{
+		ExtendedType:  (*descriptorpb.FieldOptions)(nil),
+		ExtensionType: (*AttributeConfig)(nil),
+		Field:         50004,
+		Name:          "dynamo.attribute",
+		Tag:           "bytes,50004,opt,name=attribute",
+		Filename:      "dynamo/annotations.proto",
+	},
 }
 
+// Extension fields to descriptorpb.MessageOptions.
+var (
+	// Table configuration annotation.
+	//
+	// optional dynamo.TableConfig table = 50003;
+	E_Table = &file_dynamo_annotations_proto_extTypes[0]
+	// Composite key annotations (repeatable).
+	//
+	// repeated dynamo.CompositeKeyConfig composite_key = 50005;
+	E_CompositeKey = &file_dynamo_annotations_proto_extTypes[1]
+)
+
 // Extension fields to descriptorpb.FieldOptions.
 var (
 	// Primary table key annotation.
 	//
 	// optional dynamo.KeyConfig key = 50000;
-	E_Key = &file_dynamo_annotations_proto_extTypes[0]
+	E_Key = &file_dynamo_annotations_proto_extTypes[2]
 	// Global Secondary Index annotations (repeatable).
 	//
 	// repeated dynamo.IndexConfig gsi = 50001;
-	E_Gsi = &file_dynamo_annotations_proto_extTypes[1]
+	E_Gsi = &file_dynamo_annotations_proto_extTypes[3]
 	// Local Secondary Index annotations (repeatable).
 	//
 	// repeated dynamo.IndexConfig lsi = 50002;
-	E_Lsi = &file_dynamo_annotations_proto_extTypes[2]
+	E_Lsi = &file_dynamo_annotations_proto_extTypes[4]
+	// Attribute encoding annotation.
+	//
+	// optional dynamo.AttributeConfig attribute = 50004;
+	E_Attribute = &file_dynamo_annotations_proto_extTypes[5]
 )
 
 var File_dynamo_annotations_proto protoreflect.FileDescriptor
@@ -300,17 +935,66 @@ const file_dynamo_annotations_proto_rawDesc = "" +
 	"\tKeyConfig\x12#\n" +
 	"\x04type\x18\x01 \x01(\x0e2\x0f.dynamo.KeyTypeR\x04type\x12\x1f\n" +
 	"\vcolumn_name\x18\x02 \x01(\tR\n" +
-	"columnName\"D\n" +
+	"columnName\"\xcb\x01\n" +
 	"\vIndexConfig\x12\x12\n" +
 	"\x04name\x18\x01 \x01(\tR\x04name\x12!\n" +
-	"\x03key\x18\x02 \x01(\x0e2\x0f.dynamo.KeyTypeR\x03key*J\n" +
+	"\x03key\x18\x02 \x01(\x0e2\x0f.dynamo.KeyTypeR\x03key\x12?\n" +
+	"\x0fprojection_type\x18\x03 \x01(\x0e2\x16.dynamo.ProjectionTypeR\x0eprojectionType\x12,\n" +
+	"\x12non_key_attributes\x18\x04 \x03(\tR\x10nonKeyAttributes\x12\x16\n" +
+	"\x06sparse\x18\x05 \x01(\bR\x06sparse\"\x88\x01\n" +
+	"\x0fAttributeConfig\x12\x1c\n" +
+	"\tomitempty\x18\x01 \x01(\bR\tomitempty\x12!\n" +
+	"\x03set\x18\x02 \x01(\x0e2\x0f.dynamo.SetTypeR\x03set\x12\x1a\n" +
+	"\bunixtime\x18\x03 \x01(\bR\bunixtime\x12\x18\n" +
+	"\aversion\x18\x04 \x01(\bR\aversion\"}\n" +
+	"\x12CompositeKeyConfig\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x1a\n" +
+	"\btemplate\x18\x02 \x01(\tR\btemplate\x12!\n" +
+	"\x03key\x18\x03 \x01(\x0e2\x0f.dynamo.KeyTypeR\x03key\x12\x14\n" +
+	"\x05index\x18\x04 \x01(\tR\x05index\"n\n" +
+	"\n" +
+	"Throughput\x12.\n" +
+	"\x13read_capacity_units\x18\x01 \x01(\x03R\x11readCapacityUnits\x120\n" +
+	"\x14write_capacity_units\x18\x02 \x01(\x03R\x12writeCapacityUnits\"\xc6\x01\n" +
+	"\rIndexSettings\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12?\n" +
+	"\x0fprojection_type\x18\x02 \x01(\x0e2\x16.dynamo.ProjectionTypeR\x0eprojectionType\x12,\n" +
+	"\x12non_key_attributes\x18\x03 \x03(\tR\x10nonKeyAttributes\x122\n" +
+	"\n" +
+	"throughput\x18\x04 \x01(\v2\x12.dynamo.ThroughputR\n" +
+	"throughput\"\xe3\x01\n" +
+	"\vTableConfig\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x126\n" +
+	"\fbilling_mode\x18\x02 \x01(\x0e2\x13.dynamo.BillingModeR\vbillingMode\x122\n" +
+	"\n" +
+	"throughput\x18\x03 \x01(\v2\x12.dynamo.ThroughputR\n" +
+	"throughput\x12#\n" +
+	"\rttl_attribute\x18\x04 \x01(\tR\fttlAttribute\x12/\n" +
+	"\aindexes\x18\x05 \x03(\v2\x15.dynamo.IndexSettingsR\aindexes*J\n" +
 	"\aKeyType\x12\x18\n" +
 	"\x14KEY_TYPE_UNSPECIFIED\x10\x00\x12\x11\n" +
 	"\rKEY_TYPE_HASH\x10\x01\x12\x12\n" +
-	"\x0eKEY_TYPE_RANGE\x10\x02:D\n" +
+	"\x0eKEY_TYPE_RANGE\x10\x02*b\n" +
+	"\aSetType\x12\x18\n" +
+	"\x14SET_TYPE_UNSPECIFIED\x10\x00\x12\x13\n" +
+	"\x0fSET_TYPE_STRING\x10\x01\x12\x13\n" +
+	"\x0fSET_TYPE_NUMBER\x10\x02\x12\x13\n" +
+	"\x0fSET_TYPE_BINARY\x10\x03*k\n" +
+	"\vBillingMode\x12\x1c\n" +
+	"\x18BILLING_MODE_UNSPECIFIED\x10\x00\x12 \n" +
+	"\x1cBILLING_MODE_PAY_PER_REQUEST\x10\x01\x12\x1c\n" +
+	"\x18BILLING_MODE_PROVISIONED\x10\x02*\x86\x01\n" +
+	"\x0eProjectionType\x12\x1f\n" +
+	"\x1bPROJECTION_TYPE_UNSPECIFIED\x10\x00\x12\x17\n" +
+	"\x13PROJECTION_TYPE_ALL\x10\x01\x12\x1d\n" +
+	"\x19PROJECTION_TYPE_KEYS_ONLY\x10\x02\x12\x1b\n" +
+	"\x17PROJECTION_TYPE_INCLUDE\x10\x03:L\n" +
+	"\x05table\x12\x1f.google.protobuf.MessageOptions\x18ӆ\x03 \x01(\v2\x13.dynamo.TableConfigR\x05table:b\n" +
+	"\rcomposite_key\x12\x1f.google.protobuf.MessageOptions\x18Ն\x03 \x03(\v2\x1a.dynamo.CompositeKeyConfigR\fcompositeKey:D\n" +
 	"\x03key\x12\x1d.google.protobuf.FieldOptions\x18І\x03 \x01(\v2\x11.dynamo.KeyConfigR\x03key:F\n" +
 	"\x03gsi\x12\x1d.google.protobuf.FieldOptions\x18ц\x03 \x03(\v2\x13.dynamo.IndexConfigR\x03gsi:F\n" +
-	"\x03lsi\x12\x1d.google.protobuf.FieldOptions\x18҆\x03 \x03(\v2\x13.dynamo.IndexConfigR\x03lsiB\x97\x01\n" +
+	"\x03lsi\x12\x1d.google.protobuf.FieldOptions\x18҆\x03 \x03(\v2\x13.dynamo.IndexConfigR\x03lsi:V\n" +
+	"\tattribute\x12\x1d.google.protobuf.FieldOptions\x18Ԇ\x03 \x01(\v2\x17.dynamo.AttributeConfigR\tattributeB\x97\x01\n" +
 	"\n" +
 	"com.dynamoB\x10AnnotationsProtoP\x01Z?buf.build/gen/go/frontier/public-apis/protocolbuffers/go/dynamo\xa2\x02\x03DXX\xaa\x02\x06Dynamo\xca\x02\x06Dynamo\xe2\x02\x12Dynamo\\GPBMetadata\xea\x02\x06Dynamob\x06proto3"
 
@@ -326,28 +1010,51 @@ func file_dynamo_annotations_proto_rawDescGZIP() []byte {
 	return file_dynamo_annotations_proto_rawDescData
 }
 
-var file_dynamo_annotations_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_dynamo_annotations_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_dynamo_annotations_proto_enumTypes = make([]protoimpl.EnumInfo, 4)
+var file_dynamo_annotations_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
 var file_dynamo_annotations_proto_goTypes = []any{
-	(KeyType)(0),                      // 0: dynamo.KeyType
-	(*KeyConfig)(nil),                 // 1: dynamo.KeyConfig
-	(*IndexConfig)(nil),               // 2: dynamo.IndexConfig
-	(*descriptorpb.FieldOptions)(nil), // 3: google.protobuf.FieldOptions
+	(KeyType)(0),                        // 0: dynamo.KeyType
+	(SetType)(0),                        // 1: dynamo.SetType
+	(BillingMode)(0),                    // 2: dynamo.BillingMode
+	(ProjectionType)(0),                 // 3: dynamo.ProjectionType
+	(*KeyConfig)(nil),                   // 4: dynamo.KeyConfig
+	(*IndexConfig)(nil),                 // 5: dynamo.IndexConfig
+	(*AttributeConfig)(nil),             // 6: dynamo.AttributeConfig
+	(*CompositeKeyConfig)(nil),          // 7: dynamo.CompositeKeyConfig
+	(*Throughput)(nil),                  // 8: dynamo.Throughput
+	(*IndexSettings)(nil),               // 9: dynamo.IndexSettings
+	(*TableConfig)(nil),                 // 10: dynamo.TableConfig
+	(*descriptorpb.MessageOptions)(nil), // 11: google.protobuf.MessageOptions
+	(*descriptorpb.FieldOptions)(nil),   // 12: google.protobuf.FieldOptions
 }
 var file_dynamo_annotations_proto_depIdxs = []int32{
-	0, // 0: dynamo.KeyConfig.type:type_name -> dynamo.KeyType
-	0, // 1: dynamo.IndexConfig.key:type_name -> dynamo.KeyType
-	3, // 2: dynamo.key:extendee -> google.protobuf.FieldOptions
-	3, // 3: dynamo.gsi:extendee -> google.protobuf.FieldOptions
-	3, // 4: dynamo.lsi:extendee -> google.protobuf.FieldOptions
-	1, // 5: dynamo.key:type_name -> dynamo.KeyConfig
-	2, // 6: dynamo.gsi:type_name -> dynamo.IndexConfig
-	2, // 7: dynamo.lsi:type_name -> dynamo.IndexConfig
-	8, // [8:8] is the sub-list for method output_type
-	8, // [8:8] is the sub-list for method input_type
-	5, // [5:8] is the sub-list for extension type_name
-	2, // [2:5] is the sub-list for extension extendee
-	0, // [0:2] is the sub-list for field type_name
+	0,  // 0: dynamo.KeyConfig.type:type_name -> dynamo.KeyType
+	0,  // 1: dynamo.IndexConfig.key:type_name -> dynamo.KeyType
+	3,  // 2: dynamo.IndexConfig.projection_type:type_name -> dynamo.ProjectionType
+	1,  // 3: dynamo.AttributeConfig.set:type_name -> dynamo.SetType
+	0,  // 4: dynamo.CompositeKeyConfig.key:type_name -> dynamo.KeyType
+	3,  // 5: dynamo.IndexSettings.projection_type:type_name -> dynamo.ProjectionType
+	8,  // 6: dynamo.IndexSettings.throughput:type_name -> dynamo.Throughput
+	2,  // 7: dynamo.TableConfig.billing_mode:type_name -> dynamo.BillingMode
+	8,  // 8: dynamo.TableConfig.throughput:type_name -> dynamo.Throughput
+	9,  // 9: dynamo.TableConfig.indexes:type_name -> dynamo.IndexSettings
+	11, // 10: dynamo.table:extendee -> google.protobuf.MessageOptions
+	11, // 11: dynamo.composite_key:extendee -> google.protobuf.MessageOptions
+	12, // 12: dynamo.key:extendee -> google.protobuf.FieldOptions
+	12, // 13: dynamo.gsi:extendee -> google.protobuf.FieldOptions
+	12, // 14: dynamo.lsi:extendee -> google.protobuf.FieldOptions
+	12, // 15: dynamo.attribute:extendee -> google.protobuf.FieldOptions
+	10, // 16: dynamo.table:type_name -> dynamo.TableConfig
+	7,  // 17: dynamo.composite_key:type_name -> dynamo.CompositeKeyConfig
+	4,  // 18: dynamo.key:type_name -> dynamo.KeyConfig
+	5,  // 19: dynamo.gsi:type_name -> dynamo.IndexConfig
+	5,  // 20: dynamo.lsi:type_name -> dynamo.IndexConfig
+	6,  // 21: dynamo.attribute:type_name -> dynamo.AttributeConfig
+	22, // [22:22] is the sub-list for method output_type
+	22, // [22:22] is the sub-list for method input_type
+	16, // [16:22] is the sub-list for extension type_name
+	10, // [10:16] is the sub-list for extension extendee
+	0,  // [0:10] is the sub-list for field type_name
 }
 
 func init() { file_dynamo_annotations_proto_init() }
@@ -360,9 +1067,9 @@ func file_dynamo_annotations_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_dynamo_annotations_proto_rawDesc), len(file_dynamo_annotations_proto_rawDesc)),
-			NumEnums:      1,
-			NumMessages:   2,
-			NumExtensions: 3,
+			NumEnums:      4,
+			NumMessages:   7,
+			NumExtensions: 6,
 			NumServices:   0,
 		},
 		GoTypes:           file_dynamo_annotations_proto_goTypes,
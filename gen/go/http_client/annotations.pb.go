@@ -11,6 +11,7 @@ import (
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
 	descriptorpb "google.golang.org/protobuf/types/descriptorpb"
 	reflect "reflect"
+	sync "sync"
 	unsafe "unsafe"
 )
 
@@ -21,7 +22,333 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+// RetryPolicy overrides the client retry policy for a single method.
+// Zero values inherit the policy configured on the generated client.
+type RetryPolicy struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Maximum number of attempts, including the first one.
+	MaxAttempts uint32 `protobuf:"varint,1,opt,name=max_attempts,json=maxAttempts,proto3" json:"max_attempts,omitempty"`
+	// Backoff before the first retry, in milliseconds.
+	InitialBackoffMs uint32 `protobuf:"varint,2,opt,name=initial_backoff_ms,json=initialBackoffMs,proto3" json:"initial_backoff_ms,omitempty"`
+	// Upper bound for a single backoff, in milliseconds.
+	MaxBackoffMs uint32 `protobuf:"varint,3,opt,name=max_backoff_ms,json=maxBackoffMs,proto3" json:"max_backoff_ms,omitempty"`
+	// Factor applied to the backoff after every attempt.
+	BackoffMultiplier float64 `protobuf:"fixed64,4,opt,name=backoff_multiplier,json=backoffMultiplier,proto3" json:"backoff_multiplier,omitempty"`
+	// HTTP status codes that trigger a retry. Defaults to 429 and 5xx but 501.
+	RetryableStatusCodes []uint32 `protobuf:"varint,5,rep,packed,name=retryable_status_codes,json=retryableStatusCodes,proto3" json:"retryable_status_codes,omitempty"`
+	// Allows retrying a method whose HTTP verb is not idempotent (POST, PATCH).
+	Idempotent bool `protobuf:"varint,6,opt,name=idempotent,proto3" json:"idempotent,omitempty"`
+	// Sends a generated idempotency key with every call, which makes the
+	// method retryable regardless of its HTTP verb.
+	IdempotencyKey bool `protobuf:"varint,7,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"`
+	// Disables retries for this method regardless of the client policy.
+	Disabled      bool `protobuf:"varint,8,opt,name=disabled,proto3" json:"disabled,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RetryPolicy) Reset() {
+	*x = RetryPolicy{}
+	mi := &file_http_client_annotations_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RetryPolicy) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RetryPolicy) ProtoMessage() {}
+
+func (x *RetryPolicy) ProtoReflect() protoreflect.Message {
+	mi := &file_http_client_annotations_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RetryPolicy.ProtoReflect.Descriptor instead.
+func (*RetryPolicy) Descriptor() ([]byte, []int) {
+	return file_http_client_annotations_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *RetryPolicy) GetMaxAttempts() uint32 {
+	if x != nil {
+		return x.MaxAttempts
+	}
+	return 0
+}
+
+func (x *RetryPolicy) GetInitialBackoffMs() uint32 {
+	if x != nil {
+		return x.InitialBackoffMs
+	}
+	return 0
+}
+
+func (x *RetryPolicy) GetMaxBackoffMs() uint32 {
+	if x != nil {
+		return x.MaxBackoffMs
+	}
+	return 0
+}
+
+func (x *RetryPolicy) GetBackoffMultiplier() float64 {
+	if x != nil {
+		return x.BackoffMultiplier
+	}
+	return 0
+}
+
+func (x *RetryPolicy) GetRetryableStatusCodes() []uint32 {
+	if x != nil {
+		return x.RetryableStatusCodes
+	}
+	return nil
+}
+
+func (x *RetryPolicy) GetIdempotent() bool {
+	if x != nil {
+		return x.Idempotent
+	}
+	return false
+}
+
+func (x *RetryPolicy) GetIdempotencyKey() bool {
+	if x != nil {
+		return x.IdempotencyKey
+	}
+	return false
+}
+
+func (x *RetryPolicy) GetDisabled() bool {
+	if x != nil {
+		return x.Disabled
+	}
+	return false
+}
+
+// ClientOptions declares a generated client rooted at the file's proto package,
+// so a single plugin run can generate several clients.
+type ClientOptions struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Name of the root client. Defaults to the last package segment with a
+	// "Client" suffix (e.g., "IniciadorClient" for vendors.iniciador).
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// Output directory of the client, relative to the plugin output directory
+	// (e.g., "vendors/iniciador/httpclient/client").
+	OutputDir     string `protobuf:"bytes,2,opt,name=output_dir,json=outputDir,proto3" json:"output_dir,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ClientOptions) Reset() {
+	*x = ClientOptions{}
+	mi := &file_http_client_annotations_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ClientOptions) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClientOptions) ProtoMessage() {}
+
+func (x *ClientOptions) ProtoReflect() protoreflect.Message {
+	mi := &file_http_client_annotations_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ClientOptions.ProtoReflect.Descriptor instead.
+func (*ClientOptions) Descriptor() ([]byte, []int) {
+	return file_http_client_annotations_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ClientOptions) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ClientOptions) GetOutputDir() string {
+	if x != nil {
+		return x.OutputDir
+	}
+	return ""
+}
+
+// GroupNaming overrides the generated names of the service group of the
+// file's proto package. Unset fields keep the derived names.
+type GroupNaming struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Accessor name on the parent, without the "Get" prefix (e.g., "OpenFinance"
+	// for GetOpenFinance).
+	Accessor string `protobuf:"bytes,1,opt,name=accessor,proto3" json:"accessor,omitempty"`
+	// Interface name of the group; the implementation gets the "Impl" suffix
+	// (e.g., "OpenFinanceClient").
+	TypeName string `protobuf:"bytes,2,opt,name=type_name,json=typeName,proto3" json:"type_name,omitempty"`
+	// File name, without the ".gen.go" suffix (e.g., "open_finance").
+	FileName      string `protobuf:"bytes,3,opt,name=file_name,json=fileName,proto3" json:"file_name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GroupNaming) Reset() {
+	*x = GroupNaming{}
+	mi := &file_http_client_annotations_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GroupNaming) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GroupNaming) ProtoMessage() {}
+
+func (x *GroupNaming) ProtoReflect() protoreflect.Message {
+	mi := &file_http_client_annotations_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GroupNaming.ProtoReflect.Descriptor instead.
+func (*GroupNaming) Descriptor() ([]byte, []int) {
+	return file_http_client_annotations_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *GroupNaming) GetAccessor() string {
+	if x != nil {
+		return x.Accessor
+	}
+	return ""
+}
+
+func (x *GroupNaming) GetTypeName() string {
+	if x != nil {
+		return x.TypeName
+	}
+	return ""
+}
+
+func (x *GroupNaming) GetFileName() string {
+	if x != nil {
+		return x.FileName
+	}
+	return ""
+}
+
+// ServiceNaming overrides the generated names of a service. Unset fields keep
+// the derived names.
+type ServiceNaming struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Accessor name on the parent, without the "Get" prefix (e.g., "Accounts"
+	// for GetAccounts).
+	Accessor string `protobuf:"bytes,1,opt,name=accessor,proto3" json:"accessor,omitempty"`
+	// File name of a top-level service, without the ".gen.go" suffix
+	// (e.g., "accounts").
+	FileName      string `protobuf:"bytes,2,opt,name=file_name,json=fileName,proto3" json:"file_name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ServiceNaming) Reset() {
+	*x = ServiceNaming{}
+	mi := &file_http_client_annotations_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ServiceNaming) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ServiceNaming) ProtoMessage() {}
+
+func (x *ServiceNaming) ProtoReflect() protoreflect.Message {
+	mi := &file_http_client_annotations_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ServiceNaming.ProtoReflect.Descriptor instead.
+func (*ServiceNaming) Descriptor() ([]byte, []int) {
+	return file_http_client_annotations_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ServiceNaming) GetAccessor() string {
+	if x != nil {
+		return x.Accessor
+	}
+	return ""
+}
+
+func (x *ServiceNaming) GetFileName() string {
+	if x != nil {
+		return x.FileName
+	}
+	return ""
+}
+
 var file_http_client_annotations_proto_extTypes = []protoimpl.ExtensionInfo{
+	{
+		ExtendedType:  (*descriptorpb.FileOptions)(nil),
+		ExtensionType: (*ClientOptions)(nil),
+		Field:         50011,
+		Name:          "http_client.client",
+		Tag:           "bytes,50011,opt,name=client",
+		Filename:      "http_client/annotations.proto",
+	},
+	{
+		ExtendedType:  (*descriptorpb.FileOptions)(nil),
+		ExtensionType: (*GroupNaming)(nil),
+		Field:         50012,
+		Name:          "http_client.group",
+		Tag:           "bytes,50012,opt,name=group",
+		Filename:      "http_client/annotations.proto",
+	},
+	{
+		ExtendedType:  (*descriptorpb.FileOptions)(nil),
+		ExtensionType: (*string)(nil),
+		Field:         50015,
+		Name:          "http_client.default_unwrap_response",
+		Tag:           "bytes,50015,opt,name=default_unwrap_response",
+		Filename:      "http_client/annotations.proto",
+	},
+	{
+		ExtendedType:  (*descriptorpb.ServiceOptions)(nil),
+		ExtensionType: (*ServiceNaming)(nil),
+		Field:         50013,
+		Name:          "http_client.service",
+		Tag:           "bytes,50013,opt,name=service",
+		Filename:      "http_client/annotations.proto",
+	},
 	{
 		ExtendedType:  (*descriptorpb.MethodOptions)(nil),
 		ExtensionType: (*string)(nil),
@@ -30,35 +357,136 @@ var file_http_client_annotations_proto_extTypes = []protoimpl.ExtensionInfo{
 		Tag:           "bytes,50009,opt,name=wrap_response_into",
 		Filename:      "http_client/annotations.proto",
 	},
+	{
+		ExtendedType:  (*descriptorpb.MethodOptions)(nil),
+		ExtensionType: (*RetryPolicy)(nil),
+		Field:         50010,
+		Name:          "http_client.retry",
+		Tag:           "bytes,50010,opt,name=retry",
+		Filename:      "http_client/annotations.proto",
+	},
+	{
+		ExtendedType:  (*descriptorpb.MethodOptions)(nil),
+		ExtensionType: (*string)(nil),
+		Field:         50014,
+		Name:          "http_client.unwrap_response",
+		Tag:           "bytes,50014,opt,name=unwrap_response",
+		Filename:      "http_client/annotations.proto",
+	},
 }
 
+// Extension fields to descriptorpb.FileOptions.
+var (
+	// optional http_client.ClientOptions client = 50011;
+	E_Client = &file_http_client_annotations_proto_extTypes[0]
+	// optional http_client.GroupNaming group = 50012;
+	E_Group = &file_http_client_annotations_proto_extTypes[1]
+	// Default unwrap_response of the methods of the file.
+	//
+	// optional string default_unwrap_response = 50015;
+	E_DefaultUnwrapResponse = &file_http_client_annotations_proto_extTypes[2]
+)
+
+// Extension fields to descriptorpb.ServiceOptions.
+var (
+	// optional http_client.ServiceNaming service = 50013;
+	E_Service = &file_http_client_annotations_proto_extTypes[3]
+)
+
 // Extension fields to descriptorpb.MethodOptions.
 var (
 	// optional string wrap_response_into = 50009;
-	E_WrapResponseInto = &file_http_client_annotations_proto_extTypes[0]
+	E_WrapResponseInto = &file_http_client_annotations_proto_extTypes[4]
+	// optional http_client.RetryPolicy retry = 50010;
+	E_Retry = &file_http_client_annotations_proto_extTypes[5]
+	// JSON pointer (RFC 6901) of the response message inside the response
+	// envelope, extracted before unmarshaling (e.g., "/data" for
+	// {"data": {...}, "meta": {...}}). The inverse of wrap_response_into,
+	// applied first when both are set. An explicit "" disables the file's
+	// default_unwrap_response.
+	//
+	// optional string unwrap_response = 50014;
+	E_UnwrapResponse = &file_http_client_annotations_proto_extTypes[6]
 )
 
 var File_http_client_annotations_proto protoreflect.FileDescriptor
 
 const file_http_client_annotations_proto_rawDesc = "" +
 	"\n" +
-	"\x1dhttp_client/annotations.proto\x12\vhttp_client\x1a google/protobuf/descriptor.proto:N\n" +
-	"\x12wrap_response_into\x12\x1e.google.protobuf.MethodOptions\x18ن\x03 \x01(\tR\x10wrapResponseIntoB\xb1\x01\n" +
+	"\x1dhttp_client/annotations.proto\x12\vhttp_client\x1a google/protobuf/descriptor.proto\"\xce\x02\n" +
+	"\vRetryPolicy\x12!\n" +
+	"\fmax_attempts\x18\x01 \x01(\rR\vmaxAttempts\x12,\n" +
+	"\x12initial_backoff_ms\x18\x02 \x01(\rR\x10initialBackoffMs\x12$\n" +
+	"\x0emax_backoff_ms\x18\x03 \x01(\rR\fmaxBackoffMs\x12-\n" +
+	"\x12backoff_multiplier\x18\x04 \x01(\x01R\x11backoffMultiplier\x124\n" +
+	"\x16retryable_status_codes\x18\x05 \x03(\rR\x14retryableStatusCodes\x12\x1e\n" +
+	"\n" +
+	"idempotent\x18\x06 \x01(\bR\n" +
+	"idempotent\x12'\n" +
+	"\x0fidempotency_key\x18\a \x01(\bR\x0eidempotencyKey\x12\x1a\n" +
+	"\bdisabled\x18\b \x01(\bR\bdisabled\"B\n" +
+	"\rClientOptions\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x1d\n" +
+	"\n" +
+	"output_dir\x18\x02 \x01(\tR\toutputDir\"c\n" +
+	"\vGroupNaming\x12\x1a\n" +
+	"\baccessor\x18\x01 \x01(\tR\baccessor\x12\x1b\n" +
+	"\ttype_name\x18\x02 \x01(\tR\btypeName\x12\x1b\n" +
+	"\tfile_name\x18\x03 \x01(\tR\bfileName\"H\n" +
+	"\rServiceNaming\x12\x1a\n" +
+	"\baccessor\x18\x01 \x01(\tR\baccessor\x12\x1b\n" +
+	"\tfile_name\x18\x02 \x01(\tR\bfileName:R\n" +
+	"\x06client\x12\x1c.google.protobuf.FileOptions\x18ۆ\x03 \x01(\v2\x1a.http_client.ClientOptionsR\x06client:N\n" +
+	"\x05group\x12\x1c.google.protobuf.FileOptions\x18܆\x03 \x01(\v2\x18.http_client.GroupNamingR\x05group:V\n" +
+	"\x17default_unwrap_response\x12\x1c.google.protobuf.FileOptions\x18߆\x03 \x01(\tR\x15defaultUnwrapResponse:W\n" +
+	"\aservice\x12\x1f.google.protobuf.ServiceOptions\x18݆\x03 \x01(\v2\x1a.http_client.ServiceNamingR\aservice:N\n" +
+	"\x12wrap_response_into\x12\x1e.google.protobuf.MethodOptions\x18ن\x03 \x01(\tR\x10wrapResponseInto:P\n" +
+	"\x05retry\x12\x1e.google.protobuf.MethodOptions\x18چ\x03 \x01(\v2\x18.http_client.RetryPolicyR\x05retry:I\n" +
+	"\x0funwrap_response\x12\x1e.google.protobuf.MethodOptions\x18ކ\x03 \x01(\tR\x0eunwrapResponseB\xb1\x01\n" +
 	"\x0fcom.http_clientB\x10AnnotationsProtoP\x01ZDbuf.build/gen/go/frontier/public-apis/protocolbuffers/go/http_client\xa2\x02\x03HXX\xaa\x02\n" +
 	"HttpClient\xca\x02\n" +
 	"HttpClient\xe2\x02\x16HttpClient\\GPBMetadata\xea\x02\n" +
 	"HttpClientb\x06proto3"
 
+var (
+	file_http_client_annotations_proto_rawDescOnce sync.Once
+	file_http_client_annotations_proto_rawDescData []byte
+)
+
+func file_http_client_annotations_proto_rawDescGZIP() []byte {
+	file_http_client_annotations_proto_rawDescOnce.Do(func() {
+		file_http_client_annotations_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_http_client_annotations_proto_rawDesc), len(file_http_client_annotations_proto_rawDesc)))
+	})
+	return file_http_client_annotations_proto_rawDescData
+}
+
+var file_http_client_annotations_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
 var file_http_client_annotations_proto_goTypes = []any{
-	(*descriptorpb.MethodOptions)(nil), // 0: google.protobuf.MethodOptions
+	(*RetryPolicy)(nil),                 // 0: http_client.RetryPolicy
+	(*ClientOptions)(nil),               // 1: http_client.ClientOptions
+	(*GroupNaming)(nil),                 // 2: http_client.GroupNaming
+	(*ServiceNaming)(nil),               // 3: http_client.ServiceNaming
+	(*descriptorpb.FileOptions)(nil),    // 4: google.protobuf.FileOptions
+	(*descriptorpb.ServiceOptions)(nil), // 5: google.protobuf.ServiceOptions
+	(*descriptorpb.MethodOptions)(nil),  // 6: google.protobuf.MethodOptions
 }
 var file_http_client_annotations_proto_depIdxs = []int32{
-	0, // 0: http_client.wrap_response_into:extendee -> google.protobuf.MethodOptions
-	1, // [1:1] is the sub-list for method output_type
-	1, // [1:1] is the sub-list for method input_type
-	1, // [1:1] is the sub-list for extension type_name
-	0, // [0:1] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	4,  // 0: http_client.client:extendee -> google.protobuf.FileOptions
+	4,  // 1: http_client.group:extendee -> google.protobuf.FileOptions
+	4,  // 2: http_client.default_unwrap_response:extendee -> google.protobuf.FileOptions
+	5,  // 3: http_client.service:extendee -> google.protobuf.ServiceOptions
+	6,  // 4: http_client.wrap_response_into:extendee -> google.protobuf.MethodOptions
+	6,  // 5: http_client.retry:extendee -> google.protobuf.MethodOptions
+	6,  // 6: http_client.unwrap_response:extendee -> google.protobuf.MethodOptions
+	1,  // 7: http_client.client:type_name -> http_client.ClientOptions
+	2,  // 8: http_client.group:type_name -> http_client.GroupNaming
+	3,  // 9: http_client.service:type_name -> http_client.ServiceNaming
+	0,  // 10: http_client.retry:type_name -> http_client.RetryPolicy
+	11, // [11:11] is the sub-list for method output_type
+	11, // [11:11] is the sub-list for method input_type
+	7,  // [7:11] is the sub-list for extension type_name
+	0,  // [0:7] is the sub-list for extension extendee
+	0,  // [0:0] is the sub-list for field type_name
 }
 
 func init() { file_http_client_annotations_proto_init() }
@@ -72,12 +500,13 @@ func file_http_client_annotations_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_http_client_annotations_proto_rawDesc), len(file_http_client_annotations_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   0,
-			NumExtensions: 1,
+			NumMessages:   4,
+			NumExtensions: 7,
 			NumServices:   0,
 		},
 		GoTypes:           file_http_client_annotations_proto_goTypes,
 		DependencyIndexes: file_http_client_annotations_proto_depIdxs,
+		MessageInfos:      file_http_client_annotations_proto_msgTypes,
 		ExtensionInfos:    file_http_client_annotations_proto_extTypes,
 	}.Build()
 	File_http_client_annotations_proto = out.File
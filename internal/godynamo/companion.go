@@ -0,0 +1,230 @@
+package godynamo
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"text/template"
+
+	pgs "github.com/lyft/protoc-gen-star/v2"
+	pgsgo "github.com/lyft/protoc-gen-star/v2/lang/go"
+)
+
+// CompanionMessage is an annotated message with a tagged accessor type.
+type CompanionMessage struct {
+	Name   string           // Go type name (e.g., "User")
+	Table  *Table           // Key metadata (nil without (dynamo.key) hash key)
+	Fields []CompanionField // All fields, in declaration order
+}
+
+// CompanionField is a field of a tagged accessor type.
+type CompanionField struct {
+	GoName       string // Go field name (e.g., "CreatedAt")
+	GoType       string // Go field type, a pointer for oneof members (e.g., "*string")
+	Tag          string // Dynamo tags, without backquotes ("" if none)
+	Oneof        string // Go name of the oneof of the field ("" if none)
+	OneofWrapper string // Go type of the oneof wrapper (e.g., "User_Email")
+	Message      bool   // Message field, already a pointer
+	Composite    bool   // (dynamo.composite_key) attribute, computed by Dynamo<GoName>
+}
+
+// CompanionImport is a package imported by the types of the accessor fields.
+type CompanionImport struct {
+	Alias string
+	Path  string
+}
+
+// CompanionData is the data of the companion template of a proto file.
+type CompanionData struct {
+	Source   string
+	Package  string
+	Imports  []CompanionImport
+	Messages []*CompanionMessage
+}
+
+// extractCompanion returns the companion data of the annotated messages of a
+// file, with the tags of the given dialects.
+func extractCompanion(ctx pgsgo.Context, f pgs.File, dialects []TagDialect) (*CompanionData, error) {
+	tables, err := extractTables(ctx, f)
+	if err != nil {
+		return nil, err
+	}
+	tablesByName := map[string]*Table{}
+	for _, table := range tables {
+		tablesByName[table.Name] = table
+	}
+
+	data := &CompanionData{
+		Source:  f.InputPath().String(),
+		Package: ctx.PackageName(f).String(),
+	}
+	imports := map[string]string{}
+
+	for _, msg := range f.AllMessages() {
+		annotated, err := isAnnotated(msg)
+		if err != nil {
+			return nil, err
+		}
+		if !annotated {
+			continue
+		}
+
+		name := ctx.Name(msg).String()
+		companion := &CompanionMessage{Name: name, Table: tablesByName[name]}
+		for _, fd := range msg.Fields() {
+			field := CompanionField{
+				GoName:  ctx.Name(fd).String(),
+				GoType:  ctx.Type(fd).String(),
+				Tag:     buildTagsFromField(fd, dialects),
+				Message: fd.Type().IsEmbed(),
+			}
+			if fd.InRealOneOf() {
+				field.Oneof = ctx.Name(fd.OneOf()).String()
+				field.OneofWrapper = ctx.OneofOption(fd).String()
+			}
+			for _, e := range fieldTypeEntities(fd) {
+				if ctx.ImportPath(e) != ctx.ImportPath(f) {
+					imports[ctx.ImportPath(e).String()] = ctx.PackageName(e).String()
+				}
+			}
+			companion.Fields = append(companion.Fields, field)
+		}
+
+		composites, err := getCompositeKeys(msg)
+		if err != nil {
+			return nil, err
+		}
+		for _, cfg := range composites {
+			companion.Fields = append(companion.Fields, CompanionField{
+				GoName:    goIdentifier(cfg.GetName()),
+				GoType:    "string",
+				Tag:       buildCompositeTag(cfg.GetName(), dialects),
+				Composite: true,
+			})
+		}
+		data.Messages = append(data.Messages, companion)
+	}
+
+	for path, alias := range imports {
+		data.Imports = append(data.Imports, CompanionImport{Alias: alias, Path: path})
+	}
+	sort.Slice(data.Imports, func(i, j int) bool { return data.Imports[i].Path < data.Imports[j].Path })
+	return data, nil
+}
+
+// buildCompositeTag returns the tags of a composite key attribute.
+// Example: `dynamo:"PK" dynamodbav:"PK"`
+func buildCompositeTag(name string, dialects []TagDialect) string {
+	var tags []string
+	for _, dialect := range dialects {
+		switch dialect {
+		case DialectGuregu:
+			tags = append(tags, fmt.Sprintf("dynamo:%q", name))
+		case DialectAWSV2:
+			tags = append(tags, fmt.Sprintf("dynamodbav:%q", name))
+		}
+	}
+	return strings.Join(tags, " ")
+}
+
+// fieldTypeEntities returns the messages and enums of the Go type of a field.
+func fieldTypeEntities(f pgs.Field) []pgs.Entity {
+	var entities []pgs.Entity
+	add := func(embed pgs.Message, enum pgs.Enum) {
+		if embed != nil {
+			entities = append(entities, embed)
+		}
+		if enum != nil {
+			entities = append(entities, enum)
+		}
+	}
+
+	ft := f.Type()
+	if ft.IsRepeated() || ft.IsMap() {
+		add(ft.Element().Embed(), ft.Element().Enum())
+	} else {
+		add(ft.Embed(), ft.Enum())
+	}
+	return entities
+}
+
+// companionFileName returns the name of the companion file generated next to
+// a .pb.go file.
+// Example: "users/v1/users.pb.go" -> "users/v1/users_dynamo.pb.go"
+func companionFileName(pbFile string) string {
+	return strings.TrimSuffix(pbFile, ".pb.go") + "_dynamo.pb.go"
+}
+
+// companionTemplate generates the dynamo metadata of a file without rewriting
+// the protoc-gen-go output (companion=true). For each annotated message:
+//   - <Msg>DynamoHashKey, <Msg>DynamoRangeKey and <Msg>DynamoIndex<Index>
+//     constants, for messages with a (dynamo.key) hash key
+//   - <Msg>DynamoItem, a copy of the message struct with the dynamo tags, for
+//     tag-driven marshalers, converted with (*<Msg>).DynamoItem and
+//     (*<Msg>DynamoItem).Proto. It also holds the (dynamo.composite_key)
+//     attributes, set by DynamoItem and ignored by Proto
+//
+// Example:
+//
+//	err := table.Put(user.DynamoItem()).Run(ctx)
+var companionTemplate = template.Must(template.New("companion").Parse(`// Code generated by protoc-gen-go-dynamo. DO NOT EDIT.
+// source: {{.Source}}
+
+package {{.Package}}
+{{if .Imports}}
+import (
+{{range .Imports}}	{{.Alias}} "{{.Path}}"
+{{end}})
+{{end}}
+{{- range .Messages}}{{$m := .}}
+{{with .Table}}
+// Key attributes and indexes of {{$m.Name}} items.
+const (
+	{{$m.Name}}DynamoHashKey = {{printf "%q" .HashKey.Name}}
+{{- if .RangeKey}}
+	{{$m.Name}}DynamoRangeKey = {{printf "%q" .RangeKey.Name}}
+{{- end}}
+{{- range .Indexes}}
+	{{$m.Name}}DynamoIndex{{.GoName}} = {{printf "%q" .Name}}
+{{- end}}
+)
+{{end}}
+// {{.Name}}DynamoItem is {{.Name}} with DynamoDB struct tags, for tag-driven
+// marshalers. Oneof members are pointers, nil unless set.
+type {{.Name}}DynamoItem struct {
+{{range .Fields}}	{{.GoName}} {{.GoType}}{{if .Tag}} ` + "`{{.Tag}}`" + `{{end}}
+{{end}}}
+
+// DynamoItem returns the tagged item of x.
+func (x *{{.Name}}) DynamoItem() *{{.Name}}DynamoItem {
+	if x == nil {
+		return nil
+	}
+	item := &{{.Name}}DynamoItem{
+{{range .Fields}}{{if .Composite}}		{{.GoName}}: x.Dynamo{{.GoName}}(),
+{{else if not .Oneof}}		{{.GoName}}: x.{{.GoName}},
+{{end}}{{end}}	}
+{{- range .Fields}}{{if .Oneof}}
+	if v, ok := x.{{.Oneof}}.(*{{.OneofWrapper}}); ok {
+		item.{{.GoName}} = {{if not .Message}}&{{end}}v.{{.GoName}}
+	}
+{{- end}}{{end}}
+	return item
+}
+
+// Proto returns the {{.Name}} of the tagged item.
+func (i *{{.Name}}DynamoItem) Proto() *{{.Name}} {
+	if i == nil {
+		return nil
+	}
+	x := &{{.Name}}{
+{{range .Fields}}{{if not (or .Oneof .Composite)}}		{{.GoName}}: i.{{.GoName}},
+{{end}}{{end}}	}
+{{- range .Fields}}{{if .Oneof}}
+	if i.{{.GoName}} != nil {
+		x.{{.Oneof}} = &{{.OneofWrapper}}{ {{- .GoName}}: {{if not .Message}}*{{end}}i.{{.GoName -}} }
+	}
+{{- end}}{{end}}
+	return x
+}
+{{end}}`))
@@ -0,0 +1,54 @@
+package godynamo
+
+import (
+	"fmt"
+	"strings"
+
+	pgs "github.com/lyft/protoc-gen-star/v2"
+)
+
+// Diagnostic is an invalid dynamo annotation, located in its proto file.
+// Example: "store/users.proto:12:3: store.User.email: GSI with an empty name"
+type Diagnostic struct {
+	File    string // Proto file path
+	Line    int    // 1-based line, 0 if unknown
+	Column  int    // 1-based column, 0 if unknown
+	Entity  string // Fully qualified name of the message or field
+	Message string
+}
+
+func (d Diagnostic) String() string {
+	loc := d.File
+	if d.Line > 0 {
+		loc = fmt.Sprintf("%s:%d:%d", d.File, d.Line, d.Column)
+	}
+	return fmt.Sprintf("%s: %s: %s", loc, strings.TrimPrefix(d.Entity, "."), d.Message)
+}
+
+// Diagnostics is the list of diagnostics of a file. It is an error when not
+// empty, failing generation.
+type Diagnostics []Diagnostic
+
+func (d Diagnostics) Error() string {
+	lines := make([]string, len(d))
+	for i, diag := range d {
+		lines[i] = diag.String()
+	}
+	return fmt.Sprintf("invalid dynamo annotations:\n%s", strings.Join(lines, "\n"))
+}
+
+// newDiagnostic returns a diagnostic located at the declaration of e.
+func newDiagnostic(e pgs.Entity, format string, args ...any) Diagnostic {
+	d := Diagnostic{
+		File:    e.File().InputPath().String(),
+		Entity:  e.FullyQualifiedName(),
+		Message: fmt.Sprintf(format, args...),
+	}
+	// Spans are 0-based [line, column, ...], absent without source info
+	if info := e.SourceCodeInfo(); info != nil && info.Location() != nil {
+		if span := info.Location().GetSpan(); len(span) >= 2 {
+			d.Line, d.Column = int(span[0])+1, int(span[1])+1
+		}
+	}
+	return d
+}
@@ -5,13 +5,32 @@
 // 2. Reading the generated .pb.go files
 // 3. Modifying the Go AST to inject struct tags
 // 4. Writing the updated files back
+//
+// With companion=true, generation is hermetic: instead of rewriting the
+// protoc-gen-go output, read from outdir, the tags and key metadata are
+// generated in a companion <file>_dynamo.pb.go file.
+//
+// The tags parameter selects the tag dialects ("guregu" by default, "awsv2",
+// or both as "guregu;awsv2", see TagDialect).
+//
+// With repository=true, it also generates a typed repository per message with
+// a (dynamo.key) hash key (see repositoryTemplate). With marshal=true, it
+// generates the item marshalers of the annotated messages (see itemTemplate).
+// With expr=true, it generates their typed expression attributes (see
+// exprTemplate).
+// Messages with a (dynamo.table) option get their table definition:
+// CreateTable inputs (see schemaTemplate) and CloudFormation or Terraform JSON
+// (schema parameter). Messages with (dynamo.composite_key) options get their
+// key functions and key conditions (see keysTemplate).
 package godynamo
 
 import (
+	"fmt"
 	"go/parser"
 	"go/printer"
 	"go/token"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	pgs "github.com/lyft/protoc-gen-star/v2"
@@ -42,12 +61,53 @@ func (mod) Name() string {
 // 2. Parse the corresponding .pb.go file's AST
 // 3. Inject tags into struct field definitions
 // 4. Write the modified Go code back
+//
+// With companion=true, the .pb.go file is neither read nor rewritten: the tags
+// and key metadata are generated in a companion file (see companionTemplate).
 func (m mod) Execute(targets map[string]pgs.File, packages map[string]pgs.Package) []pgs.Artifact {
 	outdir := m.Parameters().Str("outdir")
-	extractor := newTagExtractor(m, m.Context)
+	repository, err := m.Parameters().BoolDefault("repository", false)
+	m.CheckErr(err, "invalid repository parameter")
+	marshal, err := m.Parameters().BoolDefault("marshal", false)
+	m.CheckErr(err, "invalid marshal parameter")
+	exprs, err := m.Parameters().BoolDefault("expr", false)
+	m.CheckErr(err, "invalid expr parameter")
+	companion, err := m.Parameters().BoolDefault("companion", false)
+	m.CheckErr(err, "invalid companion parameter")
+	schemaFormats, err := schemaFormats(m.Parameters().StrDefault("schema", "cloudformation"))
+	m.CheckErr(err, "invalid schema parameter")
+	dialects, err := parseTagDialects(m.Parameters().StrDefault("tags", string(DialectGuregu)))
+	m.CheckErr(err, "invalid tags parameter")
+	if repository && !slices.Contains(dialects, DialectGuregu) {
+		m.Fail("repository=true requires the guregu tag dialect, used by the generated repositories")
+	}
+	if repository && companion && !marshal {
+		// Without tags on the messages, guregu needs their item marshalers
+		m.Fail("repository=true with companion=true requires marshal=true")
+	}
+	extractor := newTagExtractor(m, m.Context, dialects, marshal)
 
 	for _, f := range targets {
-		tags := extractor.Extract(f)
+		// Invalid annotations fail generation before anything is written
+		tags, err := extractor.Extract(f)
+		m.CheckErr(err)
+
+		if repository {
+			m.generateRepository(f, marshal)
+		}
+		if marshal {
+			m.generateItem(f)
+		}
+		if exprs {
+			m.generateExpr(f)
+		}
+		m.generateSchema(f, schemaFormats)
+		m.generateKeys(f)
+
+		if companion {
+			m.generateCompanion(f, dialects)
+			continue
+		}
 		if len(tags) == 0 {
 			continue // No dynamo annotations in this file
 		}
@@ -72,3 +132,121 @@ func (m mod) Execute(targets map[string]pgs.File, packages map[string]pgs.Packag
 
 	return m.Artifacts()
 }
+
+// generateRepository generates the repositories of the tables of a file, if
+// any. Tables with composite keys have none: their keys are not message fields.
+// With marshal, their updates encode values with the item marshalers.
+func (m mod) generateRepository(f pgs.File, marshal bool) {
+	all, err := extractTables(m.Context, f)
+	m.CheckErr(err)
+
+	var tables []*Table
+	for _, table := range all {
+		if !table.composite() {
+			tables = append(tables, table)
+		}
+	}
+	if len(tables) == 0 {
+		return
+	}
+
+	m.AddGeneratorTemplateFile(repositoryFileName(m.Context.OutputPath(f).String()), repositoryTemplate, RepositoryData{
+		Source:  f.InputPath().String(),
+		Package: m.Context.PackageName(f).String(),
+		Tables:  tables,
+		Marshal: marshal,
+	})
+}
+
+// generateCompanion generates the companion file of the annotated messages of a file, if any.
+func (m mod) generateCompanion(f pgs.File, dialects []TagDialect) {
+	data, err := extractCompanion(m.Context, f, dialects)
+	m.CheckErr(err)
+	if len(data.Messages) == 0 {
+		return
+	}
+
+	m.AddGeneratorTemplateFile(companionFileName(m.Context.OutputPath(f).String()), companionTemplate, data)
+}
+
+// generateKeys generates the composite key functions of the messages of a file, if any.
+func (m mod) generateKeys(f pgs.File) {
+	data, err := extractKeys(m.Context, f)
+	m.CheckErr(err)
+	if len(data.Messages) == 0 {
+		return
+	}
+
+	m.AddGeneratorTemplateFile(keysFileName(m.Context.OutputPath(f).String()), keysTemplate, data)
+}
+
+// generateItem generates the item marshalers of the annotated messages of a file, if any.
+func (m mod) generateItem(f pgs.File) {
+	messages, err := extractItemMessages(m.Context, f)
+	m.CheckErr(err)
+	if len(messages) == 0 {
+		return
+	}
+
+	m.AddGeneratorTemplateFile(itemFileName(m.Context.OutputPath(f).String()), itemTemplate, ItemData{
+		Source:   f.InputPath().String(),
+		Package:  m.Context.PackageName(f).String(),
+		Messages: messages,
+	})
+}
+
+// generateExpr generates the expression attributes of the annotated messages of a file, if any.
+func (m mod) generateExpr(f pgs.File) {
+	data, err := extractExpr(m.Context, f)
+	m.CheckErr(err)
+	if len(data.Messages) == 0 {
+		return
+	}
+
+	m.AddGeneratorTemplateFile(exprFileName(m.Context.OutputPath(f).String()), exprTemplate, data)
+}
+
+// generateSchema generates the definitions of the tables of a file with a
+// (dynamo.table) option, if any, in Go and in the given JSON formats.
+func (m mod) generateSchema(f pgs.File, formats []string) {
+	tables, err := extractTables(m.Context, f)
+	m.CheckErr(err)
+
+	var schemaTables []*Table
+	for _, table := range tables {
+		if table.Schema != nil {
+			schemaTables = append(schemaTables, table)
+		}
+	}
+	if len(schemaTables) == 0 {
+		return
+	}
+
+	pbFile := m.Context.OutputPath(f).String()
+	m.AddGeneratorTemplateFile(schemaFileName(pbFile), schemaTemplate, SchemaData{
+		Source:  f.InputPath().String(),
+		Package: m.Context.PackageName(f).String(),
+		Tables:  schemaTables,
+	})
+
+	for _, format := range formats {
+		content, err := generateSchemaJSON(format, schemaTables)
+		m.CheckErr(err)
+		m.AddGeneratorFile(schemaJSONFileName(pbFile, format), content)
+	}
+}
+
+// schemaFormats returns the JSON formats of the schema parameter:
+// "cloudformation" (default), "terraform", "all" or "none".
+func schemaFormats(param string) ([]string, error) {
+	switch param {
+	case "cloudformation", "terraform":
+		return []string{param}, nil
+	case "all":
+		return []string{"cloudformation", "terraform"}, nil
+	case "none":
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("unsupported schema format %q", param)
+	}
+}
@@ -0,0 +1,433 @@
+package godynamo
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io"
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+
+	pgs "github.com/lyft/protoc-gen-star/v2"
+	"google.golang.org/protobuf/cmd/protoc-gen-go/internal_gengo"
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"google.golang.org/protobuf/types/pluginpb"
+
+	dynamopb "github.com/getfrontierhq/buf-public-apis/gen/go/dynamo"
+)
+
+// The tests run the plugin on descriptors built in Go, as protoc would after
+// protoc-gen-go, and compile its output in a module using this repository and
+// the guregu/dynamo fake of testdata/guregu.
+
+const (
+	testProtoFile = "store/store.proto"
+	testModule    = "example.com/dyntest"
+)
+
+// Shorthands of the descriptors of the tests.
+const (
+	str      = descriptorpb.FieldDescriptorProto_TYPE_STRING
+	hash     = dynamopb.KeyType_KEY_TYPE_HASH
+	rangeKey = dynamopb.KeyType_KEY_TYPE_RANGE
+)
+
+// protoFile returns the proto3 file store/store.proto of package store.
+func protoFile(messages ...*descriptorpb.DescriptorProto) *descriptorpb.FileDescriptorProto {
+	return &descriptorpb.FileDescriptorProto{
+		Name:        proto.String(testProtoFile),
+		Package:     proto.String("store"),
+		Syntax:      proto.String("proto3"),
+		Dependency:  []string{"dynamo/annotations.proto", "google/protobuf/timestamp.proto"},
+		Options:     &descriptorpb.FileOptions{GoPackage: proto.String(testModule + "/store")},
+		MessageType: messages,
+	}
+}
+
+// message returns a message with the given fields, numbered in order.
+func message(name string, fields ...*descriptorpb.FieldDescriptorProto) *descriptorpb.DescriptorProto {
+	m := &descriptorpb.DescriptorProto{Name: proto.String(name), Field: fields}
+	for i, f := range fields {
+		f.Number = proto.Int32(int32(i + 1))
+		if f.GetProto3Optional() {
+			// proto3 optional fields belong to a synthetic oneof
+			f.OneofIndex = proto.Int32(int32(len(m.OneofDecl)))
+			m.OneofDecl = append(m.OneofDecl, &descriptorpb.OneofDescriptorProto{Name: proto.String("_" + f.GetName())})
+		}
+	}
+	return m
+}
+
+// field returns a singular field of a scalar type.
+func field(name string, typ descriptorpb.FieldDescriptorProto_Type) *descriptorpb.FieldDescriptorProto {
+	return &descriptorpb.FieldDescriptorProto{
+		Name:  proto.String(name),
+		Label: descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+		Type:  typ.Enum(),
+	}
+}
+
+// repeated marks f as a repeated field.
+func repeated(f *descriptorpb.FieldDescriptorProto) *descriptorpb.FieldDescriptorProto {
+	f.Label = descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum()
+	return f
+}
+
+// key sets the (dynamo.key) type of f.
+func key(f *descriptorpb.FieldDescriptorProto, typ dynamopb.KeyType) *descriptorpb.FieldDescriptorProto {
+	return withFieldOption(f, dynamopb.E_Key, &dynamopb.KeyConfig{Type: typ})
+}
+
+// optional marks f as a proto3 optional field.
+func optional(f *descriptorpb.FieldDescriptorProto) *descriptorpb.FieldDescriptorProto {
+	f.Proto3Optional = proto.Bool(true)
+	return f
+}
+
+// oneof adds the given fields of m to a oneof.
+func oneof(m *descriptorpb.DescriptorProto, name string, fields ...string) *descriptorpb.DescriptorProto {
+	index := proto.Int32(int32(len(m.OneofDecl)))
+	m.OneofDecl = append(m.OneofDecl, &descriptorpb.OneofDescriptorProto{Name: proto.String(name)})
+	for _, f := range m.Field {
+		for _, name := range fields {
+			if f.GetName() == name {
+				f.OneofIndex = index
+			}
+		}
+	}
+	return m
+}
+
+// timestampField returns a google.protobuf.Timestamp field.
+func timestampField(name string) *descriptorpb.FieldDescriptorProto {
+	f := field(name, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE)
+	f.TypeName = proto.String(".google.protobuf.Timestamp")
+	return f
+}
+
+// withMessageOption sets an extension of the options of m.
+func withMessageOption(m *descriptorpb.DescriptorProto, xt protoreflect.ExtensionType, v any) *descriptorpb.DescriptorProto {
+	if m.Options == nil {
+		m.Options = &descriptorpb.MessageOptions{}
+	}
+	proto.SetExtension(m.Options, xt, v)
+	return m
+}
+
+// withFieldOption sets an extension of the options of f.
+func withFieldOption(f *descriptorpb.FieldDescriptorProto, xt protoreflect.ExtensionType, v any) *descriptorpb.FieldDescriptorProto {
+	if f.Options == nil {
+		f.Options = &descriptorpb.FieldOptions{}
+	}
+	proto.SetExtension(f.Options, xt, v)
+	return f
+}
+
+// codeGeneratorRequest returns the request of protoc generating file.
+func codeGeneratorRequest(params string, file *descriptorpb.FileDescriptorProto) *pluginpb.CodeGeneratorRequest {
+	req := &pluginpb.CodeGeneratorRequest{
+		FileToGenerate: []string{file.GetName()},
+		Parameter:      proto.String(params),
+	}
+	for _, dep := range []protoreflect.FileDescriptor{
+		descriptorpb.File_google_protobuf_descriptor_proto,
+		timestamppb.File_google_protobuf_timestamp_proto,
+		dynamopb.File_dynamo_annotations_proto,
+	} {
+		req.ProtoFile = append(req.ProtoFile, protodesc.ToFileDescriptorProto(dep))
+	}
+	// The annotations are generated into this repository (see buf.gen.yaml)
+	req.ProtoFile[2].Options.GoPackage = proto.String("github.com/getfrontierhq/buf-public-apis/gen/go/dynamo")
+	req.ProtoFile = append(req.ProtoFile, file)
+	return req
+}
+
+// generate runs protoc-gen-go and the plugin with the given parameters on
+// file, and returns the generated files by name: the .pb.go file, rewritten by
+// the plugin unless companion=true, and the files of the plugin. A failed
+// generation returns the failure messages of the plugin.
+func generate(t *testing.T, params string, file *descriptorpb.FileDescriptorProto) (map[string]string, error) {
+	t.Helper()
+	files := map[string]string{}
+
+	gen, err := protogen.Options{}.New(codeGeneratorRequest("paths=source_relative", file))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range gen.Files {
+		if f.Generate {
+			internal_gengo.GenerateFile(gen, f)
+		}
+	}
+	outdir := t.TempDir()
+	for _, f := range gen.Response().GetFile() {
+		files[f.GetName()] = f.GetContent()
+		writeFile(t, filepath.Join(outdir, f.GetName()), f.GetContent())
+	}
+
+	params = strings.Trim("paths=source_relative,outdir="+outdir+","+params, ",")
+	d := pgs.InitMockDebugger()
+	artifacts := execute(d, codeGeneratorRequest(params, file))
+	if d.Exited() {
+		out, _ := io.ReadAll(d.Output())
+		return nil, fmt.Errorf("%s", out)
+	}
+
+	for _, a := range artifacts {
+		f, err := a.(pgs.GeneratorArtifact).ProtoFile()
+		if err != nil {
+			t.Fatal(err)
+		}
+		content := f.GetContent()
+		if strings.HasSuffix(f.GetName(), ".go") {
+			formatted, err := format.Source([]byte(content))
+			if err != nil {
+				t.Fatalf("%s: %v\n%s", f.GetName(), err, content)
+			}
+			content = string(formatted)
+		}
+		files[f.GetName()] = content
+	}
+	return files, nil
+}
+
+// execute runs the plugin module. A failing module exits through the mock
+// debugger, so it keeps running on the invalid input and may panic.
+func execute(d pgs.MockDebugger, req *pluginpb.CodeGeneratorRequest) (artifacts []pgs.Artifact) {
+	defer func() {
+		if r := recover(); r != nil && !d.Exited() {
+			panic(r)
+		}
+	}()
+
+	ast := pgs.ProcessCodeGeneratorRequest(d, req)
+	m := New()
+	m.InitContext(pgs.Context(d, pgs.ParseParameters(req.GetParameter()), "."))
+	return m.Execute(ast.Targets(), ast.Packages())
+}
+
+// goTest writes the generated files and the given tests of testdata/dyntest
+// into the store package of a new module, and runs its tests.
+func goTest(t *testing.T, files map[string]string, tests ...string) {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("compiles the generated code")
+	}
+	goCmd := filepath.Join(runtime.GOROOT(), "bin", "go")
+	if _, err := os.Stat(goCmd); err != nil {
+		t.Skip("go command not found")
+	}
+
+	root, err := filepath.Abs(filepath.Join("..", ".."))
+	if err != nil {
+		t.Fatal(err)
+	}
+	goMod, err := os.ReadFile(filepath.Join(root, "go.mod"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	goSum, err := os.ReadFile(filepath.Join(root, "go.sum"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The module has the requirements of this repository, so go.sum is complete
+	dir := t.TempDir()
+	mod := strings.Replace(string(goMod), "module github.com/getfrontierhq/buf-public-apis", "module "+testModule, 1)
+	mod += fmt.Sprintf(`
+require (
+	github.com/getfrontierhq/buf-public-apis v0.0.0
+	github.com/guregu/dynamo/v2 v2.0.0
+)
+
+replace github.com/getfrontierhq/buf-public-apis => %s
+
+replace github.com/guregu/dynamo/v2 => %s
+`, root, filepath.Join(root, "internal", "godynamo", "testdata", "guregu"))
+	writeFile(t, filepath.Join(dir, "go.mod"), mod)
+	writeFile(t, filepath.Join(dir, "go.sum"), string(goSum))
+	for name, content := range files {
+		writeFile(t, filepath.Join(dir, name), content)
+	}
+	for _, test := range tests {
+		content, err := os.ReadFile(filepath.Join("testdata", "dyntest", test))
+		if err != nil {
+			t.Fatal(err)
+		}
+		writeFile(t, filepath.Join(dir, "store", test), string(content))
+	}
+
+	cmd := exec.Command(goCmd, "test", "./...")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod", "GOWORK=off")
+	var out bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &out, &out
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("go test: %v\n%s", err, out.String())
+	}
+}
+
+func writeFile(t *testing.T, name, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(name, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// user is a table with a range key, a GSI and an optional attribute.
+func user() *descriptorpb.DescriptorProto {
+	return message("User",
+		withFieldOption(field("id", str), dynamopb.E_Key, &dynamopb.KeyConfig{Type: hash, ColumnName: "ID"}),
+		key(field("created_at", descriptorpb.FieldDescriptorProto_TYPE_INT64), rangeKey),
+		withFieldOption(field("email", str), dynamopb.E_Gsi, []*dynamopb.IndexConfig{
+			{Name: "email-index", Key: hash},
+		}),
+		withFieldOption(optional(field("nickname", str)), dynamopb.E_Attribute, &dynamopb.AttributeConfig{Omitempty: true}),
+	)
+}
+
+// session is a table with a version field.
+func session() *descriptorpb.DescriptorProto {
+	return message("Session",
+		key(field("token", str), hash),
+		field("user_id", str),
+		withFieldOption(field("version", descriptorpb.FieldDescriptorProto_TYPE_UINT32), dynamopb.E_Attribute, &dynamopb.AttributeConfig{Version: true}),
+	)
+}
+
+// profile is a table with fields of every kind of presence, stored by the
+// item marshalers.
+func profile() *descriptorpb.DescriptorProto {
+	return message("Profile",
+		key(field("id", str), hash),
+		timestampField("updated_at"),
+		field("visits", descriptorpb.FieldDescriptorProto_TYPE_INT64),
+		optional(field("nickname", str)),
+	)
+}
+
+// order is a table with composite keys, and an index of composite keys.
+func order() *descriptorpb.DescriptorProto {
+	return withMessageOption(message("Order",
+		field("id", str),
+		field("user_id", str),
+		field("created_at", descriptorpb.FieldDescriptorProto_TYPE_INT64),
+		field("type", str),
+	), dynamopb.E_CompositeKey, []*dynamopb.CompositeKeyConfig{
+		{Name: "PK", Template: "USER#{user_id}", Key: hash},
+		{Name: "SK", Template: "ORDER#{created_at:20}#{id}", Key: rangeKey},
+		{Name: "GSI1PK", Template: "TYPE#{type}", Key: hash, Index: "gsi1"},
+		{Name: "GSI1SK", Template: "{created_at:20}", Key: rangeKey, Index: "gsi1"},
+	})
+}
+
+// TestGenerate compiles the output of the plugin and runs a test of
+// testdata/dyntest on it.
+func TestGenerate(t *testing.T) {
+	tests := []struct {
+		name     string
+		params   string
+		messages []*descriptorpb.DescriptorProto // user() if none
+		files    []string                        // Generated files, the .pb.go file included
+		test     string
+	}{
+		{
+			name:  "guregu tags",
+			files: []string{"store/store.pb.go"},
+			test:  "guregu_test.go",
+		},
+		{
+			name:   "repository",
+			params: "repository=true",
+			files:  []string{"store/store.pb.go", "store/store_dynamo_repo.pb.go"},
+			test:   "repository_test.go",
+		},
+		{
+			name:     "marshaled repository",
+			params:   "repository=true,marshal=true",
+			messages: []*descriptorpb.DescriptorProto{profile()},
+			files:    []string{"store/store.pb.go", "store/store_dynamo_item.pb.go", "store/store_dynamo_repo.pb.go"},
+			test:     "marshal_repository_test.go",
+		},
+		{
+			name:     "versioned repository",
+			params:   "repository=true",
+			messages: []*descriptorpb.DescriptorProto{session()},
+			files:    []string{"store/store.pb.go", "store/store_dynamo_repo.pb.go"},
+			test:     "version_test.go",
+		},
+		{
+			name:     "table schema",
+			params:   "schema=all",
+			messages: []*descriptorpb.DescriptorProto{withMessageOption(user(), dynamopb.E_Table, &dynamopb.TableConfig{Name: "users"})},
+			files: []string{
+				"store/store.pb.go", "store/store_dynamo.cfn.json", "store/store_dynamo.tf.json",
+				"store/store_dynamo_schema.pb.go",
+			},
+			test: "schema_test.go",
+		},
+		{
+			name:     "composite keys",
+			params:   "marshal=true",
+			messages: []*descriptorpb.DescriptorProto{order()},
+			files:    []string{"store/store.pb.go", "store/store_dynamo_item.pb.go", "store/store_dynamo_keys.pb.go"},
+			test:     "keys_test.go",
+		},
+		{
+			name:     "expression attributes",
+			params:   "expr=true",
+			messages: []*descriptorpb.DescriptorProto{user(), order()},
+			files:    []string{"store/store.pb.go", "store/store_dynamo_expr.pb.go", "store/store_dynamo_keys.pb.go"},
+			test:     "expr_test.go",
+		},
+		{
+			name:   "item marshalers",
+			params: "marshal=true",
+			files:  []string{"store/store.pb.go", "store/store_dynamo_item.pb.go"},
+			test:   "item_test.go",
+		},
+		{
+			name:   "awsv2 tags",
+			params: "tags=awsv2",
+			files:  []string{"store/store.pb.go"},
+			test:   "awsv2_test.go",
+		},
+		{
+			name:   "companion",
+			params: "companion=true",
+			files:  []string{"store/store.pb.go", "store/store_dynamo.pb.go"},
+			test:   "companion_test.go",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			messages := tt.messages
+			if messages == nil {
+				messages = []*descriptorpb.DescriptorProto{user()}
+			}
+			files, err := generate(t, tt.params, protoFile(messages...))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if names := slices.Sorted(maps.Keys(files)); !slices.Equal(names, tt.files) {
+				t.Errorf("generated %v, want %v", names, tt.files)
+			}
+			goTest(t, files, tt.test)
+		})
+	}
+}
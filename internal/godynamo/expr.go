@@ -0,0 +1,139 @@
+package godynamo
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"text/template"
+
+	pgs "github.com/lyft/protoc-gen-star/v2"
+	pgsgo "github.com/lyft/protoc-gen-star/v2/lang/go"
+)
+
+// ExprMessage is an annotated message with typed expression attributes.
+type ExprMessage struct {
+	Name       string     // Go type name (e.g., "User")
+	Attributes []ExprAttr // Field attributes in declaration order, then composite keys
+}
+
+// ExprAttr is a typed attribute of an expression.
+type ExprAttr struct {
+	GoName string // Go field name (e.g., "CreatedAt")
+	Name   string // Attribute name: column_name, or the Go field name
+	Type   string // Go scalar type (e.g., "int64"), "" for lists, maps and messages
+}
+
+// ExprData is the data of the expression template of a proto file.
+type ExprData struct {
+	Source   string
+	Package  string
+	Imports  []CompanionImport
+	Messages []*ExprMessage
+}
+
+// extractExpr returns the expression attributes of the annotated messages of a file.
+func extractExpr(ctx pgsgo.Context, f pgs.File) (*ExprData, error) {
+	data := &ExprData{
+		Source:  f.InputPath().String(),
+		Package: ctx.PackageName(f).String(),
+	}
+	imports := map[string]string{}
+
+	for _, msg := range f.AllMessages() {
+		annotated, err := isAnnotated(msg)
+		if err != nil {
+			return nil, err
+		}
+		if !annotated {
+			continue
+		}
+
+		message := &ExprMessage{Name: ctx.Name(msg).String()}
+		for _, fd := range msg.Fields() {
+			keyCfg, err := getKeyConfig(fd)
+			if err != nil {
+				return nil, fmt.Errorf("%s: invalid dynamo.key option: %w", fd.FullyQualifiedName(), err)
+			}
+
+			attr := ExprAttr{GoName: ctx.Name(fd).String()}
+			attr.Name = attr.GoName
+			if keyCfg.GetColumnName() != "" {
+				attr.Name = keyCfg.GetColumnName()
+			}
+			if ft := fd.Type(); !ft.IsRepeated() && !ft.IsMap() && !ft.IsEmbed() {
+				// Scalars with presence (proto3 optional) are pointers
+				attr.Type = strings.TrimPrefix(ctx.Type(fd).String(), "*")
+				for _, e := range fieldTypeEntities(fd) {
+					if ctx.ImportPath(e) != ctx.ImportPath(f) {
+						imports[ctx.ImportPath(e).String()] = ctx.PackageName(e).String()
+					}
+				}
+			}
+			message.Attributes = append(message.Attributes, attr)
+		}
+
+		composites, err := getCompositeKeys(msg)
+		if err != nil {
+			return nil, fmt.Errorf("%s: invalid dynamo.composite_key option: %w", msg.FullyQualifiedName(), err)
+		}
+		for _, cfg := range composites {
+			message.Attributes = append(message.Attributes, ExprAttr{GoName: goIdentifier(cfg.GetName()), Name: cfg.GetName(), Type: "string"})
+		}
+		data.Messages = append(data.Messages, message)
+	}
+
+	for path, alias := range imports {
+		data.Imports = append(data.Imports, CompanionImport{Alias: alias, Path: path})
+	}
+	sort.Slice(data.Imports, func(i, j int) bool { return data.Imports[i].Path < data.Imports[j].Path })
+	return data, nil
+}
+
+// exprFileName returns the name of the expression file generated next to a .pb.go file.
+// Example: "users/v1/users.pb.go" -> "users/v1/users_dynamo_expr.pb.go"
+func exprFileName(pbFile string) string {
+	return strings.TrimSuffix(pbFile, ".pb.go") + "_dynamo_expr.pb.go"
+}
+
+// exprTemplate generates the expression attributes of the annotated messages
+// of a file (expr=true), backed by the expr package. For each message:
+//   - <Msg>DynamoAttr<Field> constants, the attribute names
+//   - <Msg>DynamoAttrs, an expr.Attr per scalar field, typed with its Go type,
+//     and an expr.Path per list, map or message field
+//
+// Example:
+//
+//	e, err := expr.Builder{
+//		Condition: pb.UserDynamoAttrs.Email.NotExists(),
+//		Update:    expr.Update{pb.UserDynamoAttrs.Name.Set("Ada")},
+//	}.Build()
+var exprTemplate = template.Must(template.New("expr").Parse(`// Code generated by protoc-gen-go-dynamo. DO NOT EDIT.
+// source: {{.Source}}
+
+package {{.Package}}
+
+import (
+	"github.com/getfrontierhq/buf-public-apis/pkg/godynamo/expr"
+{{- range .Imports}}
+	{{.Alias}} "{{.Path}}"
+{{- end}}
+)
+{{range .Messages}}{{$m := .}}
+// Attribute names of {{.Name}} items.
+const (
+{{- range .Attributes}}
+	{{$m.Name}}DynamoAttr{{.GoName}} = {{printf "%q" .Name}}
+{{- end}}
+)
+
+// {{.Name}}DynamoAttrs are the typed attributes of {{.Name}} items, for expressions.
+var {{.Name}}DynamoAttrs = struct {
+{{- range .Attributes}}
+	{{.GoName}} {{if .Type}}expr.Attr[{{.Type}}]{{else}}expr.Path{{end}}
+{{- end}}
+}{
+{{- range .Attributes}}
+	{{.GoName}}: {{if .Type}}expr.NewAttr[{{.Type}}]{{else}}expr.NewPath{{end}}({{$m.Name}}DynamoAttr{{.GoName}}),
+{{- end}}
+}
+{{end}}`))
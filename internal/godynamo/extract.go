@@ -4,31 +4,309 @@ package godynamo
 
 import (
 	"fmt"
+	"sort"
+	"strings"
 
 	pgs "github.com/lyft/protoc-gen-star/v2"
 	pgsgo "github.com/lyft/protoc-gen-star/v2/lang/go"
+	"google.golang.org/protobuf/proto"
 
 	dynamopb "github.com/getfrontierhq/buf-public-apis/gen/go/dynamo"
+	"github.com/getfrontierhq/buf-public-apis/pkg/godynamo/compositekey"
 )
 
 // DynamoTags maps message names to field names to tag strings.
 // Example: {"UserAction": {"UserId": `dynamo:"ID,hash" index:"Seq-ID-index,range"`}}
 type DynamoTags map[string]map[string]string
 
+// TagDialect is the struct tag syntax of a DynamoDB marshaler.
+type TagDialect string
+
+const (
+	// DialectGuregu emits the dynamo, index and localIndex tags of guregu/dynamo.
+	DialectGuregu TagDialect = "guregu"
+	// DialectAWSV2 emits the dynamodbav tags of the aws-sdk-go-v2 attributevalue package.
+	DialectAWSV2 TagDialect = "awsv2"
+)
+
+// parseTagDialects parses the tags parameter: dialects separated by ";".
+// Example: "guregu;awsv2"
+func parseTagDialects(param string) ([]TagDialect, error) {
+	var dialects []TagDialect
+	for _, name := range strings.Split(param, ";") {
+		switch dialect := TagDialect(strings.TrimSpace(name)); dialect {
+		case DialectGuregu, DialectAWSV2:
+			dialects = append(dialects, dialect)
+		case "":
+		default:
+			return nil, fmt.Errorf("unsupported tag dialect %q (expected: guregu or awsv2)", name)
+		}
+	}
+	if len(dialects) == 0 {
+		return nil, fmt.Errorf("no tag dialect")
+	}
+	return dialects, nil
+}
+
 type tagExtractor struct {
 	pgs.Visitor
 	pgs.DebuggerCommon
 	pgsgo.Context
 
-	tags DynamoTags
+	dialects []TagDialect
+	marshal  bool // Item marshalers are generated (marshal=true)
+	tags     DynamoTags
+	diags    Diagnostics
 }
 
-func newTagExtractor(d pgs.DebuggerCommon, ctx pgsgo.Context) *tagExtractor {
-	v := &tagExtractor{DebuggerCommon: d, Context: ctx}
+// DynamoDB limits on the secondary indexes of a table.
+const (
+	maxGSIs = 20
+	maxLSIs = 5
+)
+
+func newTagExtractor(d pgs.DebuggerCommon, ctx pgsgo.Context, dialects []TagDialect, marshal bool) *tagExtractor {
+	v := &tagExtractor{DebuggerCommon: d, Context: ctx, dialects: dialects, marshal: marshal}
 	v.Visitor = pgs.PassThroughVisitor(v)
 	return v
 }
 
+// keySource is the field or composite key declaring a key, for diagnostics.
+type keySource struct {
+	field     pgs.Field
+	composite string // Composite key attribute name
+}
+
+func (k *keySource) String() string {
+	if k.field != nil {
+		return k.field.Name().String()
+	}
+	return fmt.Sprintf("composite key %s", k.composite)
+}
+
+// VisitMessage validates the keys and indexes declared across the fields and
+// composite keys of a message.
+func (v *tagExtractor) VisitMessage(m pgs.Message) (pgs.Visitor, error) {
+	var hashKey, rangeKey *keySource
+	type indexKeys struct {
+		local             bool
+		hashKey, rangeKey *keySource
+		projection        pgs.Field // Field declaring the projection (nil if none)
+	}
+	var indexNames []string
+	indexes := map[string]*indexKeys{}
+	var firstLSI, version pgs.Field
+
+	// setKey records a key, reporting a second key of the same type at e
+	setKey := func(e pgs.Entity, slot **keySource, key *keySource, desc string) {
+		if *slot != nil {
+			v.addDiagnostic(e, "%s is already declared by %s", desc, *slot)
+		}
+		*slot = key
+	}
+	setIndexKey := func(e pgs.Entity, name string, local bool, keyType dynamopb.KeyType, key *keySource) {
+		index, ok := indexes[name]
+		if !ok {
+			index = &indexKeys{local: local}
+			indexes[name] = index
+			indexNames = append(indexNames, name)
+		} else if index.local != local && key.field != nil {
+			v.addDiagnostic(e, "index %q is declared both as a GSI and an LSI", name)
+		}
+		switch keyType {
+		case dynamopb.KeyType_KEY_TYPE_HASH:
+			setKey(e, &index.hashKey, key, fmt.Sprintf("the KEY_TYPE_HASH key of index %q", name))
+		case dynamopb.KeyType_KEY_TYPE_RANGE:
+			setKey(e, &index.rangeKey, key, fmt.Sprintf("the KEY_TYPE_RANGE key of index %q", name))
+		}
+	}
+
+	for _, f := range m.Fields() {
+		key := &keySource{field: f}
+
+		if attrCfg, err := getAttributeConfig(f); err == nil && attrCfg.GetVersion() {
+			if version != nil {
+				v.addDiagnostic(f, "second version field, the version is %s", version.Name())
+			}
+			version = f
+		}
+
+		// Invalid options are reported by VisitField
+		if keyCfg, err := getKeyConfig(f); err == nil {
+			switch keyCfg.GetType() {
+			case dynamopb.KeyType_KEY_TYPE_HASH:
+				setKey(f, &hashKey, key, "the table KEY_TYPE_HASH key")
+			case dynamopb.KeyType_KEY_TYPE_RANGE:
+				setKey(f, &rangeKey, key, "the table KEY_TYPE_RANGE key")
+			}
+		}
+
+		gsis, _ := getGSIs(f)
+		lsis, _ := getLSIs(f)
+		for i, cfg := range append(gsis, lsis...) {
+			local := i >= len(gsis)
+			if cfg.GetName() == "" {
+				continue
+			}
+			if local && firstLSI == nil {
+				firstLSI = f
+			}
+			setIndexKey(f, cfg.GetName(), local, cfg.GetKey(), key)
+			if cfg.GetProjectionType() != dynamopb.ProjectionType_PROJECTION_TYPE_UNSPECIFIED {
+				v.checkProjection(m, f, cfg)
+				if index := indexes[cfg.GetName()]; index.projection != nil {
+					v.addDiagnostic(f, "the projection of index %q is already declared by %s", cfg.GetName(), index.projection.Name())
+				} else {
+					index.projection = f
+				}
+			}
+		}
+	}
+
+	composites, err := getCompositeKeys(m)
+	if err != nil {
+		v.addDiagnostic(m, "invalid dynamo.composite_key option: %v", err)
+	}
+	for _, cfg := range composites {
+		if !v.validateCompositeKey(m, cfg) {
+			continue
+		}
+		key := &keySource{composite: cfg.GetName()}
+		if cfg.GetIndex() != "" {
+			local := false
+			if index, ok := indexes[cfg.GetIndex()]; ok {
+				local = index.local
+			}
+			setIndexKey(m, cfg.GetIndex(), local, cfg.GetKey(), key)
+			continue
+		}
+		switch cfg.GetKey() {
+		case dynamopb.KeyType_KEY_TYPE_HASH:
+			setKey(m, &hashKey, key, "the table KEY_TYPE_HASH key")
+		case dynamopb.KeyType_KEY_TYPE_RANGE:
+			setKey(m, &rangeKey, key, "the table KEY_TYPE_RANGE key")
+		}
+	}
+
+	if rangeKey != nil && hashKey == nil {
+		v.addDiagnostic(m, "KEY_TYPE_RANGE key %s without a KEY_TYPE_HASH key in the message", rangeKey)
+	}
+	if firstLSI != nil && hashKey == nil {
+		v.addDiagnostic(firstLSI, "LSI without a table KEY_TYPE_HASH key, LSIs share the table hash key")
+	}
+
+	var tableCfg dynamopb.TableConfig
+	if _, err := m.Extension(dynamopb.E_Table, &tableCfg); err == nil {
+		for _, settings := range tableCfg.GetIndexes() {
+			index, ok := indexes[settings.GetName()]
+			declared := settings.GetProjectionType() != dynamopb.ProjectionType_PROJECTION_TYPE_UNSPECIFIED || len(settings.GetNonKeyAttributes()) > 0
+			if ok && declared && index.projection != nil {
+				v.addDiagnostic(index.projection, "the projection of index %q is already declared by the indexes of dynamo.table", settings.GetName())
+			}
+		}
+		for _, f := range m.Fields() {
+			if f.Name().String() == tableCfg.GetTtlAttribute() && isTimestamp(f) && !v.marshal {
+				// Like unixtime, which it implies
+				v.addDiagnostic(f, "a google.protobuf.Timestamp ttl_attribute requires marshal=true, struct tags store a Timestamp as a map")
+			}
+		}
+	}
+
+	var gsiCount, lsiCount int
+	for _, name := range indexNames {
+		index := indexes[name]
+		if index.local {
+			lsiCount++
+			continue
+		}
+		gsiCount++
+		if index.hashKey == nil && index.rangeKey != nil {
+			v.addDiagnostic(m, "GSI %q has no KEY_TYPE_HASH key", name)
+		}
+	}
+	if gsiCount > maxGSIs {
+		v.addDiagnostic(m, "%d GSIs, DynamoDB allows at most %d per table", gsiCount, maxGSIs)
+	}
+	if lsiCount > maxLSIs {
+		v.addDiagnostic(m, "%d LSIs, DynamoDB allows at most %d per table", lsiCount, maxLSIs)
+	}
+
+	return v, nil
+}
+
+// checkProjection reports the non_key_attributes of an index of f that are
+// not fields of m.
+func (v *tagExtractor) checkProjection(m pgs.Message, f pgs.Field, cfg *dynamopb.IndexConfig) {
+	for _, name := range cfg.GetNonKeyAttributes() {
+		found := false
+		for _, fd := range m.Fields() {
+			found = found || fd.Name().String() == name
+		}
+		if !found {
+			v.addDiagnostic(f, "index %q: non_key_attributes: no field %s", cfg.GetName(), name)
+		}
+	}
+}
+
+// validateCompositeKey reports the errors of a composite key of m, and
+// returns whether it is valid.
+func (v *tagExtractor) validateCompositeKey(m pgs.Message, cfg *dynamopb.CompositeKeyConfig) bool {
+	valid := true
+	report := func(format string, args ...any) {
+		v.addDiagnostic(m, "composite key %q: "+format, append([]any{cfg.GetName()}, args...)...)
+		valid = false
+	}
+
+	if cfg.GetName() == "" {
+		report("empty name")
+	}
+	if cfg.GetKey() == dynamopb.KeyType_KEY_TYPE_UNSPECIFIED {
+		report("no key, use KEY_TYPE_HASH or KEY_TYPE_RANGE")
+	}
+	for _, f := range m.Fields() {
+		if keyCfg, _ := getKeyConfig(f); keyCfg.GetColumnName() == cfg.GetName() || (keyCfg.GetColumnName() == "" && v.Context.Name(f).String() == cfg.GetName()) {
+			report("attribute name of field %s", f.Name())
+		}
+	}
+
+	tmpl, err := compositekey.Parse(cfg.GetTemplate())
+	if err != nil {
+		report("%v", err)
+		return false
+	}
+	for _, name := range tmpl.Fields() {
+		var field pgs.Field
+		for _, f := range m.Fields() {
+			if f.Name().String() == name {
+				field = f
+			}
+		}
+		switch {
+		case field == nil:
+			report("no field %s", name)
+		case field.Type().IsRepeated() || field.Type().IsMap() || field.Type().IsEmbed():
+			report("field %s is not a string, integer or enum", name)
+		case field.HasPresence():
+			// Their Go fields are pointers or oneof wrappers
+			report("field %s has presence (optional or oneof), use a plain field", name)
+		case field.Type().IsEnum(), field.Type().ProtoType() == pgs.StringT, isInteger(field.Type().ProtoType()):
+		default:
+			report("field %s is not a string, integer or enum", name)
+		}
+	}
+	return valid
+}
+
+// isInteger reports whether a proto type is an integer type.
+func isInteger(pt pgs.ProtoType) bool {
+	switch pt {
+	case pgs.Int32T, pgs.Int64T, pgs.UInt32T, pgs.UInt64T,
+		pgs.SInt32, pgs.SInt64, pgs.Fixed32T, pgs.Fixed64T, pgs.SFixed32, pgs.SFixed64:
+		return true
+	}
+	return false
+}
+
 // VisitField extracts dynamo annotations from a proto field and builds the tag string.
 func (v *tagExtractor) VisitField(f pgs.Field) (pgs.Visitor, error) {
 	msgName := v.Context.Name(f.Message()).String()
@@ -37,7 +315,9 @@ func (v *tagExtractor) VisitField(f pgs.Field) (pgs.Visitor, error) {
 		v.tags[msgName] = map[string]string{}
 	}
 
-	tagStr := buildTagsFromField(f)
+	v.validateField(f)
+
+	tagStr := buildTagsFromField(f, v.dialects)
 	if tagStr != "" {
 		fieldName := v.Context.Name(f).String()
 		v.tags[msgName][fieldName] = tagStr
@@ -46,37 +326,149 @@ func (v *tagExtractor) VisitField(f pgs.Field) (pgs.Visitor, error) {
 	return v, nil
 }
 
-// Extract walks the proto file and returns all dynamo tags.
-func (v *tagExtractor) Extract(f pgs.File) DynamoTags {
-	v.tags = DynamoTags{}
-	v.CheckErr(pgs.Walk(v, f))
-	return v.tags
-}
+// validateField reports the invalid dynamo options of a field.
+func (v *tagExtractor) validateField(f pgs.Field) {
+	keyCfg, err := getKeyConfig(f)
+	if err != nil {
+		v.addDiagnostic(f, "invalid dynamo.key option: %v", err)
+	}
+	gsis, err := getGSIs(f)
+	if err != nil {
+		v.addDiagnostic(f, "invalid dynamo.gsi option: %v", err)
+	}
+	lsis, err := getLSIs(f)
+	if err != nil {
+		v.addDiagnostic(f, "invalid dynamo.lsi option: %v", err)
+	}
 
-// buildTagsFromField reads field options and constructs the complete tag string.
-// Example: `dynamo:"id,hash" index:"username-index,hash"`
-func buildTagsFromField(f pgs.Field) string {
-	var parts []string
+	checkIndex := func(kind string, cfg *dynamopb.IndexConfig) {
+		if cfg.GetName() == "" {
+			v.addDiagnostic(f, "%s with an empty name", kind)
+		}
+		if cfg.GetKey() == dynamopb.KeyType_KEY_TYPE_UNSPECIFIED {
+			v.addDiagnostic(f, "%s %q without key, use KEY_TYPE_HASH or KEY_TYPE_RANGE", kind, cfg.GetName())
+		}
+		switch include := cfg.GetProjectionType() == dynamopb.ProjectionType_PROJECTION_TYPE_INCLUDE; {
+		case include && len(cfg.GetNonKeyAttributes()) == 0:
+			v.addDiagnostic(f, "%s %q: PROJECTION_TYPE_INCLUDE without non_key_attributes", kind, cfg.GetName())
+		case !include && len(cfg.GetNonKeyAttributes()) > 0:
+			v.addDiagnostic(f, "%s %q: non_key_attributes require PROJECTION_TYPE_INCLUDE", kind, cfg.GetName())
+		}
+		if cfg.GetSparse() && keyCfg.GetType() != dynamopb.KeyType_KEY_TYPE_UNSPECIFIED {
+			v.addDiagnostic(f, "sparse %s %q on a table key field, table keys are in every item", kind, cfg.GetName())
+		}
+	}
+	for _, cfg := range gsis {
+		checkIndex("GSI", cfg)
+	}
+	for _, cfg := range lsis {
+		checkIndex("LSI", cfg)
+	}
 
-	// Check for primary key
-	if keyCfg, err := getKeyConfig(f); err == nil && keyCfg != nil {
-		tagStr := buildKeyTag(keyCfg)
-		if tagStr != "" {
-			parts = append(parts, tagStr)
+	attrCfg, err := getAttributeConfig(f)
+	if err != nil {
+		v.addDiagnostic(f, "invalid dynamo.attribute option: %v", err)
+	}
+	if set := attrCfg.GetSet(); set != dynamopb.SetType_SET_TYPE_UNSPECIFIED {
+		elem := f.Type().Element()
+		switch {
+		case !f.Type().IsRepeated() || f.Type().IsMap():
+			v.addDiagnostic(f, "%s on a non-repeated field", set)
+		case set == dynamopb.SetType_SET_TYPE_STRING && elem.ProtoType() != pgs.StringT,
+			set == dynamopb.SetType_SET_TYPE_NUMBER && !elem.ProtoType().IsNumeric(),
+			set == dynamopb.SetType_SET_TYPE_BINARY && elem.ProtoType() != pgs.BytesT:
+			v.addDiagnostic(f, "%s on a repeated %s field", set, elem.ProtoType().Proto())
+		}
+	}
+	if attrCfg.GetUnixtime() {
+		switch {
+		case !isTimestamp(f):
+			v.addDiagnostic(f, "unixtime on a field other than google.protobuf.Timestamp")
+		case !v.marshal:
+			// guregu and attributevalue honor unixtime on time.Time fields only
+			v.addDiagnostic(f, "unixtime requires marshal=true, struct tags store a google.protobuf.Timestamp as a map")
 		}
 	}
 
-	// Check for GSIs (now repeated)
-	if gsis, err := getGSIs(f); err == nil && len(gsis) > 0 {
-		for _, gsi := range gsis {
-			parts = append(parts, buildGSITag(gsi))
+	isKey := keyCfg.GetType() != dynamopb.KeyType_KEY_TYPE_UNSPECIFIED || len(gsis) > 0 || len(lsis) > 0
+	if attrCfg.GetVersion() {
+		if typ := f.Type(); typ.IsRepeated() || typ.IsMap() || !isInteger(typ.ProtoType()) {
+			v.addDiagnostic(f, "version on a non-integer field")
+		}
+		if isKey {
+			v.addDiagnostic(f, "version on a key field, versions change on every write")
 		}
 	}
+	if !isKey {
+		return
+	}
+	switch typ := f.Type(); {
+	case typ.IsMap():
+		v.addDiagnostic(f, "key on a map field, use a string, integer, bytes or enum field")
+	case typ.IsRepeated():
+		v.addDiagnostic(f, "key on a repeated field, use a string, integer, bytes or enum field")
+	case typ.IsEmbed():
+		v.addDiagnostic(f, "key on a message field, use a string, integer, bytes or enum field")
+	case typ.ProtoType() == pgs.BoolT:
+		// DynamoDB keys are S, N or B
+		v.addDiagnostic(f, "key on a bool field, use a string, integer, bytes or enum field")
+	case typ.ProtoType() == pgs.FloatT || typ.ProtoType() == pgs.DoubleT:
+		// Keys are matched exactly, which rounding makes unreliable
+		v.addDiagnostic(f, "key on a floating-point field, use a string, integer, bytes or enum field")
+	}
+}
+
+// addDiagnostic records an invalid annotation of e.
+func (v *tagExtractor) addDiagnostic(e pgs.Entity, format string, args ...any) {
+	v.diags = append(v.diags, newDiagnostic(e, format, args...))
+}
+
+// Extract walks the proto file and returns all dynamo tags, or the
+// Diagnostics of its invalid annotations.
+func (v *tagExtractor) Extract(f pgs.File) (DynamoTags, error) {
+	v.tags = DynamoTags{}
+	v.diags = nil
+	if err := pgs.Walk(v, f); err != nil {
+		return nil, err
+	}
+	if len(v.diags) > 0 {
+		sort.SliceStable(v.diags, func(i, j int) bool { return v.diags[i].Line < v.diags[j].Line })
+		return nil, v.diags
+	}
+	return v.tags, nil
+}
+
+// buildTagsFromField reads field options and constructs the complete tag
+// string, with the tags of each dialect.
+// Example: `dynamo:"id,hash" index:"username-index,hash" dynamodbav:"id"`
+func buildTagsFromField(f pgs.Field, dialects []TagDialect) string {
+	// Invalid options are reported by validateField
+	keyCfg, _ := getKeyConfig(f)
+	gsis, _ := getGSIs(f)
+	lsis, _ := getLSIs(f)
+	attrCfg, _ := fieldAttributeConfig(f)
 
-	// Check for LSIs (now repeated)
-	if lsis, err := getLSIs(f); err == nil && len(lsis) > 0 {
-		for _, lsi := range lsis {
-			parts = append(parts, buildLSITag(lsi))
+	var parts []string
+	for _, dialect := range dialects {
+		switch dialect {
+		case DialectGuregu:
+			if tagStr := buildKeyTag(keyCfg, attrCfg); tagStr != "" {
+				parts = append(parts, tagStr)
+			}
+			for _, gsi := range gsis {
+				if tagStr := buildGSITag(gsi); tagStr != "" {
+					parts = append(parts, tagStr)
+				}
+			}
+			for _, lsi := range lsis {
+				if tagStr := buildLSITag(lsi); tagStr != "" {
+					parts = append(parts, tagStr)
+				}
+			}
+		case DialectAWSV2:
+			if tagStr := buildAttributeValueTag(keyCfg, attrCfg); tagStr != "" {
+				parts = append(parts, tagStr)
+			}
 		}
 	}
 
@@ -105,6 +497,71 @@ func getGSIs(f pgs.Field) ([]*dynamopb.IndexConfig, error) {
 	return cfgs, nil
 }
 
+func getAttributeConfig(f pgs.Field) (*dynamopb.AttributeConfig, error) {
+	var cfg dynamopb.AttributeConfig
+	ok, err := f.Extension(dynamopb.E_Attribute, &cfg)
+	if err != nil || !ok {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// fieldAttributeConfig returns the attribute options of a field, with the
+// options implied by its other annotations: omitempty for the keys of sparse
+// indexes and for the TTL attribute of the message, and unixtime for a
+// google.protobuf.Timestamp TTL attribute. Nil if the field has none.
+func fieldAttributeConfig(f pgs.Field) (*dynamopb.AttributeConfig, error) {
+	attrCfg, err := getAttributeConfig(f)
+	if err != nil {
+		return nil, err
+	}
+	gsis, err := getGSIs(f)
+	if err != nil {
+		return nil, err
+	}
+	lsis, err := getLSIs(f)
+	if err != nil {
+		return nil, err
+	}
+	sparse := false
+	for _, cfg := range append(gsis, lsis...) {
+		sparse = sparse || cfg.GetSparse()
+	}
+	var tableCfg dynamopb.TableConfig
+	if _, err := f.Message().Extension(dynamopb.E_Table, &tableCfg); err != nil {
+		return nil, err
+	}
+	ttl := tableCfg.GetTtlAttribute() == f.Name().String()
+	if !sparse && !ttl {
+		return attrCfg, nil
+	}
+
+	cfg := &dynamopb.AttributeConfig{}
+	if attrCfg != nil {
+		cfg = proto.Clone(attrCfg).(*dynamopb.AttributeConfig)
+	}
+	cfg.Omitempty = true
+	if ttl && isTimestamp(f) {
+		cfg.Unixtime = true
+	}
+	return cfg, nil
+}
+
+// isTimestamp reports whether a field is a google.protobuf.Timestamp.
+func isTimestamp(f pgs.Field) bool {
+	embed := f.Type().Embed()
+	return embed != nil && embed.FullyQualifiedName() == ".google.protobuf.Timestamp"
+}
+
+func getCompositeKeys(m pgs.Message) ([]*dynamopb.CompositeKeyConfig, error) {
+	var cfgs []*dynamopb.CompositeKeyConfig
+	ok, err := m.Extension(dynamopb.E_CompositeKey, &cfgs)
+	if err != nil || !ok {
+		return nil, err
+	}
+	return cfgs, nil
+}
+
 func getLSIs(f pgs.Field) ([]*dynamopb.IndexConfig, error) {
 	var cfgs []*dynamopb.IndexConfig
 	ok, err := f.Extension(dynamopb.E_Lsi, &cfgs)
@@ -114,23 +571,57 @@ func getLSIs(f pgs.Field) ([]*dynamopb.IndexConfig, error) {
 	return cfgs, nil
 }
 
-func buildKeyTag(cfg *dynamopb.KeyConfig) string {
-	columnName := cfg.ColumnName
+func buildKeyTag(keyCfg *dynamopb.KeyConfig, attrCfg *dynamopb.AttributeConfig) string {
+	opts := []string{keyCfg.GetColumnName()}
 
-	switch cfg.Type {
+	switch keyCfg.GetType() {
 	case dynamopb.KeyType_KEY_TYPE_HASH:
-		return fmt.Sprintf(`dynamo:"%s,hash"`, columnName)
+		opts = append(opts, "hash")
 	case dynamopb.KeyType_KEY_TYPE_RANGE:
-		return fmt.Sprintf(`dynamo:"%s,range"`, columnName)
-	case dynamopb.KeyType_KEY_TYPE_UNSPECIFIED:
-		// Type not specified, just output column name
-		if columnName == "" {
-			return ""
-		}
-		return fmt.Sprintf(`dynamo:"%s"`, columnName)
-	default:
+		opts = append(opts, "range")
+	}
+	if attrCfg.GetOmitempty() {
+		opts = append(opts, "omitempty")
+	}
+	if attrCfg.GetSet() != dynamopb.SetType_SET_TYPE_UNSPECIFIED {
+		opts = append(opts, "set") // guregu infers the set type
+	}
+	if attrCfg.GetUnixtime() {
+		opts = append(opts, "unixtime")
+	}
+
+	// Neither key type, column name nor option: no tag
+	if len(opts) == 1 && opts[0] == "" {
+		return ""
+	}
+	return fmt.Sprintf(`dynamo:"%s"`, strings.Join(opts, ","))
+}
+
+// buildAttributeValueTag builds the dynamodbav tag of the aws-sdk-go-v2
+// attributevalue marshaler, which has no key or index options.
+// Example: `dynamodbav:"email,omitempty"`
+func buildAttributeValueTag(keyCfg *dynamopb.KeyConfig, attrCfg *dynamopb.AttributeConfig) string {
+	opts := []string{keyCfg.GetColumnName()}
+
+	if attrCfg.GetOmitempty() {
+		opts = append(opts, "omitempty")
+	}
+	switch attrCfg.GetSet() {
+	case dynamopb.SetType_SET_TYPE_STRING:
+		opts = append(opts, "stringset")
+	case dynamopb.SetType_SET_TYPE_NUMBER:
+		opts = append(opts, "numberset")
+	case dynamopb.SetType_SET_TYPE_BINARY:
+		opts = append(opts, "binaryset")
+	}
+	if attrCfg.GetUnixtime() {
+		opts = append(opts, "unixtime")
+	}
+
+	if len(opts) == 1 && opts[0] == "" {
 		return ""
 	}
+	return fmt.Sprintf(`dynamodbav:"%s"`, strings.Join(opts, ","))
 }
 
 func buildGSITag(cfg *dynamopb.IndexConfig) string {
@@ -0,0 +1,289 @@
+package godynamo
+
+import (
+	"strings"
+	"testing"
+
+	"google.golang.org/protobuf/types/descriptorpb"
+
+	dynamopb "github.com/getfrontierhq/buf-public-apis/gen/go/dynamo"
+)
+
+// TestDiagnostics generates files with one invalid annotation each.
+func TestDiagnostics(t *testing.T) {
+	tests := []struct {
+		name   string
+		params string
+		file   *descriptorpb.FileDescriptorProto
+		want   string // Expected diagnostic
+	}{
+		{
+			name: "second table hash key",
+			file: protoFile(message("User",
+				key(field("id", str), hash),
+				key(field("email", str), hash),
+			)),
+			want: "store.User.email: the table KEY_TYPE_HASH key is already declared by id",
+		},
+		{
+			name: "range key without hash key",
+			file: protoFile(message("User",
+				key(field("created_at", descriptorpb.FieldDescriptorProto_TYPE_INT64), rangeKey),
+			)),
+			want: "store.User: KEY_TYPE_RANGE key created_at without a KEY_TYPE_HASH key in the message",
+		},
+		{
+			name: "key on a repeated field",
+			file: protoFile(message("User",
+				key(repeated(field("tags", str)), hash),
+			)),
+			want: "store.User.tags: key on a repeated field",
+		},
+		{
+			name: "GSI with an empty name",
+			file: protoFile(message("User",
+				key(field("id", str), hash),
+				withFieldOption(field("email", str), dynamopb.E_Gsi, []*dynamopb.IndexConfig{{Key: hash}}),
+			)),
+			want: "store.User.email: GSI with an empty name",
+		},
+		{
+			name: "GSI without key",
+			file: protoFile(message("User",
+				key(field("id", str), hash),
+				withFieldOption(field("email", str), dynamopb.E_Gsi, []*dynamopb.IndexConfig{{Name: "email-index"}}),
+			)),
+			want: `store.User.email: GSI "email-index" without key`,
+		},
+		{
+			name: "GSI without hash key",
+			file: protoFile(message("User",
+				key(field("id", str), hash),
+				withFieldOption(field("email", str), dynamopb.E_Gsi, []*dynamopb.IndexConfig{{Name: "email-index", Key: rangeKey}}),
+			)),
+			want: `store.User: GSI "email-index" has no KEY_TYPE_HASH key`,
+		},
+		{
+			name: "index declared as a GSI and an LSI",
+			file: protoFile(message("User",
+				key(field("id", str), hash),
+				withFieldOption(field("email", str), dynamopb.E_Gsi, []*dynamopb.IndexConfig{{Name: "by-email", Key: hash}}),
+				withFieldOption(field("name", str), dynamopb.E_Lsi, []*dynamopb.IndexConfig{{Name: "by-email", Key: rangeKey}}),
+			)),
+			want: `store.User.name: index "by-email" is declared both as a GSI and an LSI`,
+		},
+		{
+			name: "LSI without table hash key",
+			file: protoFile(message("User",
+				withFieldOption(field("name", str), dynamopb.E_Lsi, []*dynamopb.IndexConfig{{Name: "name-index", Key: rangeKey}}),
+			)),
+			want: "store.User.name: LSI without a table KEY_TYPE_HASH key",
+		},
+		{
+			name: "include projection without non_key_attributes",
+			file: protoFile(message("User",
+				key(field("id", str), hash),
+				withFieldOption(field("email", str), dynamopb.E_Gsi, []*dynamopb.IndexConfig{
+					{Name: "email-index", Key: hash, ProjectionType: dynamopb.ProjectionType_PROJECTION_TYPE_INCLUDE},
+				}),
+			)),
+			want: `store.User.email: GSI "email-index": PROJECTION_TYPE_INCLUDE without non_key_attributes`,
+		},
+		{
+			name: "non_key_attributes without include projection",
+			file: protoFile(message("User",
+				key(field("id", str), hash),
+				withFieldOption(field("email", str), dynamopb.E_Gsi, []*dynamopb.IndexConfig{
+					{Name: "email-index", Key: hash, NonKeyAttributes: []string{"id"}},
+				}),
+			)),
+			want: `store.User.email: GSI "email-index": non_key_attributes require PROJECTION_TYPE_INCLUDE`,
+		},
+		{
+			name: "non_key_attributes naming no field",
+			file: protoFile(message("User",
+				key(field("id", str), hash),
+				withFieldOption(field("email", str), dynamopb.E_Gsi, []*dynamopb.IndexConfig{
+					{Name: "email-index", Key: hash, ProjectionType: dynamopb.ProjectionType_PROJECTION_TYPE_INCLUDE, NonKeyAttributes: []string{"nickname"}},
+				}),
+			)),
+			want: `store.User.email: index "email-index": non_key_attributes: no field nickname`,
+		},
+		{
+			name: "sparse GSI on a table key",
+			file: protoFile(message("User",
+				withFieldOption(key(field("id", str), hash), dynamopb.E_Gsi, []*dynamopb.IndexConfig{{Name: "id-index", Key: hash, Sparse: true}}),
+			)),
+			want: `store.User.id: sparse GSI "id-index" on a table key field`,
+		},
+		{
+			name: "set on a non-repeated field",
+			file: protoFile(message("User",
+				key(field("id", str), hash),
+				withFieldOption(field("tag", str), dynamopb.E_Attribute, &dynamopb.AttributeConfig{Set: dynamopb.SetType_SET_TYPE_STRING}),
+			)),
+			want: "store.User.tag: SET_TYPE_STRING on a non-repeated field",
+		},
+		{
+			name: "string set on numbers",
+			file: protoFile(message("User",
+				key(field("id", str), hash),
+				withFieldOption(repeated(field("scores", descriptorpb.FieldDescriptorProto_TYPE_INT64)), dynamopb.E_Attribute, &dynamopb.AttributeConfig{Set: dynamopb.SetType_SET_TYPE_STRING}),
+			)),
+			want: "store.User.scores: SET_TYPE_STRING on a repeated TYPE_INT64 field",
+		},
+		{
+			name:   "unixtime on an integer",
+			params: "marshal=true",
+			file: protoFile(message("Event",
+				key(field("id", str), hash),
+				withFieldOption(field("at", descriptorpb.FieldDescriptorProto_TYPE_INT64), dynamopb.E_Attribute, &dynamopb.AttributeConfig{Unixtime: true}),
+			)),
+			want: "store.Event.at: unixtime on a field other than google.protobuf.Timestamp",
+		},
+		{
+			name: "version on a string",
+			file: protoFile(message("Session",
+				key(field("token", str), hash),
+				withFieldOption(field("version", str), dynamopb.E_Attribute, &dynamopb.AttributeConfig{Version: true}),
+			)),
+			want: "store.Session.version: version on a non-integer field",
+		},
+		{
+			name: "version on a key",
+			file: protoFile(message("Session",
+				withFieldOption(key(field("version", descriptorpb.FieldDescriptorProto_TYPE_INT64), hash), dynamopb.E_Attribute, &dynamopb.AttributeConfig{Version: true}),
+			)),
+			want: "store.Session.version: version on a key field",
+		},
+		{
+			name: "second version field",
+			file: protoFile(message("Session",
+				key(field("token", str), hash),
+				withFieldOption(field("version", descriptorpb.FieldDescriptorProto_TYPE_INT64), dynamopb.E_Attribute, &dynamopb.AttributeConfig{Version: true}),
+				withFieldOption(field("revision", descriptorpb.FieldDescriptorProto_TYPE_INT64), dynamopb.E_Attribute, &dynamopb.AttributeConfig{Version: true}),
+			)),
+			want: "store.Session.revision: second version field, the version is version",
+		},
+		{
+			name: "composite key without key type",
+			file: protoFile(withMessageOption(message("Order",
+				field("user_id", str),
+			), dynamopb.E_CompositeKey, []*dynamopb.CompositeKeyConfig{
+				{Name: "PK", Template: "USER#{user_id}"},
+			})),
+			want: `store.Order: composite key "PK": no key, use KEY_TYPE_HASH or KEY_TYPE_RANGE`,
+		},
+		{
+			name: "composite key on a missing field",
+			file: protoFile(withMessageOption(message("Order",
+				field("user_id", str),
+			), dynamopb.E_CompositeKey, []*dynamopb.CompositeKeyConfig{
+				{Name: "PK", Template: "USER#{owner_id}", Key: hash},
+			})),
+			want: `store.Order: composite key "PK": no field owner_id`,
+		},
+		{
+			name: "unixtime without marshal",
+			file: protoFile(message("Event",
+				key(field("id", str), hash),
+				withFieldOption(timestampField("at"), dynamopb.E_Attribute, &dynamopb.AttributeConfig{Unixtime: true}),
+			)),
+			want: "store.Event.at: unixtime requires marshal=true",
+		},
+		{
+			name: "timestamp ttl_attribute without marshal",
+			file: protoFile(withMessageOption(message("Session",
+				key(field("token", str), hash),
+				timestampField("expires"),
+			), dynamopb.E_Table, &dynamopb.TableConfig{Name: "sessions", TtlAttribute: "expires"})),
+			want: "store.Session.expires: a google.protobuf.Timestamp ttl_attribute requires marshal=true",
+		},
+		{
+			name: "projection declared by two index keys",
+			file: protoFile(message("User",
+				key(field("id", str), hash),
+				withFieldOption(field("email", str), dynamopb.E_Gsi, []*dynamopb.IndexConfig{
+					{Name: "email-index", Key: hash, ProjectionType: dynamopb.ProjectionType_PROJECTION_TYPE_KEYS_ONLY},
+				}),
+				withFieldOption(field("name", str), dynamopb.E_Gsi, []*dynamopb.IndexConfig{
+					{Name: "email-index", Key: rangeKey, ProjectionType: dynamopb.ProjectionType_PROJECTION_TYPE_ALL},
+				}),
+			)),
+			want: `store.User.name: the projection of index "email-index" is already declared by email`,
+		},
+		{
+			name: "projection declared by an index key and the table",
+			file: protoFile(withMessageOption(message("User",
+				key(field("id", str), hash),
+				withFieldOption(field("email", str), dynamopb.E_Gsi, []*dynamopb.IndexConfig{
+					{Name: "email-index", Key: hash, ProjectionType: dynamopb.ProjectionType_PROJECTION_TYPE_KEYS_ONLY},
+				}),
+			), dynamopb.E_Table, &dynamopb.TableConfig{
+				Name:    "users",
+				Indexes: []*dynamopb.IndexSettings{{Name: "email-index", ProjectionType: dynamopb.ProjectionType_PROJECTION_TYPE_ALL}},
+			})),
+			want: `store.User.email: the projection of index "email-index" is already declared by the indexes of dynamo.table`,
+		},
+		{
+			name: "key on a bool field",
+			file: protoFile(message("Flag",
+				key(field("enabled", descriptorpb.FieldDescriptorProto_TYPE_BOOL), hash),
+			)),
+			want: "store.Flag.enabled: key on a bool field",
+		},
+		{
+			name: "index key on a floating-point field",
+			file: protoFile(message("Score",
+				key(field("id", str), hash),
+				withFieldOption(field("value", descriptorpb.FieldDescriptorProto_TYPE_DOUBLE), dynamopb.E_Gsi, []*dynamopb.IndexConfig{
+					{Name: "value-index", Key: hash},
+				}),
+			)),
+			want: "store.Score.value: key on a floating-point field",
+		},
+		{
+			name: "composite key on an optional field",
+			file: protoFile(withMessageOption(message("Order",
+				optional(field("user_id", str)),
+			), dynamopb.E_CompositeKey, []*dynamopb.CompositeKeyConfig{
+				{Name: "PK", Template: "USER#{user_id}", Key: hash},
+			})),
+			want: `store.Order: composite key "PK": field user_id has presence (optional or oneof), use a plain field`,
+		},
+		{
+			name: "composite key on a oneof member",
+			file: protoFile(withMessageOption(oneof(message("Order",
+				field("user_id", str),
+				field("team_id", str),
+			), "owner", "user_id", "team_id"), dynamopb.E_CompositeKey, []*dynamopb.CompositeKeyConfig{
+				{Name: "PK", Template: "OWNER#{team_id}", Key: hash},
+			})),
+			want: `store.Order: composite key "PK": field team_id has presence (optional or oneof), use a plain field`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := generate(t, tt.params, tt.file)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("generate() = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+// TestUnixtimeWithMarshal checks unixtime is valid with the item marshalers.
+func TestUnixtimeWithMarshal(t *testing.T) {
+	file := protoFile(message("Event",
+		key(field("id", str), hash),
+		withFieldOption(timestampField("at"), dynamopb.E_Attribute, &dynamopb.AttributeConfig{Unixtime: true}),
+	))
+	files, err := generate(t, "marshal=true", file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if item := files["store/store_dynamo_item.pb.go"]; !strings.Contains(item, `{Proto: "at", Name: "At", UnixTime: true}`) {
+		t.Errorf("item marshalers without the unixtime field:\n%s", item)
+	}
+}
@@ -0,0 +1,258 @@
+package godynamo
+
+import (
+	"fmt"
+	"go/token"
+	"sort"
+	"strings"
+	"text/template"
+
+	pgs "github.com/lyft/protoc-gen-star/v2"
+	pgsgo "github.com/lyft/protoc-gen-star/v2/lang/go"
+
+	"github.com/getfrontierhq/buf-public-apis/pkg/godynamo/compositekey"
+)
+
+// KeysMessage is a message with (dynamo.composite_key) attributes.
+type KeysMessage struct {
+	Name    string          // Go type name (e.g., "Order")
+	Keys    []*CompositeKey // Composite keys, in declaration order
+	Queries []KeyQuery      // Key conditions of the table and indexes with a composite hash key
+}
+
+// CompositeKey is a (dynamo.composite_key) attribute of a message.
+// Example: {Name: "PK", GoName: "PK", Template: "USER#{user_id}"}
+type CompositeKey struct {
+	Name     string     // Attribute name (e.g., "GSI1PK")
+	GoName   string     // Attribute name as a Go identifier (e.g., "GSI1PK")
+	Template string     // Key template (e.g., "ORDER#{created_at:20}")
+	Prefix   string     // Literal prefix of the template (e.g., "ORDER#")
+	Params   []KeyParam // Parameters of the typed key function, one per field
+	Args     []string   // Parameter names in template order (fields may repeat)
+}
+
+// KeyParam is a template field as a parameter of a typed key function.
+type KeyParam struct {
+	Name   string // Parameter name (e.g., "createdAt")
+	GoType string // Go field type (e.g., "int64")
+}
+
+// KeyQuery is the key condition builder of the table (Index "") or of an index.
+type KeyQuery struct {
+	Index    string        // Index name ("" for the table)
+	GoName   string        // Index name as a Go identifier ("" for the table)
+	HashKey  *CompositeKey // Composite hash key
+	RangeKey *CompositeKey // Composite range key with a literal prefix (nil if none)
+}
+
+// KeysData is the data of the keys template of a proto file.
+type KeysData struct {
+	Source   string
+	Package  string
+	Imports  []CompanionImport
+	Messages []*KeysMessage
+}
+
+// extractKeys returns the composite keys of the messages of a file.
+func extractKeys(ctx pgsgo.Context, f pgs.File) (*KeysData, error) {
+	tables, err := extractTables(ctx, f)
+	if err != nil {
+		return nil, err
+	}
+	tablesByName := map[string]*Table{}
+	for _, table := range tables {
+		tablesByName[table.Name] = table
+	}
+
+	data := &KeysData{
+		Source:  f.InputPath().String(),
+		Package: ctx.PackageName(f).String(),
+	}
+	imports := map[string]string{}
+
+	for _, msg := range f.AllMessages() {
+		cfgs, err := getCompositeKeys(msg)
+		if err != nil {
+			return nil, fmt.Errorf("%s: invalid dynamo.composite_key option: %w", msg.FullyQualifiedName(), err)
+		}
+		if len(cfgs) == 0 {
+			continue
+		}
+
+		message := &KeysMessage{Name: ctx.Name(msg).String()}
+		keys := map[string]*CompositeKey{}
+		for _, cfg := range cfgs {
+			tmpl, err := compositekey.Parse(cfg.GetTemplate())
+			if err != nil {
+				return nil, fmt.Errorf("%s: composite key %q: %w", msg.FullyQualifiedName(), cfg.GetName(), err)
+			}
+			key := &CompositeKey{
+				Name:     cfg.GetName(),
+				GoName:   goIdentifier(cfg.GetName()),
+				Template: cfg.GetTemplate(),
+				Prefix:   tmpl.Prefix(),
+			}
+			params := map[string]string{}
+			for _, name := range tmpl.Fields() {
+				fd := fieldByName(msg, name)
+				if fd == nil {
+					return nil, fmt.Errorf("%s: composite key %q: unknown field %s", msg.FullyQualifiedName(), cfg.GetName(), name)
+				}
+				param, ok := params[name]
+				if !ok {
+					param = paramName(fd)
+					params[name] = param
+					key.Params = append(key.Params, KeyParam{Name: param, GoType: ctx.Type(fd).String()})
+					for _, e := range fieldTypeEntities(fd) {
+						if ctx.ImportPath(e) != ctx.ImportPath(f) {
+							imports[ctx.ImportPath(e).String()] = ctx.PackageName(e).String()
+						}
+					}
+				}
+				key.Args = append(key.Args, param)
+			}
+			keys[key.Name] = key
+			message.Keys = append(message.Keys, key)
+		}
+
+		if table := tablesByName[message.Name]; table != nil {
+			message.Queries = keyQueries(table, keys)
+		}
+		data.Messages = append(data.Messages, message)
+	}
+
+	for path, alias := range imports {
+		data.Imports = append(data.Imports, CompanionImport{Alias: alias, Path: path})
+	}
+	sort.Slice(data.Imports, func(i, j int) bool { return data.Imports[i].Path < data.Imports[j].Path })
+	return data, nil
+}
+
+// keyQueries returns the key conditions of the table and indexes of a message
+// with a composite hash key.
+func keyQueries(table *Table, keys map[string]*CompositeKey) []KeyQuery {
+	composite := func(attr *Attribute) *CompositeKey {
+		if attr == nil || attr.Template == "" {
+			return nil
+		}
+		return keys[attr.Name]
+	}
+	prefixed := func(attr *Attribute) *CompositeKey {
+		if key := composite(attr); key != nil && key.Prefix != "" {
+			return key
+		}
+		return nil
+	}
+
+	var queries []KeyQuery
+	if hashKey := composite(table.HashKey); hashKey != nil {
+		queries = append(queries, KeyQuery{HashKey: hashKey, RangeKey: prefixed(table.RangeKey)})
+	}
+	for _, index := range table.Indexes {
+		if hashKey := composite(index.HashKey); hashKey != nil {
+			queries = append(queries, KeyQuery{Index: index.Name, GoName: index.GoName, HashKey: hashKey, RangeKey: prefixed(index.RangeKey)})
+		}
+	}
+	return queries
+}
+
+// fieldByName returns the field of a message with the given proto name, or nil.
+func fieldByName(msg pgs.Message, name string) pgs.Field {
+	for _, fd := range msg.Fields() {
+		if fd.Name().String() == name {
+			return fd
+		}
+	}
+	return nil
+}
+
+// paramName returns the Go parameter name of a field: its lowerCamelCase name,
+// with a "_" suffix for keywords.
+// Example: "created_at" -> "createdAt", "type" -> "type_"
+func paramName(fd pgs.Field) string {
+	name := fd.Name().LowerCamelCase().String()
+	if token.IsKeyword(name) {
+		name += "_"
+	}
+	return name
+}
+
+// keysFileName returns the name of the keys file generated next to a .pb.go file.
+// Example: "orders/v1/orders.pb.go" -> "orders/v1/orders_dynamo_keys.pb.go"
+func keysFileName(pbFile string) string {
+	return strings.TrimSuffix(pbFile, ".pb.go") + "_dynamo_keys.pb.go"
+}
+
+// keysTemplate generates the composite key functions of a file, backed by
+// compositekey. For each (dynamo.composite_key) attribute of a message:
+//   - (*<Msg>).Dynamo<Key>, the attribute of a message
+//   - (*<Msg>).ParseDynamo<Key>, which sets the template fields from an attribute
+//   - <Msg>Dynamo<Key>, the attribute of typed field values
+//
+// For the table and each index with a composite hash key, <Msg>DynamoQuery
+// and <Msg>DynamoQuery<Index> return its key condition. The condition has a
+// begins_with on the literal prefix of a composite range key, which selects
+// the items of the message in a single-table design.
+//
+// Example:
+//
+//	pk := pb.OrderDynamoPK("u1") // "USER#u1"
+//	out, err := client.Query(ctx, pb.OrderDynamoQuery("u1").QueryInput("app"))
+var keysTemplate = template.Must(template.New("keys").Funcs(template.FuncMap{
+	"params": func(key *CompositeKey) string {
+		params := make([]string, len(key.Params))
+		for i, p := range key.Params {
+			params[i] = p.Name + " " + p.GoType
+		}
+		return strings.Join(params, ", ")
+	},
+	"args": func(key *CompositeKey) string {
+		return strings.Join(key.Args, ", ")
+	},
+}).Parse(`// Code generated by protoc-gen-go-dynamo. DO NOT EDIT.
+// source: {{.Source}}
+
+package {{.Package}}
+
+import (
+	"github.com/getfrontierhq/buf-public-apis/pkg/godynamo/compositekey"
+{{- range .Imports}}
+	{{.Alias}} "{{.Path}}"
+{{- end}}
+)
+{{range .Messages}}{{$m := .}}
+// Composite key templates of {{.Name}} items.
+var (
+{{- range .Keys}}
+	dynamoKey{{$m.Name}}{{.GoName}} = compositekey.MustParse({{printf "%q" .Template}})
+{{- end}}
+)
+{{range .Keys}}
+// Dynamo{{.GoName}} returns the {{.Name}} attribute of x: {{.Template}}.
+func (x *{{$m.Name}}) Dynamo{{.GoName}}() string {
+	return dynamoKey{{$m.Name}}{{.GoName}}.Format(x)
+}
+
+// ParseDynamo{{.GoName}} sets the fields of x from its {{.Name}} attribute.
+func (x *{{$m.Name}}) ParseDynamo{{.GoName}}(value string) error {
+	return dynamoKey{{$m.Name}}{{.GoName}}.Parse(value, x)
+}
+
+// {{$m.Name}}Dynamo{{.GoName}} returns the {{.Name}} attribute of {{$m.Name}} items with the given fields.
+func {{$m.Name}}Dynamo{{.GoName}}({{params .}}) string {
+	return dynamoKey{{$m.Name}}{{.GoName}}.FormatValues({{args .}})
+}
+{{end}}
+{{- range .Queries}}
+// {{$m.Name}}DynamoQuery{{.GoName}} returns the key condition of the {{$m.Name}} items {{if .Index}}of the {{.Index}} index {{end}}with the given {{.HashKey.Name}} fields{{if .RangeKey}}, with a {{.RangeKey.Name}} starting with {{printf "%q" .RangeKey.Prefix}}{{end}}.
+func {{$m.Name}}DynamoQuery{{.GoName}}({{params .HashKey}}) compositekey.KeyCondition {
+	return compositekey.KeyCondition{
+{{- if .Index}}
+		Index:     {{printf "%q" .Index}},
+{{- end}}
+		HashName:  {{printf "%q" .HashKey.Name}},
+		HashValue: {{$m.Name}}Dynamo{{.HashKey.GoName}}({{args .HashKey}}),
+	}{{if .RangeKey}}.Range({{printf "%q" .RangeKey.Name}}, compositekey.BeginsWith, {{printf "%q" .RangeKey.Prefix}}){{end}}
+}
+{{end}}
+{{- end}}`))
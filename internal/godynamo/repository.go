@@ -0,0 +1,308 @@
+package godynamo
+
+import (
+	"strings"
+	"text/template"
+)
+
+// RepositoryData is the data of the repository template of a proto file.
+type RepositoryData struct {
+	Source  string   // Proto file path (e.g., "users/v1/users.proto")
+	Package string   // Go package name
+	Tables  []*Table // Tables of the file
+	Marshal bool     // Items have the marshalers of itemTemplate (marshal=true)
+}
+
+// Versioned reports whether a table of the file has a version field.
+func (d RepositoryData) Versioned() bool {
+	for _, table := range d.Tables {
+		if table.Version != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// repositoryFileName returns the name of the repository file generated next
+// to a .pb.go file.
+// Example: "users/v1/users.pb.go" -> "users/v1/users_dynamo_repo.pb.go"
+func repositoryFileName(pbFile string) string {
+	return strings.TrimSuffix(pbFile, ".pb.go") + "_dynamo_repo.pb.go"
+}
+
+// repositoryTemplate generates the typed repositories of a file (repository=true).
+//
+// For each table:
+//   - a <Msg>Repository interface with Get, Put, Delete, Update and QueryBy<Index>
+//   - <Msg>DynamoRepository, implemented with guregu/dynamo/v2
+//   - <Msg>MemoryRepository, an in-memory fake backed by memtable
+//
+// Put and Update of tables with a version field are conditional writes: they
+// fail with a *version.ConflictError unless the stored version of the item is
+// the version of the given item, and increment the version.
+//
+// Example:
+//
+//	repo := pb.NewUserDynamoRepository(db.Table("users"))
+//	user, err := repo.Get(ctx, "u1", 1700000000)
+//	users, err := repo.QueryByEmailIndex(ctx, "a@example.com")
+var repositoryTemplate = template.Must(template.New("repository").Funcs(template.FuncMap{
+	"keyParams": keyParams,
+	"keyArgs":   keyArgs,
+	"rangeName": func(attr *Attribute) string {
+		if attr == nil {
+			return ""
+		}
+		return attr.ProtoName
+	},
+}).Parse(`// Code generated by protoc-gen-go-dynamo. DO NOT EDIT.
+// source: {{.Source}}
+
+package {{.Package}}
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/guregu/dynamo/v2"
+{{- if not .Marshal}}
+	"google.golang.org/protobuf/reflect/protoreflect"
+{{- end}}
+
+	"github.com/getfrontierhq/buf-public-apis/pkg/godynamo/memtable"
+{{- if .Versioned}}
+	"github.com/getfrontierhq/buf-public-apis/pkg/godynamo/version"
+{{- end}}
+)
+{{range .Tables}}{{$t := .}}
+// {{.Name}}Repository stores {{.Name}} items in a DynamoDB table.
+type {{.Name}}Repository interface {
+	// Get returns the item with the given key, or dynamo.ErrNotFound.
+	Get(ctx context.Context, {{keyParams .}}) (*{{.Name}}, error)
+
+	// Put creates or replaces an item.{{with .Version}} It fails with a
+	// *version.ConflictError unless the stored {{.ProtoName}} is the
+	// {{.ProtoName}} of item, which is then incremented.{{end}}
+	Put(ctx context.Context, item *{{.Name}}) error
+
+	// Delete removes the item with the given key, if any.
+	Delete(ctx context.Context, {{keyParams .}}) error
+
+	// Update sets the given fields (proto names) of item on the item with the
+	// same key, creating it if needed, and returns the updated item. Fields
+	// with presence (optional, oneof, messages) unset in item are removed{{if $.Marshal}},
+	// as are the empty fields the item marshalers omit{{end}}. Key fields cannot
+	// be updated.{{with .Version}}
+	// It fails with a *version.ConflictError unless the stored {{.ProtoName}}
+	// is the {{.ProtoName}} of item, and increments it.{{end}}
+	Update(ctx context.Context, item *{{.Name}}, fields ...string) (*{{.Name}}, error)
+{{range .Indexes}}
+	// QueryBy{{.GoName}} returns the items of the {{.Name}} index with the given
+	// hash key{{if .RangeKey}}, sorted by {{.RangeKey.ProtoName}}{{end}}.
+	QueryBy{{.GoName}}(ctx context.Context, hashKey {{.HashKey.GoType}}) ([]*{{$t.Name}}, error)
+{{end}}}
+
+var (
+	_ {{.Name}}Repository = (*{{.Name}}DynamoRepository)(nil)
+	_ {{.Name}}Repository = (*{{.Name}}MemoryRepository)(nil)
+)
+
+// {{.Name}}DynamoRepository is the {{.Name}}Repository of a DynamoDB table.
+type {{.Name}}DynamoRepository struct {
+	table dynamo.Table
+}
+
+// New{{.Name}}DynamoRepository returns the repository of the given table.
+func New{{.Name}}DynamoRepository(table dynamo.Table) *{{.Name}}DynamoRepository {
+	return &{{.Name}}DynamoRepository{table: table}
+}
+
+// Get implements {{.Name}}Repository.
+func (r *{{.Name}}DynamoRepository) Get(ctx context.Context, {{keyParams .}}) (*{{.Name}}, error) {
+	item := &{{.Name}}{}
+	query := r.table.Get("{{.HashKey.Name}}", hashKey){{if .RangeKey}}.Range("{{.RangeKey.Name}}", dynamo.Equal, rangeKey){{end}}
+	if err := query.One(ctx, item); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// Put implements {{.Name}}Repository.
+func (r *{{.Name}}DynamoRepository) Put(ctx context.Context, item *{{.Name}}) error {
+{{- with .Version}}
+	// Put marshals item, so the incremented version is set first
+	expected := item.Get{{.GoName}}()
+	item.{{.GoName}} = expected + 1
+	put := r.table.Put(item)
+	if expected == 0 {
+		put.If("attribute_not_exists($) OR $ = ?", "{{.Name}}", "{{.Name}}", expected)
+	} else {
+		put.If("$ = ?", "{{.Name}}", expected)
+	}
+	if err := put.Run(ctx); err != nil {
+		item.{{.GoName}} = expected
+		return version.Check(err, "{{.Name}}", int64(expected))
+	}
+	return nil
+{{- else}}
+	return r.table.Put(item).Run(ctx)
+{{- end}}
+}
+
+// Delete implements {{.Name}}Repository.
+func (r *{{.Name}}DynamoRepository) Delete(ctx context.Context, {{keyParams .}}) error {
+	return r.table.Delete("{{.HashKey.Name}}", hashKey){{if .RangeKey}}.Range("{{.RangeKey.Name}}", rangeKey){{end}}.Run(ctx)
+}
+
+// Update implements {{.Name}}Repository.
+func (r *{{.Name}}DynamoRepository) Update(ctx context.Context, item *{{.Name}}, fields ...string) (*{{.Name}}, error) {
+	update := r.table.Update("{{.HashKey.Name}}", item.Get{{.HashKey.GoName}}()){{if .RangeKey}}.Range("{{.RangeKey.Name}}", item.Get{{.RangeKey.GoName}}()){{end}}
+{{- with .Version}}
+	expected := item.Get{{.GoName}}()
+	if expected == 0 {
+		update.If("attribute_not_exists($) OR $ = ?", "{{.Name}}", "{{.Name}}", expected)
+	} else {
+		update.If("$ = ?", "{{.Name}}", expected)
+	}
+	update.Set("{{.Name}}", expected+1)
+{{- end}}
+{{- if $.Marshal}}
+	// Values are encoded like Put encodes them
+	attrs, err := item.MarshalDynamoItem()
+	if err != nil {
+		return nil, err
+	}
+{{- else}}
+	msg := item.ProtoReflect()
+{{- end}}
+	for _, field := range fields {
+{{- with .Version}}
+		if field == "{{.ProtoName}}" {
+			continue
+		}
+{{- end}}
+{{- if $.Marshal}}
+		name, _, err := {{.Name}}DynamoAttribute(item, field)
+		if err != nil {
+			return nil, err
+		}
+		if value, ok := attrs[name]; ok {
+			update.Set(name, value)
+		} else {
+			update.Remove(name)
+		}
+{{- else}}
+		name, value, err := {{.Name}}DynamoAttribute(item, field)
+		if err != nil {
+			return nil, err
+		}
+		if fd := msg.Descriptor().Fields().ByName(protoreflect.Name(field)); fd.HasPresence() && !msg.Has(fd) {
+			update.Remove(name)
+		} else {
+			update.Set(name, value)
+		}
+{{- end}}
+	}
+
+	updated := &{{.Name}}{}
+	if err := update.Value(ctx, updated); err != nil {
+		return nil, {{with .Version}}version.Check(err, "{{.Name}}", int64(expected)){{else}}err{{end}}
+	}
+	return updated, nil
+}
+{{range .Indexes}}
+// QueryBy{{.GoName}} implements {{$t.Name}}Repository.
+func (r *{{$t.Name}}DynamoRepository) QueryBy{{.GoName}}(ctx context.Context, hashKey {{.HashKey.GoType}}) ([]*{{$t.Name}}, error) {
+	var items []*{{$t.Name}}
+	if err := r.table.Get("{{.HashKey.Name}}", hashKey).Index("{{.Name}}").All(ctx, &items); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+{{end}}
+// {{.Name}}MemoryRepository is an in-memory {{.Name}}Repository, for tests.
+type {{.Name}}MemoryRepository struct {
+	table *memtable.Table[*{{.Name}}]
+}
+
+// New{{.Name}}MemoryRepository returns an empty in-memory repository.
+func New{{.Name}}MemoryRepository() *{{.Name}}MemoryRepository {
+	return &{{.Name}}MemoryRepository{table: memtable.New[*{{.Name}}]("{{.HashKey.ProtoName}}", "{{rangeName .RangeKey}}")}
+}
+
+// Get implements {{.Name}}Repository.
+func (r *{{.Name}}MemoryRepository) Get(ctx context.Context, {{keyParams .}}) (*{{.Name}}, error) {
+	item, ok := r.table.Get({{keyArgs .}})
+	if !ok {
+		return nil, dynamo.ErrNotFound
+	}
+	return item, nil
+}
+
+// Put implements {{.Name}}Repository.
+func (r *{{.Name}}MemoryRepository) Put(ctx context.Context, item *{{.Name}}) error {
+{{- with .Version}}
+	stored, err := r.table.PutVersion(item, "{{.ProtoName}}")
+	if err != nil {
+		return err
+	}
+	item.{{.GoName}} = stored.Get{{.GoName}}()
+{{- else}}
+	r.table.Put(item)
+{{- end}}
+	return nil
+}
+
+// Delete implements {{.Name}}Repository.
+func (r *{{.Name}}MemoryRepository) Delete(ctx context.Context, {{keyParams .}}) error {
+	r.table.Delete({{keyArgs .}})
+	return nil
+}
+
+// Update implements {{.Name}}Repository.
+func (r *{{.Name}}MemoryRepository) Update(ctx context.Context, item *{{.Name}}, fields ...string) (*{{.Name}}, error) {
+	for _, field := range fields {
+		if _, _, err := {{.Name}}DynamoAttribute(item, field); err != nil {
+			return nil, err
+		}
+	}
+	return r.table.{{with .Version}}UpdateVersion(item, "{{.ProtoName}}", {{else}}Update(item, {{end}}fields...)
+}
+{{range .Indexes}}
+// QueryBy{{.GoName}} implements {{$t.Name}}Repository.
+func (r *{{$t.Name}}MemoryRepository) QueryBy{{.GoName}}(ctx context.Context, hashKey {{.HashKey.GoType}}) ([]*{{$t.Name}}, error) {
+	return r.table.Query("{{.HashKey.ProtoName}}", hashKey, "{{rangeName .RangeKey}}"), nil
+}
+{{end}}
+// {{.Name}}DynamoAttribute returns the attribute name and value of a non-key
+// field (proto name) of item.
+func {{.Name}}DynamoAttribute(item *{{.Name}}, field string) (string, any, error) {
+	switch field {
+{{range .Attributes}}	case "{{.ProtoName}}":
+		return "{{.Name}}", item.Get{{.GoName}}(), nil
+{{end}}	case "{{.HashKey.ProtoName}}"{{if .RangeKey}}, "{{.RangeKey.ProtoName}}"{{end}}:
+		return "", nil, fmt.Errorf("key field %q cannot be updated", field)
+	default:
+		return "", nil, fmt.Errorf("{{.Name}} has no field %q", field)
+	}
+}
+{{end}}`))
+
+// keyParams returns the key parameters of the Get and Delete methods of a table.
+// Example: "hashKey string, rangeKey int64"
+func keyParams(t *Table) string {
+	params := "hashKey " + t.HashKey.GoType
+	if t.RangeKey != nil {
+		params += ", rangeKey " + t.RangeKey.GoType
+	}
+	return params
+}
+
+// keyArgs returns the memtable key arguments of a table.
+// Example: "hashKey, rangeKey", or "hashKey, nil" without range key
+func keyArgs(t *Table) string {
+	if t.RangeKey == nil {
+		return "hashKey, nil"
+	}
+	return "hashKey, rangeKey"
+}
@@ -0,0 +1,474 @@
+package godynamo
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/token"
+	"strings"
+	"text/template"
+
+	pgs "github.com/lyft/protoc-gen-star/v2"
+
+	dynamopb "github.com/getfrontierhq/buf-public-apis/gen/go/dynamo"
+)
+
+// Schema is the DynamoDB definition of a table, built from its (dynamo.table)
+// option and its key annotations. Values use the DynamoDB API names
+// (e.g., "PAY_PER_REQUEST", "HASH", "KEYS_ONLY").
+type Schema struct {
+	TableName              string
+	BillingMode            string
+	Throughput             *Throughput // nil with PAY_PER_REQUEST
+	TTLAttribute           string      // Attribute name ("" without TTL)
+	AttributeDefinitions   []AttributeDefinition
+	KeySchema              []KeySchemaElement
+	GlobalSecondaryIndexes []IndexSchema
+	LocalSecondaryIndexes  []IndexSchema
+}
+
+// AttributeDefinition is the type of a key attribute: "S", "N" or "B".
+type AttributeDefinition struct {
+	Name string
+	Type string
+}
+
+// KeySchemaElement is a key attribute with its key type: "HASH" or "RANGE".
+type KeySchemaElement struct {
+	Name    string
+	KeyType string
+}
+
+// IndexSchema is the definition of a secondary index.
+type IndexSchema struct {
+	Name             string
+	KeySchema        []KeySchemaElement
+	ProjectionType   string
+	NonKeyAttributes []string
+	Throughput       *Throughput // nil for LSIs and with PAY_PER_REQUEST
+}
+
+// Throughput is a provisioned capacity.
+type Throughput struct {
+	Read  int64
+	Write int64
+}
+
+// buildSchema builds the schema of a table from its configuration.
+func buildSchema(table *Table, cfg *dynamopb.TableConfig) (*Schema, error) {
+	if cfg.GetName() == "" {
+		return nil, fmt.Errorf("table name is required")
+	}
+
+	schema := &Schema{
+		TableName:   cfg.GetName(),
+		BillingMode: "PAY_PER_REQUEST",
+		KeySchema:   keySchema(table.HashKey, table.RangeKey),
+	}
+
+	if cfg.GetBillingMode() == dynamopb.BillingMode_BILLING_MODE_PROVISIONED {
+		schema.BillingMode = "PROVISIONED"
+		schema.Throughput = throughput(cfg.GetThroughput())
+		if schema.Throughput == nil {
+			return nil, fmt.Errorf("throughput is required with BILLING_MODE_PROVISIONED")
+		}
+	}
+
+	if name := cfg.GetTtlAttribute(); name != "" {
+		attr := table.attribute(name)
+		if attr == nil {
+			return nil, fmt.Errorf("ttl_attribute: no field %q", name)
+		}
+		if !isIntegerType(attr.GoType) && attr.GoType != "*timestamppb.Timestamp" {
+			return nil, fmt.Errorf("ttl_attribute: field %q must be an integer (Unix seconds) or a google.protobuf.Timestamp, not %s", name, attr.GoType)
+		}
+		schema.TTLAttribute = attr.Name
+	}
+
+	// Key attributes must be defined once, with their type
+	defined := map[string]bool{}
+	define := func(attr *Attribute) {
+		if attr != nil && !defined[attr.Name] {
+			defined[attr.Name] = true
+			schema.AttributeDefinitions = append(schema.AttributeDefinitions, AttributeDefinition{
+				Name: attr.Name,
+				Type: attributeType(attr.GoType),
+			})
+		}
+	}
+	define(table.HashKey)
+	define(table.RangeKey)
+
+	settings := map[string]*dynamopb.IndexSettings{}
+	for _, s := range cfg.GetIndexes() {
+		settings[s.GetName()] = s
+	}
+
+	for _, index := range table.Indexes {
+		define(index.HashKey)
+		define(index.RangeKey)
+
+		indexSchema := IndexSchema{
+			Name:           index.Name,
+			KeySchema:      keySchema(index.HashKey, index.RangeKey),
+			ProjectionType: "ALL",
+		}
+
+		s := settings[index.Name]
+		delete(settings, index.Name)
+		projectionType, nonKeyAttributes := s.GetProjectionType(), s.GetNonKeyAttributes()
+		if index.ProjectionType != dynamopb.ProjectionType_PROJECTION_TYPE_UNSPECIFIED {
+			if projectionType != dynamopb.ProjectionType_PROJECTION_TYPE_UNSPECIFIED || len(nonKeyAttributes) > 0 {
+				return nil, fmt.Errorf("index %q: projection is declared both by the index keys and by indexes", index.Name)
+			}
+			projectionType, nonKeyAttributes = index.ProjectionType, index.NonKeyAttributes
+		}
+		switch projectionType {
+		case dynamopb.ProjectionType_PROJECTION_TYPE_KEYS_ONLY:
+			indexSchema.ProjectionType = "KEYS_ONLY"
+		case dynamopb.ProjectionType_PROJECTION_TYPE_INCLUDE:
+			indexSchema.ProjectionType = "INCLUDE"
+			if len(nonKeyAttributes) == 0 {
+				return nil, fmt.Errorf("index %q: non_key_attributes are required with PROJECTION_TYPE_INCLUDE", index.Name)
+			}
+			for _, name := range nonKeyAttributes {
+				attr := table.attribute(name)
+				if attr == nil {
+					return nil, fmt.Errorf("index %q: non_key_attributes: no field %q", index.Name, name)
+				}
+				indexSchema.NonKeyAttributes = append(indexSchema.NonKeyAttributes, attr.Name)
+			}
+		}
+		if len(nonKeyAttributes) > 0 && indexSchema.ProjectionType != "INCLUDE" {
+			return nil, fmt.Errorf("index %q: non_key_attributes require PROJECTION_TYPE_INCLUDE", index.Name)
+		}
+
+		if index.Local {
+			if s.GetThroughput() != nil {
+				return nil, fmt.Errorf("index %q: local indexes share the table throughput", index.Name)
+			}
+			schema.LocalSecondaryIndexes = append(schema.LocalSecondaryIndexes, indexSchema)
+			continue
+		}
+		if schema.Throughput != nil {
+			indexSchema.Throughput = schema.Throughput
+			if t := throughput(s.GetThroughput()); t != nil {
+				indexSchema.Throughput = t
+			}
+		}
+		schema.GlobalSecondaryIndexes = append(schema.GlobalSecondaryIndexes, indexSchema)
+	}
+
+	for name := range settings {
+		return nil, fmt.Errorf("indexes: no index %q declared with dynamo.gsi or dynamo.lsi", name)
+	}
+
+	return schema, nil
+}
+
+// keySchema returns the key schema of a table or index.
+func keySchema(hashKey, rangeKey *Attribute) []KeySchemaElement {
+	elements := []KeySchemaElement{{Name: hashKey.Name, KeyType: "HASH"}}
+	if rangeKey != nil {
+		elements = append(elements, KeySchemaElement{Name: rangeKey.Name, KeyType: "RANGE"})
+	}
+	return elements
+}
+
+// throughput converts a Throughput option, nil if unset.
+func throughput(t *dynamopb.Throughput) *Throughput {
+	if t.GetReadCapacityUnits() == 0 && t.GetWriteCapacityUnits() == 0 {
+		return nil
+	}
+	return &Throughput{Read: t.GetReadCapacityUnits(), Write: t.GetWriteCapacityUnits()}
+}
+
+// attributeType returns the DynamoDB scalar type of a key Go type, or "" if
+// the type cannot be a key.
+func attributeType(goType string) string {
+	switch {
+	case goType == "string":
+		return "S"
+	case goType == "[]byte":
+		return "B"
+	case isIntegerType(goType):
+		return "N"
+	case goType == "bool" || goType == "float32" || goType == "float64":
+		return "" // Floats are inexact, booleans are not key types
+	case token.IsIdentifier(goType):
+		return "N" // Enums of the same package (messages are pointers)
+	default:
+		return ""
+	}
+}
+
+// isIntegerType reports whether a Go field type is an integer.
+func isIntegerType(goType string) bool {
+	switch goType {
+	case "int32", "int64", "uint32", "uint64":
+		return true
+	default:
+		return false
+	}
+}
+
+// SchemaData is the data of the schema template of a proto file.
+type SchemaData struct {
+	Source  string   // Proto file path
+	Package string   // Go package name
+	Tables  []*Table // Tables of the file with a schema
+}
+
+// schemaFileName returns the name of the schema file generated next to a .pb.go file.
+// Example: "users/v1/users.pb.go" -> "users/v1/users_dynamo_schema.pb.go"
+func schemaFileName(pbFile string) string {
+	return strings.TrimSuffix(pbFile, ".pb.go") + "_dynamo_schema.pb.go"
+}
+
+// schemaTemplate generates the table definitions of a file with aws-sdk-go-v2
+// types: <Msg>TableName, <Msg>CreateTableInput and, with a TTL attribute,
+// <Msg>UpdateTimeToLiveInput.
+//
+// Example:
+//
+//	_, err := client.CreateTable(ctx, pb.UserCreateTableInput())
+var schemaTemplate = template.Must(template.New("schema").Funcs(template.FuncMap{
+	"sdk": func(value string) string { return sdkConstants[value] },
+}).Parse(`// Code generated by protoc-gen-go-dynamo. DO NOT EDIT.
+// source: {{.Source}}
+
+package {{.Package}}
+
+import (
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+{{define "keySchema"}}[]types.KeySchemaElement{
+{{range .}}	{AttributeName: aws.String({{printf "%q" .Name}}), KeyType: {{sdk .KeyType}}},
+{{end}}}{{end}}
+{{- define "throughput"}}&types.ProvisionedThroughput{
+	ReadCapacityUnits:  aws.Int64({{.Read}}),
+	WriteCapacityUnits: aws.Int64({{.Write}}),
+}{{end}}
+{{- define "index"}}{
+	IndexName:  aws.String({{printf "%q" .Name}}),
+	KeySchema:  {{template "keySchema" .KeySchema}},
+	Projection: &types.Projection{
+		ProjectionType: {{sdk .ProjectionType}},{{if .NonKeyAttributes}}
+		NonKeyAttributes: []string{ {{range .NonKeyAttributes}}{{printf "%q" .}}, {{end}} },{{end}}
+	},{{if .Throughput}}
+	ProvisionedThroughput: {{template "throughput" .Throughput}},{{end}}
+},{{end}}
+{{- range .Tables}}{{$t := .}}{{with .Schema}}
+// {{$t.Name}}TableName is the name of the table of {{$t.Name}}.
+const {{$t.Name}}TableName = {{printf "%q" .TableName}}
+
+// {{$t.Name}}CreateTableInput returns the input creating the table of {{$t.Name}}.
+func {{$t.Name}}CreateTableInput() *dynamodb.CreateTableInput {
+	return &dynamodb.CreateTableInput{
+		TableName:   aws.String({{$t.Name}}TableName),
+		BillingMode: {{sdk .BillingMode}},
+		AttributeDefinitions: []types.AttributeDefinition{
+{{range .AttributeDefinitions}}			{AttributeName: aws.String({{printf "%q" .Name}}), AttributeType: {{sdk .Type}}},
+{{end}}		},
+		KeySchema: {{template "keySchema" .KeySchema}},{{if .Throughput}}
+		ProvisionedThroughput: {{template "throughput" .Throughput}},{{end}}{{if .GlobalSecondaryIndexes}}
+		GlobalSecondaryIndexes: []types.GlobalSecondaryIndex{
+{{range .GlobalSecondaryIndexes}}{{template "index" .}}
+{{end}}		},{{end}}{{if .LocalSecondaryIndexes}}
+		LocalSecondaryIndexes: []types.LocalSecondaryIndex{
+{{range .LocalSecondaryIndexes}}{{template "index" .}}
+{{end}}		},{{end}}
+	}
+}
+{{if .TTLAttribute}}
+// {{$t.Name}}UpdateTimeToLiveInput returns the input enabling TTL on the table of {{$t.Name}}.
+func {{$t.Name}}UpdateTimeToLiveInput() *dynamodb.UpdateTimeToLiveInput {
+	return &dynamodb.UpdateTimeToLiveInput{
+		TableName: aws.String({{$t.Name}}TableName),
+		TimeToLiveSpecification: &types.TimeToLiveSpecification{
+			AttributeName: aws.String({{printf "%q" .TTLAttribute}}),
+			Enabled:       aws.Bool(true),
+		},
+	}
+}
+{{end}}{{end}}{{end}}`))
+
+// sdkConstants maps DynamoDB API values to their aws-sdk-go-v2 constants.
+var sdkConstants = map[string]string{
+	"PAY_PER_REQUEST": "types.BillingModePayPerRequest",
+	"PROVISIONED":     "types.BillingModeProvisioned",
+	"HASH":            "types.KeyTypeHash",
+	"RANGE":           "types.KeyTypeRange",
+	"S":               "types.ScalarAttributeTypeS",
+	"N":               "types.ScalarAttributeTypeN",
+	"B":               "types.ScalarAttributeTypeB",
+	"ALL":             "types.ProjectionTypeAll",
+	"KEYS_ONLY":       "types.ProjectionTypeKeysOnly",
+	"INCLUDE":         "types.ProjectionTypeInclude",
+}
+
+// schemaJSONFileName returns the name of the JSON schema file generated next
+// to a .pb.go file.
+// Example: "users/v1/users.pb.go", "terraform" -> "users/v1/users_dynamo.tf.json"
+func schemaJSONFileName(pbFile, format string) string {
+	ext := ".cfn.json"
+	if format == "terraform" {
+		ext = ".tf.json"
+	}
+	return strings.TrimSuffix(pbFile, ".pb.go") + "_dynamo" + ext
+}
+
+// generateSchemaJSON renders the CloudFormation or Terraform JSON definition
+// of the tables of a file.
+func generateSchemaJSON(format string, tables []*Table) (string, error) {
+	var doc any
+	switch format {
+	case "cloudformation":
+		resources := map[string]any{}
+		for _, table := range tables {
+			resources[table.Name+"Table"] = map[string]any{
+				"Type":       "AWS::DynamoDB::Table",
+				"Properties": cloudFormationTable(table.Schema),
+			}
+		}
+		doc = map[string]any{
+			"AWSTemplateFormatVersion": "2010-09-09",
+			"Resources":                resources,
+		}
+	case "terraform":
+		resources := map[string]any{}
+		for _, table := range tables {
+			resources[pgs.Name(table.Name).LowerSnakeCase().String()] = terraformTable(table.Schema)
+		}
+		doc = map[string]any{
+			"resource": map[string]any{"aws_dynamodb_table": resources},
+		}
+	default:
+		return "", fmt.Errorf("unsupported schema format %q", format)
+	}
+
+	out, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(out) + "\n", nil
+}
+
+// cloudFormationTable returns the properties of an AWS::DynamoDB::Table resource.
+func cloudFormationTable(schema *Schema) map[string]any {
+	keySchema := func(elements []KeySchemaElement) []map[string]any {
+		var out []map[string]any
+		for _, e := range elements {
+			out = append(out, map[string]any{"AttributeName": e.Name, "KeyType": e.KeyType})
+		}
+		return out
+	}
+	throughput := func(t *Throughput) map[string]any {
+		return map[string]any{"ReadCapacityUnits": t.Read, "WriteCapacityUnits": t.Write}
+	}
+	indexes := func(indexes []IndexSchema) []map[string]any {
+		var out []map[string]any
+		for _, index := range indexes {
+			projection := map[string]any{"ProjectionType": index.ProjectionType}
+			if len(index.NonKeyAttributes) > 0 {
+				projection["NonKeyAttributes"] = index.NonKeyAttributes
+			}
+			i := map[string]any{
+				"IndexName":  index.Name,
+				"KeySchema":  keySchema(index.KeySchema),
+				"Projection": projection,
+			}
+			if index.Throughput != nil {
+				i["ProvisionedThroughput"] = throughput(index.Throughput)
+			}
+			out = append(out, i)
+		}
+		return out
+	}
+
+	var attributes []map[string]any
+	for _, attr := range schema.AttributeDefinitions {
+		attributes = append(attributes, map[string]any{"AttributeName": attr.Name, "AttributeType": attr.Type})
+	}
+
+	props := map[string]any{
+		"TableName":            schema.TableName,
+		"BillingMode":          schema.BillingMode,
+		"AttributeDefinitions": attributes,
+		"KeySchema":            keySchema(schema.KeySchema),
+	}
+	if schema.Throughput != nil {
+		props["ProvisionedThroughput"] = throughput(schema.Throughput)
+	}
+	if len(schema.GlobalSecondaryIndexes) > 0 {
+		props["GlobalSecondaryIndexes"] = indexes(schema.GlobalSecondaryIndexes)
+	}
+	if len(schema.LocalSecondaryIndexes) > 0 {
+		props["LocalSecondaryIndexes"] = indexes(schema.LocalSecondaryIndexes)
+	}
+	if schema.TTLAttribute != "" {
+		props["TimeToLiveSpecification"] = map[string]any{"AttributeName": schema.TTLAttribute, "Enabled": true}
+	}
+	return props
+}
+
+// terraformTable returns the arguments of an aws_dynamodb_table resource.
+func terraformTable(schema *Schema) map[string]any {
+	keys := func(elements []KeySchemaElement, args map[string]any) {
+		for _, e := range elements {
+			if e.KeyType == "HASH" {
+				args["hash_key"] = e.Name
+			} else {
+				args["range_key"] = e.Name
+			}
+		}
+	}
+	indexes := func(indexes []IndexSchema) []map[string]any {
+		var out []map[string]any
+		for _, index := range indexes {
+			i := map[string]any{"name": index.Name, "projection_type": index.ProjectionType}
+			keys(index.KeySchema, i)
+			if len(index.NonKeyAttributes) > 0 {
+				i["non_key_attributes"] = index.NonKeyAttributes
+			}
+			if index.Throughput != nil {
+				i["read_capacity"] = index.Throughput.Read
+				i["write_capacity"] = index.Throughput.Write
+			}
+			out = append(out, i)
+		}
+		return out
+	}
+
+	var attributes []map[string]any
+	for _, attr := range schema.AttributeDefinitions {
+		attributes = append(attributes, map[string]any{"name": attr.Name, "type": attr.Type})
+	}
+
+	args := map[string]any{
+		"name":         schema.TableName,
+		"billing_mode": schema.BillingMode,
+		"attribute":    attributes,
+	}
+	keys(schema.KeySchema, args)
+	if schema.Throughput != nil {
+		args["read_capacity"] = schema.Throughput.Read
+		args["write_capacity"] = schema.Throughput.Write
+	}
+	if len(schema.GlobalSecondaryIndexes) > 0 {
+		args["global_secondary_index"] = indexes(schema.GlobalSecondaryIndexes)
+	}
+	if len(schema.LocalSecondaryIndexes) > 0 {
+		// LSIs use the table hash key
+		lsis := indexes(schema.LocalSecondaryIndexes)
+		for _, lsi := range lsis {
+			delete(lsi, "hash_key")
+		}
+		args["local_secondary_index"] = lsis
+	}
+	if schema.TTLAttribute != "" {
+		args["ttl"] = map[string]any{"attribute_name": schema.TTLAttribute, "enabled": true}
+	}
+	return args
+}
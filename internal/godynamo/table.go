@@ -0,0 +1,272 @@
+package godynamo
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	pgs "github.com/lyft/protoc-gen-star/v2"
+	pgsgo "github.com/lyft/protoc-gen-star/v2/lang/go"
+
+	dynamopb "github.com/getfrontierhq/buf-public-apis/gen/go/dynamo"
+)
+
+// Table describes a message stored as a DynamoDB item: its primary key,
+// its indexes and its other attributes.
+// Example: message User with (dynamo.key) on id → Table{Name: "User", HashKey: id}
+type Table struct {
+	Name       string      // Go type name (e.g., "User")
+	HashKey    *Attribute  // Table partition key
+	RangeKey   *Attribute  // Table sort key (nil if none)
+	Indexes    []*Index    // Secondary indexes, in declaration order
+	Attributes []Attribute // Non-key fields
+	Version    *Attribute  // Version field of optimistic locking (nil if none)
+	Schema     *Schema     // Table definition (nil without (dynamo.table) option)
+}
+
+// Attribute is a message field stored as an item attribute.
+type Attribute struct {
+	ProtoName string // Proto field name (e.g., "created_at")
+	GoName    string // Go field name (e.g., "CreatedAt")
+	GoType    string // Go field type (e.g., "int64")
+	Name      string // Attribute name: column_name, or the Go field name
+	Template  string // Template of a (dynamo.composite_key) attribute ("" for fields)
+}
+
+// Index is a global or local secondary index.
+type Index struct {
+	Name     string     // Index name (e.g., "email-index")
+	GoName   string     // Index name as a Go identifier (e.g., "EmailIndex")
+	Local    bool       // true for LSIs
+	HashKey  *Attribute // Index partition key (the table partition key for LSIs)
+	RangeKey *Attribute // Index sort key (nil if none)
+
+	// Projection declared by the (dynamo.gsi) or (dynamo.lsi) options of the
+	// index (PROJECTION_TYPE_UNSPECIFIED if none).
+	ProjectionType   dynamopb.ProjectionType
+	NonKeyAttributes []string // Proto field names
+}
+
+// extractTables returns the tables of the messages of a file with a
+// (dynamo.key) hash key, in declaration order.
+func extractTables(ctx pgsgo.Context, f pgs.File) ([]*Table, error) {
+	var tables []*Table
+	for _, msg := range f.AllMessages() {
+		table, err := extractTable(ctx, msg)
+		if err != nil {
+			return nil, err
+		}
+		if table != nil {
+			tables = append(tables, table)
+		}
+	}
+	return tables, nil
+}
+
+// extractTable builds the table of a message, or returns nil if the message
+// has no (dynamo.key) or (dynamo.composite_key) hash key.
+func extractTable(ctx pgsgo.Context, msg pgs.Message) (*Table, error) {
+	table := &Table{Name: ctx.Name(msg).String()}
+	indexes := map[string]*Index{}
+
+	for _, f := range msg.Fields() {
+		attr := Attribute{
+			ProtoName: f.Name().String(),
+			GoName:    ctx.Name(f).String(),
+			GoType:    ctx.Type(f).String(),
+		}
+		attr.Name = attr.GoName
+
+		keyCfg, err := getKeyConfig(f)
+		if err != nil {
+			return nil, fmt.Errorf("%s: invalid dynamo.key option: %w", f.FullyQualifiedName(), err)
+		}
+		if keyCfg != nil && keyCfg.ColumnName != "" {
+			attr.Name = keyCfg.ColumnName
+		}
+
+		attrCfg, err := getAttributeConfig(f)
+		if err != nil {
+			return nil, fmt.Errorf("%s: invalid dynamo.attribute option: %w", f.FullyQualifiedName(), err)
+		}
+		if attrCfg.GetVersion() {
+			table.Version = &attr
+		}
+
+		isKey := false
+		switch keyCfg.GetType() {
+		case dynamopb.KeyType_KEY_TYPE_HASH:
+			table.HashKey, isKey = &attr, true
+		case dynamopb.KeyType_KEY_TYPE_RANGE:
+			table.RangeKey, isKey = &attr, true
+		}
+
+		gsis, err := getGSIs(f)
+		if err != nil {
+			return nil, fmt.Errorf("%s: invalid dynamo.gsi option: %w", f.FullyQualifiedName(), err)
+		}
+		lsis, err := getLSIs(f)
+		if err != nil {
+			return nil, fmt.Errorf("%s: invalid dynamo.lsi option: %w", f.FullyQualifiedName(), err)
+		}
+		for _, cfg := range gsis {
+			addIndexKey(table, indexes, cfg, false, &attr)
+		}
+		for _, cfg := range lsis {
+			addIndexKey(table, indexes, cfg, true, &attr)
+		}
+
+		if !isKey {
+			table.Attributes = append(table.Attributes, attr)
+		}
+	}
+
+	composites, err := getCompositeKeys(msg)
+	if err != nil {
+		return nil, fmt.Errorf("%s: invalid dynamo.composite_key option: %w", msg.FullyQualifiedName(), err)
+	}
+	for _, cfg := range composites {
+		attr := &Attribute{Name: cfg.GetName(), GoName: goIdentifier(cfg.GetName()), GoType: "string", Template: cfg.GetTemplate()}
+		if cfg.GetIndex() != "" {
+			local := false
+			if index, ok := indexes[cfg.GetIndex()]; ok {
+				local = index.Local
+			}
+			addIndexKey(table, indexes, &dynamopb.IndexConfig{Name: cfg.GetIndex(), Key: cfg.GetKey()}, local, attr)
+			continue
+		}
+		switch cfg.GetKey() {
+		case dynamopb.KeyType_KEY_TYPE_HASH:
+			table.HashKey = attr
+		case dynamopb.KeyType_KEY_TYPE_RANGE:
+			table.RangeKey = attr
+		}
+	}
+
+	var cfg dynamopb.TableConfig
+	hasConfig, err := msg.Extension(dynamopb.E_Table, &cfg)
+	if err != nil {
+		return nil, fmt.Errorf("%s: invalid dynamo.table option: %w", msg.FullyQualifiedName(), err)
+	}
+
+	if table.HashKey == nil {
+		if hasConfig {
+			return nil, fmt.Errorf("%s: dynamo.table option requires a KEY_TYPE_HASH key", msg.FullyQualifiedName())
+		}
+		return nil, nil
+	}
+
+	for _, key := range []*Attribute{table.HashKey, table.RangeKey} {
+		if key != nil {
+			if err := checkKeyType(msg, key); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for _, index := range table.Indexes {
+		if index.Local {
+			// LSIs share the partition key of the table
+			index.HashKey = table.HashKey
+		}
+		if index.HashKey == nil {
+			return nil, fmt.Errorf("%s: index %q has no hash key", msg.FullyQualifiedName(), index.Name)
+		}
+		for _, key := range []*Attribute{index.HashKey, index.RangeKey} {
+			if key != nil {
+				if err := checkKeyType(msg, key); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	if hasConfig {
+		if table.Schema, err = buildSchema(table, &cfg); err != nil {
+			return nil, fmt.Errorf("%s: invalid dynamo.table option: %w", msg.FullyQualifiedName(), err)
+		}
+	}
+
+	return table, nil
+}
+
+// composite reports whether a key of the table or of its indexes is a
+// (dynamo.composite_key) attribute.
+func (t *Table) composite() bool {
+	keys := []*Attribute{t.HashKey, t.RangeKey}
+	for _, index := range t.Indexes {
+		keys = append(keys, index.HashKey, index.RangeKey)
+	}
+	for _, key := range keys {
+		if key != nil && key.Template != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// attribute returns the attribute of a field (proto name), keys included.
+func (t *Table) attribute(protoName string) *Attribute {
+	for _, attr := range []*Attribute{t.HashKey, t.RangeKey} {
+		if attr != nil && attr.ProtoName == protoName {
+			return attr
+		}
+	}
+	for i := range t.Attributes {
+		if t.Attributes[i].ProtoName == protoName {
+			return &t.Attributes[i]
+		}
+	}
+	return nil
+}
+
+// checkKeyType checks that a key attribute is a string, integer, bytes or enum
+// of the same package, which DynamoDB stores as S, N or B.
+func checkKeyType(msg pgs.Message, key *Attribute) error {
+	if attributeType(key.GoType) == "" {
+		return fmt.Errorf("%s.%s: key of type %s is not supported, use a string, integer, bytes or enum field",
+			msg.FullyQualifiedName(), key.ProtoName, key.GoType)
+	}
+	return nil
+}
+
+// addIndexKey records attr as the cfg key of its index, creating the index
+// on first use, and the projection of cfg.
+func addIndexKey(table *Table, indexes map[string]*Index, cfg *dynamopb.IndexConfig, local bool, attr *Attribute) {
+	index, ok := indexes[cfg.Name]
+	if !ok {
+		index = &Index{Name: cfg.Name, GoName: goIdentifier(cfg.Name), Local: local}
+		indexes[cfg.Name] = index
+		table.Indexes = append(table.Indexes, index)
+	}
+
+	// A second projection is reported by the extractor, the first one is kept
+	if index.ProjectionType == dynamopb.ProjectionType_PROJECTION_TYPE_UNSPECIFIED && cfg.GetProjectionType() != dynamopb.ProjectionType_PROJECTION_TYPE_UNSPECIFIED {
+		index.ProjectionType = cfg.GetProjectionType()
+		index.NonKeyAttributes = cfg.GetNonKeyAttributes()
+	}
+
+	switch cfg.Key {
+	case dynamopb.KeyType_KEY_TYPE_HASH:
+		index.HashKey = attr
+	case dynamopb.KeyType_KEY_TYPE_RANGE:
+		index.RangeKey = attr
+	}
+}
+
+// goIdentifier converts an index name into an exported Go identifier.
+// Examples:
+//
+//	"email-index" -> "EmailIndex"
+//	"Seq-ID-index" -> "SeqIDIndex"
+func goIdentifier(name string) string {
+	var b strings.Builder
+	for _, part := range strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	if b.Len() == 0 || unicode.IsDigit(rune(b.String()[0])) {
+		return "Index" + b.String()
+	}
+	return b.String()
+}
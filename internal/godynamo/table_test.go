@@ -0,0 +1,58 @@
+package godynamo
+
+import (
+	"testing"
+
+	dynamopb "github.com/getfrontierhq/buf-public-apis/gen/go/dynamo"
+)
+
+func TestAddIndexKeyProjection(t *testing.T) {
+	table := &Table{}
+	indexes := map[string]*Index{}
+	email, name := &Attribute{Name: "email"}, &Attribute{Name: "name"}
+	addIndexKey(table, indexes, &dynamopb.IndexConfig{
+		Name:           "email-index",
+		Key:            dynamopb.KeyType_KEY_TYPE_HASH,
+		ProjectionType: dynamopb.ProjectionType_PROJECTION_TYPE_KEYS_ONLY,
+	}, false, email)
+	addIndexKey(table, indexes, &dynamopb.IndexConfig{
+		Name:             "email-index",
+		Key:              dynamopb.KeyType_KEY_TYPE_RANGE,
+		ProjectionType:   dynamopb.ProjectionType_PROJECTION_TYPE_INCLUDE,
+		NonKeyAttributes: []string{"tags"},
+	}, false, name)
+
+	if len(table.Indexes) != 1 {
+		t.Fatalf("got %d indexes, want 1", len(table.Indexes))
+	}
+	index := table.Indexes[0]
+	if index.HashKey != email || index.RangeKey != name {
+		t.Errorf("keys = %v, %v", index.HashKey, index.RangeKey)
+	}
+	// The second projection is reported by the extractor, the first one is kept
+	if index.ProjectionType != dynamopb.ProjectionType_PROJECTION_TYPE_KEYS_ONLY || index.NonKeyAttributes != nil {
+		t.Errorf("projection = %v %v, want the first one", index.ProjectionType, index.NonKeyAttributes)
+	}
+}
+
+func TestAttributeType(t *testing.T) {
+	for goType, want := range map[string]string{
+		"string":                 "S",
+		"[]byte":                 "B",
+		"int32":                  "N",
+		"uint64":                 "N",
+		"Status":                 "N", // Enum of the same package
+		"bool":                   "",
+		"float32":                "",
+		"float64":                "",
+		"*User":                  "",
+		"[]string":               "",
+		"map[string]string":      "",
+		"otherpb.Status":         "",
+		"*timestamppb.Timestamp": "",
+	} {
+		if got := attributeType(goType); got != want {
+			t.Errorf("attributeType(%q) = %q, want %q", goType, got, want)
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package store_test
+
+import (
+	"reflect"
+	"testing"
+
+	"example.com/dyntest/store"
+)
+
+// TestAWSV2Tags checks the attributevalue tags written into the protoc-gen-go
+// structs, which have no guregu tags.
+func TestAWSV2Tags(t *testing.T) {
+	typ := reflect.TypeOf(store.User{})
+	for name, want := range map[string]string{
+		"Id":        "ID",
+		"CreatedAt": "",
+		"Email":     "",
+		"Nickname":  ",omitempty",
+	} {
+		field, _ := typ.FieldByName(name)
+		if got := field.Tag.Get("dynamodbav"); got != want {
+			t.Errorf("%s: dynamodbav tag %q, want %q", name, got, want)
+		}
+		if tag, ok := field.Tag.Lookup("dynamo"); ok {
+			t.Errorf("%s: unexpected dynamo tag %q", name, tag)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package store_test
+
+import (
+	"reflect"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+
+	"example.com/dyntest/store"
+)
+
+// TestCompanion checks the tags and key metadata of the companion file, and
+// the conversions between the messages and their items.
+func TestCompanion(t *testing.T) {
+	if store.UserDynamoHashKey != "ID" || store.UserDynamoRangeKey != "CreatedAt" || store.UserDynamoIndexEmailIndex != "email-index" {
+		t.Errorf("key metadata = %q, %q, %q", store.UserDynamoHashKey, store.UserDynamoRangeKey, store.UserDynamoIndexEmailIndex)
+	}
+
+	field, _ := reflect.TypeOf(store.UserDynamoItem{}).FieldByName("Id")
+	if tag := field.Tag.Get("dynamo"); tag != "ID,hash" {
+		t.Errorf("Id: dynamo tag %q, want %q", tag, "ID,hash")
+	}
+	// The protoc-gen-go structs are not rewritten
+	if field, _ := reflect.TypeOf(store.User{}).FieldByName("Id"); field.Tag.Get("dynamo") != "" {
+		t.Errorf("User.Id: dynamo tag %q, want none", field.Tag.Get("dynamo"))
+	}
+
+	user := &store.User{Id: "u1", CreatedAt: 10, Email: "a@example.com", Nickname: proto.String("a")}
+	if got := user.DynamoItem().Proto(); !proto.Equal(got, user) {
+		t.Errorf("DynamoItem().Proto() = %v, want %v", got, user)
+	}
+	if (*store.User)(nil).DynamoItem() != nil || (*store.UserDynamoItem)(nil).Proto() != nil {
+		t.Error("conversions of nil are not nil")
+	}
+}
@@ -0,0 +1,43 @@
+package store_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"github.com/getfrontierhq/buf-public-apis/pkg/godynamo/expr"
+
+	"example.com/dyntest/store"
+)
+
+// TestExpressions builds expressions with the typed attributes of users and
+// orders.
+func TestExpressions(t *testing.T) {
+	attrs := store.UserDynamoAttrs
+	e, err := expr.Builder{
+		KeyCondition: expr.And(attrs.Email.Equal("a@example.com"), attrs.CreatedAt.Greater(10)),
+		Filter:       expr.BeginsWith(attrs.Nickname, "a"),
+		Update:       expr.Update{attrs.Nickname.Set("b"), expr.Add(attrs.CreatedAt, 1)},
+	}.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *e.KeyCondition != "(#n0 = :v0) AND (#n1 > :v1)" || *e.Filter != "begins_with(#n2, :v2)" {
+		t.Errorf("expressions = %q, %q", *e.KeyCondition, *e.Filter)
+	}
+	wantNames := map[string]string{"#n0": "Email", "#n1": "CreatedAt", "#n2": "Nickname"}
+	if !reflect.DeepEqual(e.Names, wantNames) {
+		t.Errorf("Names = %v, want %v", e.Names, wantNames)
+	}
+	if v := e.Values[":v1"]; !reflect.DeepEqual(v, &types.AttributeValueMemberN{Value: "10"}) {
+		t.Errorf("Values[:v1] = %v", v)
+	}
+
+	if store.UserDynamoAttrId != "ID" || attrs.Id.Name() != "ID" {
+		t.Errorf("id attribute = %q, %q", store.UserDynamoAttrId, attrs.Id.Name())
+	}
+	if store.OrderDynamoAttrs.PK.Name() != "PK" {
+		t.Errorf("PK attribute = %q", store.OrderDynamoAttrs.PK.Name())
+	}
+}
@@ -0,0 +1,26 @@
+package store_test
+
+import (
+	"reflect"
+	"testing"
+
+	"example.com/dyntest/store"
+)
+
+// TestGureguTags checks the tags written into the protoc-gen-go structs.
+func TestGureguTags(t *testing.T) {
+	typ := reflect.TypeOf(store.User{})
+	for name, want := range map[string]reflect.StructTag{
+		"Id":        `dynamo:"ID,hash"`,
+		"CreatedAt": `dynamo:",range"`,
+		"Email":     `index:"email-index,hash"`,
+		"Nickname":  `dynamo:",omitempty"`,
+	} {
+		field, _ := typ.FieldByName(name)
+		for _, key := range []string{"dynamo", "index"} {
+			if got := field.Tag.Get(key); got != want.Get(key) {
+				t.Errorf("%s: %s tag %q, want %q", name, key, got, want.Get(key))
+			}
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package store_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"google.golang.org/protobuf/proto"
+
+	"example.com/dyntest/store"
+)
+
+// TestItemRoundTrip marshals and unmarshals an item with the generated
+// marshalers.
+func TestItemRoundTrip(t *testing.T) {
+	user := &store.User{Id: "u1", Email: "a@example.com"}
+	item, err := user.MarshalDynamoItem()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]types.AttributeValue{
+		"ID":        &types.AttributeValueMemberS{Value: "u1"},
+		"CreatedAt": &types.AttributeValueMemberN{Value: "0"}, // Keys are always stored
+		"Email":     &types.AttributeValueMemberS{Value: "a@example.com"},
+	}
+	if !reflect.DeepEqual(item, want) {
+		t.Errorf("MarshalDynamoItem() = %v, want %v", item, want)
+	}
+
+	got := &store.User{}
+	if err := got.UnmarshalDynamoItem(item); err != nil {
+		t.Fatal(err)
+	}
+	if !proto.Equal(got, user) {
+		t.Errorf("UnmarshalDynamoItem() = %v, want %v", got, user)
+	}
+}
@@ -0,0 +1,68 @@
+package store_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"github.com/getfrontierhq/buf-public-apis/pkg/godynamo/compositekey"
+
+	"example.com/dyntest/store"
+)
+
+// TestCompositeKeys formats and parses the composite keys of an order.
+func TestCompositeKeys(t *testing.T) {
+	order := &store.Order{Id: "o1", UserId: "u1", CreatedAt: 42, Type: "web"}
+	if got, want := order.DynamoSK(), "ORDER#00000000000000000042#o1"; got != want {
+		t.Errorf("DynamoSK() = %q, want %q", got, want)
+	}
+	if got := store.OrderDynamoSK(42, "o1"); got != order.DynamoSK() {
+		t.Errorf("OrderDynamoSK() = %q, want %q", got, order.DynamoSK())
+	}
+
+	var parsed store.Order
+	if err := parsed.ParseDynamoSK(order.DynamoSK()); err != nil || parsed.Id != "o1" || parsed.CreatedAt != 42 {
+		t.Errorf("ParseDynamoSK() = %v, %v", &parsed, err)
+	}
+	if err := parsed.ParseDynamoPK("ORDER#u1"); err == nil {
+		t.Error("ParseDynamoPK() succeeded with another prefix")
+	}
+
+	// The item marshalers store the composite keys
+	item, err := order.MarshalDynamoItem()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, want := range map[string]string{"PK": "USER#u1", "GSI1PK": "TYPE#web", "GSI1SK": "00000000000000000042"} {
+		if av, ok := item[name].(*types.AttributeValueMemberS); !ok || av.Value != want {
+			t.Errorf("item[%q] = %v, want %q", name, item[name], want)
+		}
+	}
+}
+
+// TestKeyConditions checks the key conditions of the table and its index.
+func TestKeyConditions(t *testing.T) {
+	expr, names, values := store.OrderDynamoQuery("u1").Expression()
+	if expr != "#h = :h AND begins_with(#r, :r0)" {
+		t.Errorf("expression = %q", expr)
+	}
+	if want := map[string]string{"#h": "PK", "#r": "SK"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("names = %v, want %v", names, want)
+	}
+	wantValues := map[string]types.AttributeValue{
+		":h":  &types.AttributeValueMemberS{Value: "USER#u1"},
+		":r0": &types.AttributeValueMemberS{Value: "ORDER#"},
+	}
+	if !reflect.DeepEqual(values, wantValues) {
+		t.Errorf("values = %v, want %v", values, wantValues)
+	}
+
+	c := store.OrderDynamoQueryGsi1("web")
+	if c.Index != "gsi1" || c.HashName != "GSI1PK" || c.HashValue != "TYPE#web" || c.RangeName != "" {
+		t.Errorf("OrderDynamoQueryGsi1() = %+v", c)
+	}
+	if in := c.Range("GSI1SK", compositekey.Greater, store.OrderDynamoGSI1SK(10)).QueryInput("app"); *in.IndexName != "gsi1" {
+		t.Errorf("QueryInput() = %+v", in)
+	}
+}
@@ -0,0 +1,48 @@
+package store_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/guregu/dynamo/v2"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"example.com/dyntest/store"
+)
+
+// TestRepositoryMarshal checks that updates store the attributes of the item
+// marshalers, which Get decodes.
+func TestRepositoryMarshal(t *testing.T) {
+	ctx := context.Background()
+	table := dynamo.NewTable("Id", "")
+	repo := store.NewProfileDynamoRepository(table)
+	updatedAt := timestamppb.New(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC))
+
+	err := repo.Put(ctx, &store.Profile{Id: "p1", Visits: 3, Nickname: proto.String("a"), UpdatedAt: timestamppb.New(time.Unix(0, 0))})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &store.Profile{Id: "p1", UpdatedAt: updatedAt}
+	updated, err := repo.Update(ctx, want, "updated_at", "visits", "nickname")
+	if err != nil || !proto.Equal(updated, want) {
+		t.Fatalf("Update() = %v, %v, want %v", updated, err, want)
+	}
+	stored, err := repo.Get(ctx, "p1")
+	if err != nil || !proto.Equal(stored, want) {
+		t.Fatalf("Get() = %v, %v, want %v", stored, err, want)
+	}
+
+	// The zero visits are stored, the unset nickname is removed
+	wantItem := dynamo.Item{
+		"Id":        &types.AttributeValueMemberS{Value: "p1"},
+		"UpdatedAt": &types.AttributeValueMemberS{Value: "2024-01-02T03:04:05Z"},
+		"Visits":    &types.AttributeValueMemberN{Value: "0"},
+	}
+	if items := table.Items(); len(items) != 1 || !reflect.DeepEqual(items[0], wantItem) {
+		t.Errorf("stored items = %v, want %v", items, wantItem)
+	}
+}
@@ -0,0 +1,62 @@
+package store_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/guregu/dynamo/v2"
+	"google.golang.org/protobuf/proto"
+
+	"example.com/dyntest/store"
+)
+
+// TestRepository runs the same reads and writes on both repositories.
+func TestRepository(t *testing.T) {
+	repos := map[string]store.UserRepository{
+		"dynamo": store.NewUserDynamoRepository(dynamo.NewTable("ID", "CreatedAt")),
+		"memory": store.NewUserMemoryRepository(),
+	}
+	for name, repo := range repos {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			for _, user := range []*store.User{
+				{Id: "u1", CreatedAt: 10, Email: "a@example.com"},
+				{Id: "u1", CreatedAt: 20, Email: "a@example.com"},
+				{Id: "u2", CreatedAt: 10, Email: "b@example.com"},
+			} {
+				if err := repo.Put(ctx, user); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			user, err := repo.Get(ctx, "u1", 20)
+			if err != nil || user.CreatedAt != 20 || user.Email != "a@example.com" {
+				t.Fatalf("Get() = %v, %v", user, err)
+			}
+			users, err := repo.QueryByEmailIndex(ctx, "a@example.com")
+			if err != nil || len(users) != 2 {
+				t.Fatalf("QueryByEmailIndex() = %v, %v", users, err)
+			}
+
+			updated, err := repo.Update(ctx, &store.User{Id: "u1", CreatedAt: 10, Nickname: proto.String("a")}, "nickname")
+			if err != nil || updated.GetNickname() != "a" || updated.Email != "a@example.com" {
+				t.Fatalf("Update() = %v, %v", updated, err)
+			}
+			updated, err = repo.Update(ctx, &store.User{Id: "u1", CreatedAt: 10}, "nickname")
+			if err != nil || updated.Nickname != nil {
+				t.Fatalf("Update() of an unset field = %v, %v", updated, err)
+			}
+			if _, err := repo.Update(ctx, &store.User{Id: "u1", CreatedAt: 10}, "id"); err == nil {
+				t.Error("Update() of a key field succeeded")
+			}
+
+			if err := repo.Delete(ctx, "u1", 10); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := repo.Get(ctx, "u1", 10); !errors.Is(err, dynamo.ErrNotFound) {
+				t.Errorf("Get() of a deleted item: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package store_test
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"example.com/dyntest/store"
+)
+
+// attribute is an attribute definition of the JSON schemas.
+type attribute struct {
+	Name, Type string
+}
+
+// wantAttributes are the key attributes of the users table.
+var wantAttributes = []attribute{{"ID", "S"}, {"CreatedAt", "N"}, {"Email", "S"}}
+
+// TestCreateTableInput checks the CreateTable input of the users table.
+func TestCreateTableInput(t *testing.T) {
+	in := store.UserCreateTableInput()
+	if store.UserTableName != "users" || aws.ToString(in.TableName) != store.UserTableName {
+		t.Errorf("table name = %q, %q", store.UserTableName, aws.ToString(in.TableName))
+	}
+	var attributes []attribute
+	for _, def := range in.AttributeDefinitions {
+		attributes = append(attributes, attribute{aws.ToString(def.AttributeName), string(def.AttributeType)})
+	}
+	if !reflect.DeepEqual(attributes, wantAttributes) {
+		t.Errorf("AttributeDefinitions = %v, want %v", attributes, wantAttributes)
+	}
+	if len(in.KeySchema) != 2 || in.KeySchema[1].KeyType != types.KeyTypeRange {
+		t.Errorf("KeySchema = %v", in.KeySchema)
+	}
+	if len(in.GlobalSecondaryIndexes) != 1 || aws.ToString(in.GlobalSecondaryIndexes[0].IndexName) != "email-index" {
+		t.Errorf("GlobalSecondaryIndexes = %v", in.GlobalSecondaryIndexes)
+	}
+}
+
+// TestCloudFormation checks the CloudFormation definition of the users table.
+func TestCloudFormation(t *testing.T) {
+	var doc struct {
+		Resources map[string]struct {
+			Type       string
+			Properties struct {
+				TableName            string
+				AttributeDefinitions []struct{ AttributeName, AttributeType string }
+			}
+		}
+	}
+	readJSON(t, "store_dynamo.cfn.json", &doc)
+
+	table := doc.Resources["UserTable"]
+	var attributes []attribute
+	for _, def := range table.Properties.AttributeDefinitions {
+		attributes = append(attributes, attribute{def.AttributeName, def.AttributeType})
+	}
+	if table.Type != "AWS::DynamoDB::Table" || table.Properties.TableName != "users" || !reflect.DeepEqual(attributes, wantAttributes) {
+		t.Errorf("UserTable = %+v", table)
+	}
+}
+
+// TestTerraform checks the Terraform definition of the users table.
+func TestTerraform(t *testing.T) {
+	var doc struct {
+		Resource struct {
+			Tables map[string]struct {
+				Name      string
+				HashKey   string `json:"hash_key"`
+				RangeKey  string `json:"range_key"`
+				Attribute []attribute
+			} `json:"aws_dynamodb_table"`
+		}
+	}
+	readJSON(t, "store_dynamo.tf.json", &doc)
+
+	table := doc.Resource.Tables["user"]
+	if table.Name != "users" || table.HashKey != "ID" || table.RangeKey != "CreatedAt" || !reflect.DeepEqual(table.Attribute, wantAttributes) {
+		t.Errorf("user = %+v", table)
+	}
+}
+
+func readJSON(t *testing.T, name string, v any) {
+	t.Helper()
+	data, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		t.Fatal(err)
+	}
+}
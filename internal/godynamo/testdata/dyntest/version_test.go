@@ -0,0 +1,48 @@
+package store_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/guregu/dynamo/v2"
+
+	"github.com/getfrontierhq/buf-public-apis/pkg/godynamo/version"
+
+	"example.com/dyntest/store"
+)
+
+// TestRepositoryVersion runs the same versioned writes on both repositories.
+func TestRepositoryVersion(t *testing.T) {
+	repos := map[string]store.SessionRepository{
+		"dynamo": store.NewSessionDynamoRepository(dynamo.NewTable("Token", "")),
+		"memory": store.NewSessionMemoryRepository(),
+	}
+	for name, repo := range repos {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			session := &store.Session{Token: "t1", UserId: "u1"}
+			if err := repo.Put(ctx, session); err != nil || session.Version != 1 {
+				t.Fatalf("create: version %d, %v", session.Version, err)
+			}
+			session.UserId = "u2"
+			if err := repo.Put(ctx, session); err != nil || session.Version != 2 {
+				t.Fatalf("replace: version %d, %v", session.Version, err)
+			}
+
+			var conflict *version.ConflictError
+			stale := &store.Session{Token: "t1", UserId: "u3", Version: 1}
+			if err := repo.Put(ctx, stale); !errors.As(err, &conflict) || stale.Version != 1 {
+				t.Fatalf("stale put: version %d, %v", stale.Version, err)
+			}
+			if err := repo.Put(ctx, &store.Session{Token: "t1"}); !errors.As(err, &conflict) {
+				t.Fatalf("create of an existing item: %v", err)
+			}
+
+			stored, err := repo.Get(ctx, "t1")
+			if err != nil || stored.Version != 2 || stored.UserId != "u2" {
+				t.Fatalf("stored = %v, %v", stored, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,503 @@
+// Package dynamo is an in-memory fake of the part of the guregu/dynamo/v2 API
+// used by the repositories generated by protoc-gen-go-dynamo, for its tests.
+//
+// Like guregu/dynamo, Put marshals its item when it is called, not when it is
+// run, and items implementing ItemMarshaler and ItemUnmarshaler are stored as
+// the attribute values they marshal to. Other items are proto messages whose
+// attributes are their fields, named as in proto or in Go (e.g., "user_id" or
+// "UserId"). Conditions are limited to the forms written by the repositories.
+package dynamo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+var ErrNotFound = errors.New("dynamo: no item found")
+
+type Operator string
+
+const Equal Operator = "EQ"
+
+// Item is the attribute values of an item.
+type Item = map[string]types.AttributeValue
+
+// ItemMarshaler is implemented by items encoding themselves.
+type ItemMarshaler interface {
+	MarshalDynamoItem() (Item, error)
+}
+
+// ItemUnmarshaler is implemented by items decoding themselves.
+type ItemUnmarshaler interface {
+	UnmarshalDynamoItem(item Item) error
+}
+
+// Table is an in-memory table.
+type Table struct {
+	t *table
+}
+
+type table struct {
+	hashKey, rangeKey string
+	records           []*record
+}
+
+// NewTable returns an empty table with the given key attributes. It has no
+// guregu/dynamo equivalent.
+func NewTable(hashKey, rangeKey string) Table {
+	return Table{&table{hashKey: hashKey, rangeKey: rangeKey}}
+}
+
+// Items returns the attribute values of the stored items of ItemMarshaler
+// types. It has no guregu/dynamo equivalent.
+func (t Table) Items() []Item {
+	var items []Item
+	for _, r := range t.t.records {
+		if r.item != nil {
+			items = append(items, maps.Clone(r.item))
+		}
+	}
+	return items
+}
+
+func (t Table) Get(name string, value any) *Query {
+	return &Query{table: t.t, matches: []match{{name, value}}}
+}
+
+func (t Table) Put(item any) *Put {
+	r, err := newRecord(item)
+	return &Put{table: t.t, record: r, err: err}
+}
+
+func (t Table) Delete(name string, value any) *Delete {
+	return &Delete{table: t.t, matches: []match{{name, value}}}
+}
+
+func (t Table) Update(name string, value any) *Update {
+	return &Update{table: t.t, matches: []match{{name, value}}}
+}
+
+type Query struct {
+	table   *table
+	matches []match
+}
+
+func (q *Query) Range(name string, op Operator, values ...any) *Query {
+	q.matches = append(q.matches, match{name, values[0]})
+	return q
+}
+
+func (q *Query) Index(name string) *Query { return q }
+
+func (q *Query) One(ctx context.Context, out any) error {
+	i := q.table.find(q.matches)
+	if i < 0 {
+		return ErrNotFound
+	}
+	return q.table.records[i].decode(out)
+}
+
+func (q *Query) All(ctx context.Context, out any) error {
+	slice := reflect.ValueOf(out).Elem()
+	for _, r := range q.table.records {
+		if r.matches(q.matches) {
+			elem := reflect.New(slice.Type().Elem().Elem())
+			if err := r.decode(elem.Interface()); err != nil {
+				return err
+			}
+			slice = reflect.Append(slice, elem)
+		}
+	}
+	reflect.ValueOf(out).Elem().Set(slice)
+	return nil
+}
+
+type Put struct {
+	table  *table
+	record *record
+	err    error
+	cond   *condition
+}
+
+func (p *Put) If(expr string, args ...any) *Put {
+	p.cond = &condition{expr, args}
+	return p
+}
+
+func (p *Put) Run(ctx context.Context) error {
+	if p.err != nil {
+		return p.err
+	}
+	i := p.table.find(p.table.key(p.record))
+	if err := p.cond.check(p.table.at(i)); err != nil {
+		return err
+	}
+	p.table.store(i, p.record)
+	return nil
+}
+
+type Delete struct {
+	table   *table
+	matches []match
+}
+
+func (d *Delete) Range(name string, value any) *Delete {
+	d.matches = append(d.matches, match{name, value})
+	return d
+}
+
+func (d *Delete) Run(ctx context.Context) error {
+	if i := d.table.find(d.matches); i >= 0 {
+		d.table.records = append(d.table.records[:i], d.table.records[i+1:]...)
+	}
+	return nil
+}
+
+type Update struct {
+	table   *table
+	matches []match
+	set     []match
+	remove  []string
+	cond    *condition
+}
+
+func (u *Update) Range(name string, value any) *Update {
+	u.matches = append(u.matches, match{name, value})
+	return u
+}
+
+func (u *Update) Set(path string, value any) *Update {
+	u.set = append(u.set, match{path, value})
+	return u
+}
+
+func (u *Update) Remove(paths ...string) *Update {
+	u.remove = append(u.remove, paths...)
+	return u
+}
+
+func (u *Update) If(expr string, args ...any) *Update {
+	u.cond = &condition{expr, args}
+	return u
+}
+
+func (u *Update) Value(ctx context.Context, out any) error {
+	i := u.table.find(u.matches)
+	stored := u.table.at(i)
+	if err := u.cond.check(stored); err != nil {
+		return err
+	}
+
+	// The item is created from its key if missing, stored like out would be
+	var r *record
+	switch {
+	case stored != nil:
+		r = stored.clone()
+	case isItemMarshaler(out):
+		r = &record{item: Item{}}
+	default:
+		r = &record{msg: proto.Clone(out.(proto.Message))}
+		proto.Reset(r.msg)
+	}
+	if stored == nil {
+		for _, m := range u.matches {
+			if err := r.set(m.name, m.value); err != nil {
+				return err
+			}
+		}
+	}
+	for _, m := range u.set {
+		if err := r.set(m.name, m.value); err != nil {
+			return err
+		}
+	}
+	for _, name := range u.remove {
+		r.remove(name)
+	}
+	u.table.store(i, r)
+	return r.decode(out)
+}
+
+// match is an attribute value.
+type match struct {
+	name  string
+	value any
+}
+
+// record is a stored item: the attribute values of an ItemMarshaler, or a
+// message.
+type record struct {
+	item Item
+	msg  proto.Message
+}
+
+func newRecord(v any) (*record, error) {
+	if isItemMarshaler(v) {
+		item, err := v.(ItemMarshaler).MarshalDynamoItem()
+		if err != nil {
+			return nil, err
+		}
+		return &record{item: item}, nil
+	}
+	return &record{msg: proto.Clone(v.(proto.Message))}, nil
+}
+
+// isItemMarshaler reports whether v is stored as attribute values.
+func isItemMarshaler(v any) bool {
+	_, marshaler := v.(ItemMarshaler)
+	_, unmarshaler := v.(ItemUnmarshaler)
+	return marshaler && unmarshaler
+}
+
+func (r *record) clone() *record {
+	if r.item != nil {
+		return &record{item: maps.Clone(r.item)}
+	}
+	return &record{msg: proto.Clone(r.msg)}
+}
+
+// decode sets out, a pointer to a message, to the item of r.
+func (r *record) decode(out any) error {
+	if r.item != nil {
+		return out.(ItemUnmarshaler).UnmarshalDynamoItem(maps.Clone(r.item))
+	}
+	msg := out.(proto.Message)
+	proto.Reset(msg)
+	proto.Merge(msg, r.msg)
+	return nil
+}
+
+// attr returns the value of an attribute of r, and whether it is set.
+func (r *record) attr(name string) (any, bool) {
+	if r.item != nil {
+		av, ok := r.item[name]
+		return av, ok
+	}
+	msg := r.msg.ProtoReflect()
+	fd := field(msg, name)
+	if fd == nil {
+		return nil, false
+	}
+	return msg.Get(fd).Interface(), msg.Has(fd)
+}
+
+func (r *record) matches(values []match) bool {
+	for _, m := range values {
+		if value, _ := r.attr(m.name); !equal(value, m.value) {
+			return false
+		}
+	}
+	return true
+}
+
+// set sets an attribute of r. Like guregu/dynamo, values of other types than
+// types.AttributeValue are encoded by reflection.
+func (r *record) set(name string, value any) error {
+	if r.item != nil {
+		av, err := encode(reflect.ValueOf(value))
+		if err != nil {
+			return fmt.Errorf("dynamo: %s: %w", name, err)
+		}
+		r.item[name] = av
+		return nil
+	}
+
+	msg := r.msg.ProtoReflect()
+	fd := field(msg, name)
+	if fd == nil {
+		panic(fmt.Sprintf("dynamo: %s has no attribute %q", msg.Descriptor().FullName(), name))
+	}
+	switch v := value.(type) {
+	case protoreflect.Enum:
+		msg.Set(fd, protoreflect.ValueOfEnum(v.Number()))
+	case proto.Message:
+		if !v.ProtoReflect().IsValid() {
+			msg.Clear(fd)
+			return nil
+		}
+		msg.Set(fd, protoreflect.ValueOfMessage(v.ProtoReflect()))
+	default:
+		msg.Set(fd, protoreflect.ValueOf(value))
+	}
+	return nil
+}
+
+// remove removes an attribute of r.
+func (r *record) remove(name string) {
+	if r.item != nil {
+		delete(r.item, name)
+		return
+	}
+	msg := r.msg.ProtoReflect()
+	if fd := field(msg, name); fd != nil {
+		msg.Clear(fd)
+	}
+}
+
+// encode returns the attribute value of v: structs, such as messages, are
+// maps of their exported fields.
+func encode(v reflect.Value) (types.AttributeValue, error) {
+	if !v.IsValid() {
+		return &types.AttributeValueMemberNULL{Value: true}, nil
+	}
+	if av, ok := v.Interface().(types.AttributeValue); ok {
+		return av, nil
+	}
+	if e, ok := v.Interface().(protoreflect.Enum); ok {
+		return &types.AttributeValueMemberN{Value: strconv.Itoa(int(e.Number()))}, nil
+	}
+	switch v.Kind() {
+	case reflect.String:
+		return &types.AttributeValueMemberS{Value: v.String()}, nil
+	case reflect.Bool:
+		return &types.AttributeValueMemberBOOL{Value: v.Bool()}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &types.AttributeValueMemberN{Value: strconv.FormatInt(v.Int(), 10)}, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &types.AttributeValueMemberN{Value: strconv.FormatUint(v.Uint(), 10)}, nil
+	case reflect.Float32, reflect.Float64:
+		return &types.AttributeValueMemberN{Value: strconv.FormatFloat(v.Float(), 'g', -1, 64)}, nil
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return &types.AttributeValueMemberB{Value: v.Bytes()}, nil
+		}
+	case reflect.Pointer:
+		if v.IsNil() {
+			return &types.AttributeValueMemberNULL{Value: true}, nil
+		}
+		return encode(v.Elem())
+	case reflect.Struct:
+		m := map[string]types.AttributeValue{}
+		for i := 0; i < v.NumField(); i++ {
+			if f := v.Type().Field(i); f.IsExported() {
+				av, err := encode(v.Field(i))
+				if err != nil {
+					return nil, err
+				}
+				m[f.Name] = av
+			}
+		}
+		return &types.AttributeValueMemberM{Value: m}, nil
+	}
+	return nil, fmt.Errorf("unsupported type %s", v.Type())
+}
+
+// key returns the key attribute values of r.
+func (t *table) key(r *record) []match {
+	var key []match
+	for _, name := range []string{t.hashKey, t.rangeKey} {
+		if name != "" {
+			value, _ := r.attr(name)
+			key = append(key, match{name, value})
+		}
+	}
+	return key
+}
+
+// find returns the index of the first record with the given attribute values, or -1.
+func (t *table) find(values []match) int {
+	for i, r := range t.records {
+		if r.matches(values) {
+			return i
+		}
+	}
+	return -1
+}
+
+// at returns the record at index i, or nil if i is -1.
+func (t *table) at(i int) *record {
+	if i < 0 {
+		return nil
+	}
+	return t.records[i]
+}
+
+// store replaces the record at index i, or adds r if i is -1.
+func (t *table) store(i int, r *record) {
+	if i < 0 {
+		t.records = append(t.records, r)
+		return
+	}
+	t.records[i] = r
+}
+
+// field returns the field of an attribute of msg, or nil.
+func field(msg protoreflect.Message, name string) protoreflect.FieldDescriptor {
+	fields := msg.Descriptor().Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		if string(fd.Name()) == name || strings.EqualFold(strings.ReplaceAll(string(fd.Name()), "_", ""), name) {
+			return fd
+		}
+	}
+	return nil
+}
+
+// equal compares attribute values of any scalar, enum or attribute value type.
+func equal(a, b any) bool {
+	return format(a) == format(b)
+}
+
+func format(v any) string {
+	switch v := v.(type) {
+	case protoreflect.Enum:
+		return fmt.Sprint(v.Number())
+	case *types.AttributeValueMemberS:
+		return v.Value
+	case *types.AttributeValueMemberN:
+		return v.Value
+	}
+	return fmt.Sprint(v)
+}
+
+// condition is the condition of a write.
+// Supported: "$ = ?", "attribute_not_exists($)" and their disjunctions.
+type condition struct {
+	expr string
+	args []any
+}
+
+// check returns a *types.ConditionalCheckFailedException unless the stored
+// record, nil if none, satisfies c.
+func (c *condition) check(stored *record) error {
+	if c == nil {
+		return nil
+	}
+
+	args := c.args
+	for _, clause := range strings.Split(c.expr, " OR ") {
+		switch clause {
+		case "attribute_not_exists($)":
+			name := args[0].(string)
+			args = args[1:]
+			if stored == nil {
+				return nil
+			}
+			if _, ok := stored.attr(name); !ok {
+				return nil
+			}
+		case "$ = ?":
+			name, want := args[0].(string), args[1]
+			args = args[2:]
+			if stored == nil {
+				continue
+			}
+			if value, _ := stored.attr(name); equal(value, want) {
+				return nil
+			}
+		default:
+			panic(fmt.Sprintf("dynamo: unsupported condition %q", c.expr))
+		}
+	}
+	return &types.ConditionalCheckFailedException{Message: aws.String("The conditional request failed")}
+}
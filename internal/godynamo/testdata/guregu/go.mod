@@ -0,0 +1,11 @@
+module github.com/guregu/dynamo/v2
+
+go 1.23
+
+require (
+	github.com/aws/aws-sdk-go-v2 v1.41.0
+	github.com/aws/aws-sdk-go-v2/service/dynamodb v1.53.5
+	google.golang.org/protobuf v1.36.10
+)
+
+require github.com/aws/smithy-go v1.24.0 // indirect
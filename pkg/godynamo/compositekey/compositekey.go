@@ -0,0 +1,265 @@
+// Package compositekey computes and parses composite key attributes.
+//
+// It backs the composite key functions generated by protoc-gen-go-dynamo for
+// (dynamo.composite_key) options. A template mixes literals and {field}
+// proto field names, e.g. "ORDER#{created_at:20}#{id}":
+// - Strings are copied as is
+// - Integers are decimal, zero-padded to the width after ":" if any
+// - Padded non-negative integers sort as strings, negative ones do not
+// - Enums are their value name (e.g., "STATUS_ACTIVE")
+//
+// A field value extends to the next literal when parsing, so string values
+// must not contain the literal following their field.
+package compositekey
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Template is a parsed composite key template.
+type Template struct {
+	text     string
+	segments []segment
+}
+
+// segment is a literal, or a field if field is not empty.
+type segment struct {
+	literal string
+	field   protoreflect.Name
+	width   int // Zero-padding width of integers (0 if none)
+}
+
+// Parse parses a template. Fields must be separated by literals.
+func Parse(text string) (*Template, error) {
+	t := &Template{text: text}
+	for rest := text; rest != ""; {
+		open := strings.IndexByte(rest, '{')
+		if open < 0 {
+			t.segments = append(t.segments, segment{literal: rest})
+			break
+		}
+		if open > 0 {
+			t.segments = append(t.segments, segment{literal: rest[:open]})
+		}
+		end := strings.IndexByte(rest[open:], '}')
+		if end < 0 {
+			return nil, fmt.Errorf("template %q: unclosed {", text)
+		}
+
+		field := segment{}
+		name, width, padded := strings.Cut(rest[open+1:open+end], ":")
+		field.field = protoreflect.Name(name)
+		if !field.field.IsValid() {
+			return nil, fmt.Errorf("template %q: invalid field name %q", text, name)
+		}
+		if padded {
+			w, err := strconv.Atoi(width)
+			if err != nil || w <= 0 {
+				return nil, fmt.Errorf("template %q: invalid width %q of field %s", text, width, name)
+			}
+			field.width = w
+		}
+		if n := len(t.segments); n > 0 && t.segments[n-1].field != "" {
+			return nil, fmt.Errorf("template %q: fields %s and %s must be separated by a literal", text, t.segments[n-1].field, name)
+		}
+		t.segments = append(t.segments, field)
+		rest = rest[open+end+1:]
+	}
+	if len(t.segments) == 0 {
+		return nil, fmt.Errorf("empty template")
+	}
+	return t, nil
+}
+
+// MustParse is like Parse but panics on error. It initializes the templates of
+// generated code.
+func MustParse(text string) *Template {
+	t, err := Parse(text)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func (t *Template) String() string {
+	return t.text
+}
+
+// Fields returns the proto field names of the template, in order.
+func (t *Template) Fields() []string {
+	var fields []string
+	for _, s := range t.segments {
+		if s.field != "" {
+			fields = append(fields, string(s.field))
+		}
+	}
+	return fields
+}
+
+// Prefix returns the literal prefix of the template, before its first field.
+// Example: "ORDER#" for "ORDER#{id}"
+func (t *Template) Prefix() string {
+	if t.segments[0].field != "" {
+		return ""
+	}
+	return t.segments[0].literal
+}
+
+// Format returns the attribute of msg.
+func (t *Template) Format(msg proto.Message) string {
+	m := msg.ProtoReflect()
+	var b strings.Builder
+	for _, s := range t.segments {
+		if s.field == "" {
+			b.WriteString(s.literal)
+			continue
+		}
+		fd := m.Descriptor().Fields().ByName(s.field)
+		if fd == nil {
+			panic(fmt.Sprintf("template %q: %s has no field %s", t.text, m.Descriptor().FullName(), s.field))
+		}
+		b.WriteString(formatValue(fd, m.Get(fd), s.width))
+	}
+	return b.String()
+}
+
+// FormatValues returns the attribute of the given field values, in template
+// order: strings, integers or enums (protoreflect.Enum).
+func (t *Template) FormatValues(values ...any) string {
+	var b strings.Builder
+	i := 0
+	for _, s := range t.segments {
+		if s.field == "" {
+			b.WriteString(s.literal)
+			continue
+		}
+		if i >= len(values) {
+			panic(fmt.Sprintf("template %q: missing value of field %s", t.text, s.field))
+		}
+		b.WriteString(formatAny(values[i], s.width))
+		i++
+	}
+	return b.String()
+}
+
+// Parse sets the fields of msg from an attribute.
+func (t *Template) Parse(value string, msg proto.Message) error {
+	m := msg.ProtoReflect()
+	rest := value
+	for i, s := range t.segments {
+		if s.field == "" {
+			if !strings.HasPrefix(rest, s.literal) {
+				return fmt.Errorf("attribute %q does not match template %q", value, t.text)
+			}
+			rest = rest[len(s.literal):]
+			continue
+		}
+
+		// A field extends to the next literal, or to the end
+		part := rest
+		if i+1 < len(t.segments) {
+			end := strings.Index(rest, t.segments[i+1].literal)
+			if end < 0 {
+				return fmt.Errorf("attribute %q does not match template %q", value, t.text)
+			}
+			part = rest[:end]
+		}
+		rest = rest[len(part):]
+
+		fd := m.Descriptor().Fields().ByName(s.field)
+		if fd == nil {
+			return fmt.Errorf("template %q: %s has no field %s", t.text, m.Descriptor().FullName(), s.field)
+		}
+		v, err := parseValue(fd, part)
+		if err != nil {
+			return fmt.Errorf("attribute %q: field %s: %w", value, s.field, err)
+		}
+		m.Set(fd, v)
+	}
+	if rest != "" {
+		return fmt.Errorf("attribute %q does not match template %q", value, t.text)
+	}
+	return nil
+}
+
+func formatValue(fd protoreflect.FieldDescriptor, v protoreflect.Value, width int) string {
+	switch fd.Kind() {
+	case protoreflect.StringKind:
+		return v.String()
+	case protoreflect.EnumKind:
+		if ev := fd.Enum().Values().ByNumber(v.Enum()); ev != nil {
+			return string(ev.Name())
+		}
+		return strconv.Itoa(int(v.Enum()))
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind, protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return pad(strconv.FormatUint(v.Uint(), 10), width)
+	default:
+		return pad(strconv.FormatInt(v.Int(), 10), width)
+	}
+}
+
+func formatAny(v any, width int) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case protoreflect.Enum:
+		if ev := v.Descriptor().Values().ByNumber(v.Number()); ev != nil {
+			return string(ev.Name())
+		}
+		return strconv.Itoa(int(v.Number()))
+	case int32:
+		return pad(strconv.FormatInt(int64(v), 10), width)
+	case int64:
+		return pad(strconv.FormatInt(v, 10), width)
+	case uint32:
+		return pad(strconv.FormatUint(uint64(v), 10), width)
+	case uint64:
+		return pad(strconv.FormatUint(v, 10), width)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func parseValue(fd protoreflect.FieldDescriptor, s string) (protoreflect.Value, error) {
+	switch fd.Kind() {
+	case protoreflect.StringKind:
+		return protoreflect.ValueOfString(s), nil
+	case protoreflect.EnumKind:
+		if ev := fd.Enum().Values().ByName(protoreflect.Name(s)); ev != nil {
+			return protoreflect.ValueOfEnum(ev.Number()), nil
+		}
+		n, err := strconv.ParseInt(s, 10, 32)
+		return protoreflect.ValueOfEnum(protoreflect.EnumNumber(n)), err
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		n, err := strconv.ParseInt(s, 10, 32)
+		return protoreflect.ValueOfInt32(int32(n)), err
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		n, err := strconv.ParseInt(s, 10, 64)
+		return protoreflect.ValueOfInt64(n), err
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		n, err := strconv.ParseUint(s, 10, 32)
+		return protoreflect.ValueOfUint32(uint32(n)), err
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		n, err := strconv.ParseUint(s, 10, 64)
+		return protoreflect.ValueOfUint64(n), err
+	default:
+		return protoreflect.Value{}, fmt.Errorf("unsupported %s field", fd.Kind())
+	}
+}
+
+// pad zero-pads a decimal number to width, after its sign.
+func pad(n string, width int) string {
+	sign := ""
+	if strings.HasPrefix(n, "-") {
+		sign, n = "-", n[1:]
+	}
+	if len(n) < width {
+		n = strings.Repeat("0", width-len(n)) + n
+	}
+	return sign + n
+}
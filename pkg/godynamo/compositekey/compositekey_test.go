@@ -0,0 +1,55 @@
+package compositekey
+
+import (
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+func TestTemplate(t *testing.T) {
+	tmpl := MustParse("FIELD#{name}#{number:5}#{label}")
+	if tmpl.Prefix() != "FIELD#" || len(tmpl.Fields()) != 3 {
+		t.Errorf("Prefix() = %q, Fields() = %v", tmpl.Prefix(), tmpl.Fields())
+	}
+
+	f := &descriptorpb.FieldDescriptorProto{
+		Name:   proto.String("id"),
+		Number: proto.Int32(42),
+		Label:  descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum(),
+	}
+	want := "FIELD#id#00042#LABEL_REPEATED"
+	if got := tmpl.Format(f); got != want {
+		t.Errorf("Format() = %q, want %q", got, want)
+	}
+	if got := tmpl.FormatValues("id", int32(42), descriptorpb.FieldDescriptorProto_LABEL_REPEATED); got != want {
+		t.Errorf("FormatValues() = %q, want %q", got, want)
+	}
+
+	got := &descriptorpb.FieldDescriptorProto{}
+	if err := tmpl.Parse(want, got); err != nil || !proto.Equal(got, f) {
+		t.Errorf("Parse() = %v, %v", got, err)
+	}
+	for _, bad := range []string{"USER#id#1#LABEL_REPEATED", "FIELD#id#x#LABEL_REPEATED", "FIELD#id"} {
+		if err := tmpl.Parse(bad, got); err == nil {
+			t.Errorf("Parse(%q) succeeded", bad)
+		}
+	}
+
+	for _, bad := range []string{"", "A#{name", "{name}{number}", "{1x}", "{number:x}"} {
+		if _, err := Parse(bad); err == nil {
+			t.Errorf("Parse(%q) succeeded", bad)
+		}
+	}
+}
+
+func TestKeyCondition(t *testing.T) {
+	c := KeyCondition{Index: "gsi1", HashName: "GSI1PK", HashValue: "ORG#1"}.Range("GSI1SK", Between, "A", "B")
+	expr, names, values := c.Expression()
+	if expr != "#h = :h AND #r BETWEEN :r0 AND :r1" || names["#r"] != "GSI1SK" || len(values) != 3 {
+		t.Errorf("Expression() = %q, %v, %v", expr, names, values)
+	}
+	if input := c.QueryInput("app"); *input.IndexName != "gsi1" || *input.KeyConditionExpression != expr {
+		t.Errorf("QueryInput() = %+v", input)
+	}
+}
@@ -0,0 +1,88 @@
+package compositekey
+
+import (
+	"strconv"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// KeyCondition is the key condition of a query on a table or index: an
+// equality on the hash key and, optionally, a condition on the range key.
+//
+// With guregu/dynamo:
+//
+//	query := table.Get(c.HashName, c.HashValue).Index(c.Index)
+//	if c.RangeName != "" {
+//		query.Range(c.RangeName, dynamo.BeginsWith, c.RangeValues[0])
+//	}
+type KeyCondition struct {
+	Index     string // Index name, "" for the table
+	HashName  string // Hash key attribute name
+	HashValue string // Hash key attribute value
+
+	RangeName   string   // Range key attribute name, "" without range condition
+	RangeOp     RangeOp  // Range key operator
+	RangeValues []string // Range key operands: two with Between, one otherwise
+}
+
+// RangeOp is a key condition operator on a range key.
+type RangeOp string
+
+const (
+	Equal      RangeOp = "="
+	Less       RangeOp = "<"
+	LessOrEq   RangeOp = "<="
+	Greater    RangeOp = ">"
+	GreaterEq  RangeOp = ">="
+	BeginsWith RangeOp = "begins_with"
+	Between    RangeOp = "BETWEEN"
+)
+
+// Range returns c with a condition on the range key rangeName.
+func (c KeyCondition) Range(rangeName string, op RangeOp, values ...string) KeyCondition {
+	c.RangeName, c.RangeOp, c.RangeValues = rangeName, op, values
+	return c
+}
+
+// Expression returns the key condition expression of c, with its attribute
+// names and values.
+// Example: "#h = :h AND begins_with(#r, :r0)"
+func (c KeyCondition) Expression() (string, map[string]string, map[string]types.AttributeValue) {
+	expr := "#h = :h"
+	names := map[string]string{"#h": c.HashName}
+	values := map[string]types.AttributeValue{":h": &types.AttributeValueMemberS{Value: c.HashValue}}
+	if c.RangeName == "" {
+		return expr, names, values
+	}
+
+	names["#r"] = c.RangeName
+	for i, v := range c.RangeValues {
+		values[":r"+strconv.Itoa(i)] = &types.AttributeValueMemberS{Value: v}
+	}
+	switch c.RangeOp {
+	case BeginsWith:
+		expr += " AND begins_with(#r, :r0)"
+	case Between:
+		expr += " AND #r BETWEEN :r0 AND :r1"
+	default:
+		expr += " AND #r " + string(c.RangeOp) + " :r0"
+	}
+	return expr, names, values
+}
+
+// QueryInput returns the input of a query of the table with condition c.
+func (c KeyCondition) QueryInput(tableName string) *dynamodb.QueryInput {
+	expr, names, values := c.Expression()
+	input := &dynamodb.QueryInput{
+		TableName:                 aws.String(tableName),
+		KeyConditionExpression:    aws.String(expr),
+		ExpressionAttributeNames:  names,
+		ExpressionAttributeValues: values,
+	}
+	if c.Index != "" {
+		input.IndexName = aws.String(c.Index)
+	}
+	return input
+}
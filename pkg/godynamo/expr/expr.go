@@ -0,0 +1,310 @@
+// Package expr builds DynamoDB expressions from typed attributes.
+//
+// It backs the attributes generated by protoc-gen-go-dynamo (expr=true): each
+// annotated message gets an Attr per scalar field, typed with the Go type of
+// the field, so conditions and updates are checked at compile time:
+//
+//	attrs := pb.UserDynamoAttrs
+//	e, err := expr.Builder{
+//		KeyCondition: attrs.Email.Equal("a@example.com"),
+//		Filter:       expr.And(attrs.Status.Equal(pb.Status_STATUS_ACTIVE), attrs.Nickname.Exists()),
+//	}.Build()
+//
+// Attribute names are always placeholders (#n0, #n1, ...), values are
+// encoded as the item marshalers do:
+// - Strings are S, bytes are B and booleans are BOOL
+// - Numbers and enums are N
+package expr
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Path is an attribute of any type: exists conditions and removal only.
+type Path struct {
+	name string
+}
+
+// NewPath returns the attribute with the given name.
+func NewPath(name string) Path {
+	return Path{name: name}
+}
+
+// Name returns the attribute name.
+func (p Path) Name() string {
+	return p.name
+}
+
+// Exists returns the condition attribute_exists(p).
+func (p Path) Exists() Condition {
+	return function("attribute_exists", p)
+}
+
+// NotExists returns the condition attribute_not_exists(p).
+func (p Path) NotExists() Condition {
+	return function("attribute_not_exists", p)
+}
+
+// Remove returns the update action REMOVE p.
+func (p Path) Remove() Action {
+	return Action{kind: remove, path: p}
+}
+
+// Attr is a scalar attribute holding T values: strings, numbers, booleans,
+// bytes or enums.
+type Attr[T any] struct {
+	Path
+}
+
+// NewAttr returns the attribute with the given name.
+func NewAttr[T any](name string) Attr[T] {
+	return Attr[T]{Path: NewPath(name)}
+}
+
+// Equal returns the condition a = v.
+func (a Attr[T]) Equal(v T) Condition { return compare(a.Path, "=", v) }
+
+// NotEqual returns the condition a <> v.
+func (a Attr[T]) NotEqual(v T) Condition { return compare(a.Path, "<>", v) }
+
+// Less returns the condition a < v.
+func (a Attr[T]) Less(v T) Condition { return compare(a.Path, "<", v) }
+
+// LessOrEq returns the condition a <= v.
+func (a Attr[T]) LessOrEq(v T) Condition { return compare(a.Path, "<=", v) }
+
+// Greater returns the condition a > v.
+func (a Attr[T]) Greater(v T) Condition { return compare(a.Path, ">", v) }
+
+// GreaterOrEq returns the condition a >= v.
+func (a Attr[T]) GreaterOrEq(v T) Condition { return compare(a.Path, ">=", v) }
+
+// Between returns the condition a BETWEEN lo AND hi, bounds included.
+func (a Attr[T]) Between(lo, hi T) Condition {
+	return Condition{build: func(b *builder) string {
+		return fmt.Sprintf("%s BETWEEN %s AND %s", b.name(a.Path), b.value(lo), b.value(hi))
+	}}
+}
+
+// Set returns the update action SET a = v.
+func (a Attr[T]) Set(v T) Action {
+	return Action{kind: set, path: a.Path, value: v}
+}
+
+// SetIfNotExists returns the update action SET a = if_not_exists(a, v).
+func (a Attr[T]) SetIfNotExists(v T) Action {
+	return Action{kind: setIfNotExists, path: a.Path, value: v}
+}
+
+// BeginsWith returns the condition begins_with(a, prefix), on string attributes.
+func BeginsWith[T ~string](a Attr[T], prefix T) Condition {
+	return Condition{build: func(b *builder) string {
+		return fmt.Sprintf("begins_with(%s, %s)", b.name(a.Path), b.value(prefix))
+	}}
+}
+
+// Number is the type of number attributes.
+type Number interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 | ~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~float32 | ~float64
+}
+
+// Add returns the update action ADD a v, which adds v to a number attribute
+// (0 if missing).
+func Add[T Number](a Attr[T], v T) Action {
+	return Action{kind: add, path: a.Path, value: v}
+}
+
+// Condition is a condition expression. The zero Condition is no condition.
+type Condition struct {
+	build func(b *builder) string
+}
+
+// IsZero reports whether c is the zero Condition.
+func (c Condition) IsZero() bool {
+	return c.build == nil
+}
+
+// And returns the conjunction of conditions, ignoring zero conditions.
+func And(conds ...Condition) Condition {
+	return join("AND", conds)
+}
+
+// Or returns the disjunction of conditions, ignoring zero conditions.
+func Or(conds ...Condition) Condition {
+	return join("OR", conds)
+}
+
+// Not returns the negation of c, or the zero Condition if c is zero.
+func Not(c Condition) Condition {
+	if c.IsZero() {
+		return Condition{}
+	}
+	return Condition{build: func(b *builder) string {
+		return "NOT (" + c.build(b) + ")"
+	}}
+}
+
+func join(op string, conds []Condition) Condition {
+	var nonZero []Condition
+	for _, c := range conds {
+		if !c.IsZero() {
+			nonZero = append(nonZero, c)
+		}
+	}
+	switch len(nonZero) {
+	case 0:
+		return Condition{}
+	case 1:
+		return nonZero[0]
+	}
+	return Condition{build: func(b *builder) string {
+		parts := make([]string, len(nonZero))
+		for i, c := range nonZero {
+			parts[i] = "(" + c.build(b) + ")"
+		}
+		return strings.Join(parts, " "+op+" ")
+	}}
+}
+
+func compare(p Path, op string, v any) Condition {
+	return Condition{build: func(b *builder) string {
+		return fmt.Sprintf("%s %s %s", b.name(p), op, b.value(v))
+	}}
+}
+
+func function(name string, p Path) Condition {
+	return Condition{build: func(b *builder) string {
+		return fmt.Sprintf("%s(%s)", name, b.name(p))
+	}}
+}
+
+// Builder holds the expressions of a request. Zero fields are omitted.
+type Builder struct {
+	KeyCondition Condition // Query key condition
+	Filter       Condition // Query or Scan filter
+	Condition    Condition // Put, Update or Delete condition
+	Update       Update    // UpdateItem actions
+	Projection   []Path    // Attributes to return
+}
+
+// Expressions are the expressions of a request, with the attribute names and
+// values they share. Their fields are the fields of the aws-sdk-go-v2 inputs:
+//
+//	input := &dynamodb.QueryInput{
+//		TableName:                 aws.String(pb.UserTableName),
+//		KeyConditionExpression:    e.KeyCondition,
+//		FilterExpression:          e.Filter,
+//		ExpressionAttributeNames:  e.Names,
+//		ExpressionAttributeValues: e.Values,
+//	}
+type Expressions struct {
+	KeyCondition *string
+	Filter       *string
+	Condition    *string
+	Update       *string
+	Projection   *string
+	Names        map[string]string               // nil without attribute
+	Values       map[string]types.AttributeValue // nil without value
+}
+
+// Build returns the expressions of b. It fails on values of unsupported types.
+func (b Builder) Build() (Expressions, error) {
+	bb := &builder{placeholders: map[string]string{}}
+	var e Expressions
+	build := func(c Condition) *string {
+		if c.IsZero() {
+			return nil
+		}
+		s := c.build(bb)
+		return &s
+	}
+	e.KeyCondition = build(b.KeyCondition)
+	e.Filter = build(b.Filter)
+	e.Condition = build(b.Condition)
+	if len(b.Update) > 0 {
+		s := b.Update.build(bb)
+		e.Update = &s
+	}
+	if len(b.Projection) > 0 {
+		names := make([]string, len(b.Projection))
+		for i, p := range b.Projection {
+			names[i] = bb.name(p)
+		}
+		s := strings.Join(names, ", ")
+		e.Projection = &s
+	}
+	if bb.err != nil {
+		return Expressions{}, bb.err
+	}
+	e.Names, e.Values = bb.names, bb.values
+	return e, nil
+}
+
+// builder allocates the placeholders of attribute names and values.
+type builder struct {
+	placeholders map[string]string // Attribute name -> placeholder
+	names        map[string]string
+	values       map[string]types.AttributeValue
+	err          error
+}
+
+// name returns the placeholder of the attribute of p, shared by its uses.
+func (b *builder) name(p Path) string {
+	if ph, ok := b.placeholders[p.name]; ok {
+		return ph
+	}
+	ph := "#n" + strconv.Itoa(len(b.placeholders))
+	b.placeholders[p.name] = ph
+	if b.names == nil {
+		b.names = map[string]string{}
+	}
+	b.names[ph] = p.name
+	return ph
+}
+
+// value returns the placeholder of v.
+func (b *builder) value(v any) string {
+	ph := ":v" + strconv.Itoa(len(b.values))
+	av, err := encode(v)
+	if err != nil && b.err == nil {
+		b.err = err
+	}
+	if b.values == nil {
+		b.values = map[string]types.AttributeValue{}
+	}
+	b.values[ph] = av
+	return ph
+}
+
+// encode returns the attribute value of a scalar.
+func encode(v any) (types.AttributeValue, error) {
+	switch v := v.(type) {
+	case protoreflect.Enum:
+		return &types.AttributeValueMemberN{Value: strconv.Itoa(int(v.Number()))}, nil
+	case []byte:
+		return &types.AttributeValueMemberB{Value: v}, nil
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.String:
+		return &types.AttributeValueMemberS{Value: rv.String()}, nil
+	case reflect.Bool:
+		return &types.AttributeValueMemberBOOL{Value: rv.Bool()}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &types.AttributeValueMemberN{Value: strconv.FormatInt(rv.Int(), 10)}, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &types.AttributeValueMemberN{Value: strconv.FormatUint(rv.Uint(), 10)}, nil
+	case reflect.Float32, reflect.Float64:
+		// float32 values in their shortest float32 form, as the item marshalers
+		return &types.AttributeValueMemberN{Value: strconv.FormatFloat(rv.Float(), 'g', -1, rv.Type().Bits())}, nil
+	default:
+		return nil, fmt.Errorf("unsupported value of type %T", v)
+	}
+}
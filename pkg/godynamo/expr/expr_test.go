@@ -0,0 +1,104 @@
+package expr
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func TestBuild(t *testing.T) {
+	id := NewAttr[string]("ID")
+	createdAt := NewAttr[int64]("created_at")
+	state := NewAttr[structpb.NullValue]("State")
+	visits := NewAttr[uint32]("Visits")
+	note := NewPath("Note")
+
+	e, err := Builder{
+		KeyCondition: And(id.Equal("u1"), createdAt.Between(10, 20)),
+		Filter:       Or(Not(state.Equal(structpb.NullValue_NULL_VALUE)), BeginsWith(id, "u"), Condition{}),
+		Condition:    note.NotExists(),
+		Update:       Update{Add(visits, 1), id.SetIfNotExists("u2"), note.Remove(), createdAt.Set(30)},
+		Projection:   []Path{id.Path, note},
+	}.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		got  *string
+		want string
+	}{
+		{e.KeyCondition, "(#n0 = :v0) AND (#n1 BETWEEN :v1 AND :v2)"},
+		{e.Filter, "(NOT (#n2 = :v3)) OR (begins_with(#n0, :v4))"},
+		{e.Condition, "attribute_not_exists(#n3)"},
+		{e.Update, "SET #n0 = if_not_exists(#n0, :v6), #n1 = :v7 ADD #n4 :v5 REMOVE #n3"},
+		{e.Projection, "#n0, #n3"},
+	} {
+		if tt.got == nil || *tt.got != tt.want {
+			t.Errorf("expression = %v, want %q", tt.got, tt.want)
+		}
+	}
+
+	wantNames := map[string]string{"#n0": "ID", "#n1": "created_at", "#n2": "State", "#n3": "Note", "#n4": "Visits"}
+	if !reflect.DeepEqual(e.Names, wantNames) {
+		t.Errorf("Names = %v, want %v", e.Names, wantNames)
+	}
+	wantValues := map[string]types.AttributeValue{
+		":v0": &types.AttributeValueMemberS{Value: "u1"},
+		":v1": &types.AttributeValueMemberN{Value: "10"},
+		":v2": &types.AttributeValueMemberN{Value: "20"},
+		":v3": &types.AttributeValueMemberN{Value: "0"},
+		":v4": &types.AttributeValueMemberS{Value: "u"},
+		":v5": &types.AttributeValueMemberN{Value: "1"},
+		":v6": &types.AttributeValueMemberS{Value: "u2"},
+		":v7": &types.AttributeValueMemberN{Value: "30"},
+	}
+	if !reflect.DeepEqual(e.Values, wantValues) {
+		t.Errorf("Values = %v, want %v", e.Values, wantValues)
+	}
+}
+
+func TestBuildEmpty(t *testing.T) {
+	e, err := Builder{Filter: And()}.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Filter != nil || e.Names != nil || e.Values != nil {
+		t.Errorf("Build() = %+v, want no expression", e)
+	}
+}
+
+func TestBuildUnsupported(t *testing.T) {
+	if _, err := (Builder{Condition: NewAttr[[]string]("Tags").Equal([]string{"a"})}).Build(); err == nil {
+		t.Error("Build() succeeded with a list value")
+	}
+}
+
+func TestBuildZeroNot(t *testing.T) {
+	e, err := Builder{Filter: Not(Condition{}), Condition: And(Not(And()), NewPath("ID").Exists())}.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Filter != nil {
+		t.Errorf("Filter = %q, want none", *e.Filter)
+	}
+	if e.Condition == nil || *e.Condition != "attribute_exists(#n0)" {
+		t.Errorf("Condition = %v, want %q", e.Condition, "attribute_exists(#n0)")
+	}
+}
+
+func TestBuildFloat(t *testing.T) {
+	e, err := Builder{Condition: And(NewAttr[float32]("Score").Equal(0.1), NewAttr[float64]("Ratio").Equal(0.1))}.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]types.AttributeValue{
+		":v0": &types.AttributeValueMemberN{Value: "0.1"},
+		":v1": &types.AttributeValueMemberN{Value: "0.1"},
+	}
+	if !reflect.DeepEqual(e.Values, want) {
+		t.Errorf("Values = %v, want %v", e.Values, want)
+	}
+}
@@ -0,0 +1,56 @@
+package expr
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Update is the actions of an update expression, grouped by clause when
+// built: "SET #n0 = :v0, #n1 = :v1 ADD #n2 :v2 REMOVE #n3".
+//
+//	update := expr.Update{attrs.Name.Set("Ada"), expr.Add(attrs.Visits, 1), attrs.Nickname.Remove()}
+type Update []Action
+
+// Action is an update action, returned by Attr.Set, Add or Path.Remove.
+type Action struct {
+	kind  actionKind
+	path  Path
+	value any
+}
+
+type actionKind int
+
+const (
+	set actionKind = iota
+	setIfNotExists
+	add
+	remove
+)
+
+func (u Update) build(b *builder) string {
+	var sets, adds, removes []string
+	for _, a := range u {
+		switch a.kind {
+		case set:
+			sets = append(sets, fmt.Sprintf("%s = %s", b.name(a.path), b.value(a.value)))
+		case setIfNotExists:
+			name := b.name(a.path)
+			sets = append(sets, fmt.Sprintf("%s = if_not_exists(%s, %s)", name, name, b.value(a.value)))
+		case add:
+			adds = append(adds, fmt.Sprintf("%s %s", b.name(a.path), b.value(a.value)))
+		case remove:
+			removes = append(removes, b.name(a.path))
+		}
+	}
+
+	var clauses []string
+	for _, clause := range []struct {
+		keyword string
+		actions []string
+	}{{"SET", sets}, {"ADD", adds}, {"REMOVE", removes}} {
+		if len(clause.actions) > 0 {
+			clauses = append(clauses, clause.keyword+" "+strings.Join(clause.actions, ", "))
+		}
+	}
+	return strings.Join(clauses, " ")
+}
@@ -0,0 +1,545 @@
+// Package itemcodec encodes proto messages as DynamoDB items.
+//
+// It backs the MarshalDynamoItem and UnmarshalDynamoItem methods generated by
+// protoc-gen-go-dynamo (marshal=true). Unlike tag-driven reflection
+// marshalers, it reads messages through protoreflect, so generated internals
+// are never stored and:
+// - Fields with presence (messages, oneof members, optional) are stored if set
+// - Other fields are stored even when zero, unless OmitEmpty
+// - Keys are always stored, empty sets never are
+// - Enums are numbers (N), bytes are binary (B)
+// - Timestamps are RFC 3339 strings (S), or Unix seconds (N) with UnixTime
+// - Durations are nanoseconds (N)
+// - Wrappers (google.protobuf.StringValue, ...) are their value
+// - Maps and nested messages are maps (M), repeated fields lists (L) or sets
+// - Nested messages implementing Marshaler/Unmarshaler encode themselves
+package itemcodec
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Marshaler is implemented by messages encoding themselves as items.
+type Marshaler interface {
+	MarshalDynamoItem() (map[string]types.AttributeValue, error)
+}
+
+// Unmarshaler is implemented by messages decoding themselves from items.
+type Unmarshaler interface {
+	UnmarshalDynamoItem(item map[string]types.AttributeValue) error
+}
+
+// Field is the attribute of a message field.
+// Example: Field{Proto: "id", Name: "ID"}
+type Field struct {
+	Proto     string // Proto field name
+	Name      string // Attribute name
+	Key       bool   // Table key attribute: always stored
+	OmitEmpty bool   // Omit zero values, and empty lists, maps and messages
+	Set       bool   // Store a repeated field as a set (SS, NS or BS)
+	UnixTime  bool   // Store a google.protobuf.Timestamp as Unix seconds
+}
+
+// Marshal encodes msg as an item. fields lists the attributes of the message
+// fields; fields missing from the list use their Go field name.
+func Marshal(msg proto.Message, fields []Field) (map[string]types.AttributeValue, error) {
+	m := msg.ProtoReflect()
+	if !m.IsValid() {
+		return nil, nil
+	}
+
+	byName := fieldsByName(fields)
+	item := map[string]types.AttributeValue{}
+	fds := m.Descriptor().Fields()
+	for i := 0; i < fds.Len(); i++ {
+		fd := fds.Get(i)
+		field := fieldOf(fd, byName)
+		if !field.Key && fd.HasPresence() && !m.Has(fd) {
+			continue
+		}
+		v := m.Get(fd)
+		if !field.Key && field.OmitEmpty && isEmpty(fd, v) {
+			continue
+		}
+		av, err := encodeField(fd, v, field)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", field.Name, err)
+		}
+		if av != nil {
+			item[field.Name] = av
+		}
+	}
+	return item, nil
+}
+
+// Unmarshal decodes an item into msg, which is reset first. Attributes
+// without field are ignored.
+func Unmarshal(item map[string]types.AttributeValue, msg proto.Message, fields []Field) error {
+	proto.Reset(msg)
+	m := msg.ProtoReflect()
+	byName := fieldsByName(fields)
+
+	fds := m.Descriptor().Fields()
+	for i := 0; i < fds.Len(); i++ {
+		fd := fds.Get(i)
+		field := fieldOf(fd, byName)
+		av, ok := item[field.Name]
+		if !ok {
+			continue
+		}
+		if _, null := av.(*types.AttributeValueMemberNULL); null {
+			continue
+		}
+		if err := decodeField(m, fd, av, field); err != nil {
+			return fmt.Errorf("%s: %w", field.Name, err)
+		}
+	}
+	return nil
+}
+
+// fieldsByName indexes fields by proto name.
+func fieldsByName(fields []Field) map[protoreflect.Name]Field {
+	byName := make(map[protoreflect.Name]Field, len(fields))
+	for _, f := range fields {
+		byName[protoreflect.Name(f.Proto)] = f
+	}
+	return byName
+}
+
+// fieldOf returns the attribute of a field, by default named after its Go field.
+func fieldOf(fd protoreflect.FieldDescriptor, byName map[protoreflect.Name]Field) Field {
+	if f, ok := byName[fd.Name()]; ok {
+		return f
+	}
+	return Field{Proto: string(fd.Name()), Name: goCamelCase(string(fd.Name()))}
+}
+
+// isEmpty reports whether a value is a zero scalar, or an empty list, map or message.
+func isEmpty(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+	switch {
+	case fd.IsList():
+		return v.List().Len() == 0
+	case fd.IsMap():
+		return v.Map().Len() == 0
+	case fd.Message() != nil:
+		empty := true
+		v.Message().Range(func(protoreflect.FieldDescriptor, protoreflect.Value) bool {
+			empty = false
+			return false
+		})
+		return empty
+	default:
+		return v.Equal(fd.Default())
+	}
+}
+
+func encodeField(fd protoreflect.FieldDescriptor, v protoreflect.Value, field Field) (types.AttributeValue, error) {
+	switch {
+	case fd.IsMap():
+		out := map[string]types.AttributeValue{}
+		var err error
+		v.Map().Range(func(k protoreflect.MapKey, v protoreflect.Value) bool {
+			var av types.AttributeValue
+			if av, err = encodeValue(fd.MapValue(), v, field); err != nil {
+				return false
+			}
+			if av != nil {
+				out[k.String()] = av
+			}
+			return true
+		})
+		if err != nil {
+			return nil, err
+		}
+		return &types.AttributeValueMemberM{Value: out}, nil
+	case fd.IsList() && field.Set:
+		if v.List().Len() == 0 {
+			return nil, nil // Sets cannot be empty
+		}
+		return encodeSet(fd, v.List())
+	case fd.IsList():
+		list := v.List()
+		out := make([]types.AttributeValue, 0, list.Len())
+		for i := 0; i < list.Len(); i++ {
+			av, err := encodeValue(fd, list.Get(i), field)
+			if err != nil {
+				return nil, err
+			}
+			if av == nil {
+				av = &types.AttributeValueMemberNULL{Value: true}
+			}
+			out = append(out, av)
+		}
+		return &types.AttributeValueMemberL{Value: out}, nil
+	default:
+		return encodeValue(fd, v, field)
+	}
+}
+
+func encodeSet(fd protoreflect.FieldDescriptor, list protoreflect.List) (types.AttributeValue, error) {
+	switch fd.Kind() {
+	case protoreflect.StringKind:
+		out := make([]string, list.Len())
+		for i := range out {
+			out[i] = list.Get(i).String()
+		}
+		return &types.AttributeValueMemberSS{Value: out}, nil
+	case protoreflect.BytesKind:
+		out := make([][]byte, list.Len())
+		for i := range out {
+			out[i] = list.Get(i).Bytes()
+		}
+		return &types.AttributeValueMemberBS{Value: out}, nil
+	case protoreflect.MessageKind, protoreflect.GroupKind, protoreflect.BoolKind:
+		return nil, fmt.Errorf("a repeated %s field cannot be a set", fd.Kind())
+	default:
+		out := make([]string, list.Len())
+		for i := range out {
+			out[i] = encodeNumber(fd, list.Get(i))
+		}
+		return &types.AttributeValueMemberNS{Value: out}, nil
+	}
+}
+
+// encodeValue encodes a singular value, or returns nil for nil messages.
+func encodeValue(fd protoreflect.FieldDescriptor, v protoreflect.Value, field Field) (types.AttributeValue, error) {
+	switch fd.Kind() {
+	case protoreflect.StringKind:
+		return &types.AttributeValueMemberS{Value: v.String()}, nil
+	case protoreflect.BytesKind:
+		return &types.AttributeValueMemberB{Value: v.Bytes()}, nil
+	case protoreflect.BoolKind:
+		return &types.AttributeValueMemberBOOL{Value: v.Bool()}, nil
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return encodeMessage(v.Message(), field)
+	default:
+		return &types.AttributeValueMemberN{Value: encodeNumber(fd, v)}, nil
+	}
+}
+
+func encodeNumber(fd protoreflect.FieldDescriptor, v protoreflect.Value) string {
+	switch fd.Kind() {
+	case protoreflect.EnumKind:
+		return strconv.FormatInt(int64(v.Enum()), 10)
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind,
+		protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		return strconv.FormatInt(v.Int(), 10)
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind, protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return strconv.FormatUint(v.Uint(), 10)
+	case protoreflect.FloatKind:
+		return strconv.FormatFloat(v.Float(), 'g', -1, 32)
+	default:
+		return strconv.FormatFloat(v.Float(), 'g', -1, 64)
+	}
+}
+
+func encodeMessage(m protoreflect.Message, field Field) (types.AttributeValue, error) {
+	if !m.IsValid() {
+		return nil, nil
+	}
+
+	switch m.Descriptor().FullName() {
+	case "google.protobuf.Timestamp":
+		seconds, nanos := secondsNanos(m)
+		if field.UnixTime {
+			return &types.AttributeValueMemberN{Value: strconv.FormatInt(seconds, 10)}, nil
+		}
+		t := time.Unix(seconds, int64(nanos)).UTC()
+		return &types.AttributeValueMemberS{Value: t.Format(time.RFC3339Nano)}, nil
+	case "google.protobuf.Duration":
+		seconds, nanos := secondsNanos(m)
+		d := time.Duration(seconds)*time.Second + time.Duration(nanos)
+		return &types.AttributeValueMemberN{Value: strconv.FormatInt(int64(d), 10)}, nil
+	}
+	if isWrapper(m.Descriptor()) {
+		fd := m.Descriptor().Fields().ByName("value")
+		return encodeValue(fd, m.Get(fd), Field{})
+	}
+
+	var item map[string]types.AttributeValue
+	var err error
+	if marshaler, ok := m.Interface().(Marshaler); ok {
+		item, err = marshaler.MarshalDynamoItem()
+	} else {
+		item, err = Marshal(m.Interface(), nil)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &types.AttributeValueMemberM{Value: item}, nil
+}
+
+func decodeField(m protoreflect.Message, fd protoreflect.FieldDescriptor, av types.AttributeValue, field Field) error {
+	switch {
+	case fd.IsMap():
+		in, ok := av.(*types.AttributeValueMemberM)
+		if !ok {
+			return typeError("M", av)
+		}
+		out := m.Mutable(fd).Map()
+		for k, av := range in.Value {
+			key, err := decodeMapKey(fd.MapKey(), k)
+			if err != nil {
+				return err
+			}
+			var v protoreflect.Value
+			if fd.MapValue().Message() != nil {
+				v = out.NewValue()
+				err = decodeMessage(v.Message(), av, field)
+			} else {
+				v, err = decodeScalar(fd.MapValue(), av)
+			}
+			if err != nil {
+				return fmt.Errorf("%s: %w", k, err)
+			}
+			out.Set(key, v)
+		}
+		return nil
+	case fd.IsList():
+		return decodeList(m.Mutable(fd).List(), fd, av, field)
+	case fd.Message() != nil:
+		v := m.NewField(fd)
+		if err := decodeMessage(v.Message(), av, field); err != nil {
+			return err
+		}
+		m.Set(fd, v)
+		return nil
+	default:
+		v, err := decodeScalar(fd, av)
+		if err != nil {
+			return err
+		}
+		m.Set(fd, v)
+		return nil
+	}
+}
+
+func decodeList(list protoreflect.List, fd protoreflect.FieldDescriptor, av types.AttributeValue, field Field) error {
+	var values []types.AttributeValue
+	switch av := av.(type) {
+	case *types.AttributeValueMemberL:
+		values = av.Value
+	case *types.AttributeValueMemberSS:
+		for _, s := range av.Value {
+			values = append(values, &types.AttributeValueMemberS{Value: s})
+		}
+	case *types.AttributeValueMemberNS:
+		for _, n := range av.Value {
+			values = append(values, &types.AttributeValueMemberN{Value: n})
+		}
+	case *types.AttributeValueMemberBS:
+		for _, b := range av.Value {
+			values = append(values, &types.AttributeValueMemberB{Value: b})
+		}
+	default:
+		return typeError("L", av)
+	}
+
+	for i, av := range values {
+		var v protoreflect.Value
+		var err error
+		if fd.Message() != nil {
+			v = list.NewElement()
+			if _, null := av.(*types.AttributeValueMemberNULL); !null {
+				err = decodeMessage(v.Message(), av, field)
+			}
+		} else {
+			v, err = decodeScalar(fd, av)
+		}
+		if err != nil {
+			return fmt.Errorf("[%d]: %w", i, err)
+		}
+		list.Append(v)
+	}
+	return nil
+}
+
+func decodeMessage(m protoreflect.Message, av types.AttributeValue, field Field) error {
+	switch m.Descriptor().FullName() {
+	case "google.protobuf.Timestamp":
+		switch av := av.(type) {
+		case *types.AttributeValueMemberN:
+			seconds, err := strconv.ParseInt(av.Value, 10, 64)
+			if err != nil {
+				return err
+			}
+			setSecondsNanos(m, seconds, 0)
+		case *types.AttributeValueMemberS:
+			t, err := time.Parse(time.RFC3339Nano, av.Value)
+			if err != nil {
+				return err
+			}
+			setSecondsNanos(m, t.Unix(), int32(t.Nanosecond()))
+		default:
+			return typeError("S or N", av)
+		}
+		return nil
+	case "google.protobuf.Duration":
+		n, ok := av.(*types.AttributeValueMemberN)
+		if !ok {
+			return typeError("N", av)
+		}
+		nanos, err := strconv.ParseInt(n.Value, 10, 64)
+		if err != nil {
+			return err
+		}
+		d := time.Duration(nanos)
+		setSecondsNanos(m, int64(d/time.Second), int32(d%time.Second))
+		return nil
+	}
+	if isWrapper(m.Descriptor()) {
+		fd := m.Descriptor().Fields().ByName("value")
+		v, err := decodeScalar(fd, av)
+		if err != nil {
+			return err
+		}
+		m.Set(fd, v)
+		return nil
+	}
+
+	in, ok := av.(*types.AttributeValueMemberM)
+	if !ok {
+		return typeError("M", av)
+	}
+	if unmarshaler, ok := m.Interface().(Unmarshaler); ok {
+		return unmarshaler.UnmarshalDynamoItem(in.Value)
+	}
+	return Unmarshal(in.Value, m.Interface(), nil)
+}
+
+func decodeScalar(fd protoreflect.FieldDescriptor, av types.AttributeValue) (protoreflect.Value, error) {
+	switch fd.Kind() {
+	case protoreflect.StringKind:
+		s, ok := av.(*types.AttributeValueMemberS)
+		if !ok {
+			return protoreflect.Value{}, typeError("S", av)
+		}
+		return protoreflect.ValueOfString(s.Value), nil
+	case protoreflect.BytesKind:
+		b, ok := av.(*types.AttributeValueMemberB)
+		if !ok {
+			return protoreflect.Value{}, typeError("B", av)
+		}
+		return protoreflect.ValueOfBytes(b.Value), nil
+	case protoreflect.BoolKind:
+		b, ok := av.(*types.AttributeValueMemberBOOL)
+		if !ok {
+			return protoreflect.Value{}, typeError("BOOL", av)
+		}
+		return protoreflect.ValueOfBool(b.Value), nil
+	}
+
+	n, ok := av.(*types.AttributeValueMemberN)
+	if !ok {
+		return protoreflect.Value{}, typeError("N", av)
+	}
+	switch fd.Kind() {
+	case protoreflect.EnumKind:
+		i, err := strconv.ParseInt(n.Value, 10, 32)
+		return protoreflect.ValueOfEnum(protoreflect.EnumNumber(i)), err
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		i, err := strconv.ParseInt(n.Value, 10, 32)
+		return protoreflect.ValueOfInt32(int32(i)), err
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		i, err := strconv.ParseInt(n.Value, 10, 64)
+		return protoreflect.ValueOfInt64(i), err
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		u, err := strconv.ParseUint(n.Value, 10, 32)
+		return protoreflect.ValueOfUint32(uint32(u)), err
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		u, err := strconv.ParseUint(n.Value, 10, 64)
+		return protoreflect.ValueOfUint64(u), err
+	case protoreflect.FloatKind:
+		f, err := strconv.ParseFloat(n.Value, 32)
+		return protoreflect.ValueOfFloat32(float32(f)), err
+	default:
+		f, err := strconv.ParseFloat(n.Value, 64)
+		return protoreflect.ValueOfFloat64(f), err
+	}
+}
+
+// decodeMapKey parses the attribute name of a map entry.
+func decodeMapKey(fd protoreflect.FieldDescriptor, k string) (protoreflect.MapKey, error) {
+	if fd.Kind() == protoreflect.StringKind {
+		return protoreflect.ValueOfString(k).MapKey(), nil
+	}
+	if fd.Kind() == protoreflect.BoolKind {
+		b, err := strconv.ParseBool(k)
+		return protoreflect.ValueOfBool(b).MapKey(), err
+	}
+	v, err := decodeScalar(fd, &types.AttributeValueMemberN{Value: k})
+	return v.MapKey(), err
+}
+
+// secondsNanos returns the fields of a Timestamp or Duration, which may be
+// dynamic messages.
+func secondsNanos(m protoreflect.Message) (int64, int32) {
+	fields := m.Descriptor().Fields()
+	return m.Get(fields.ByName("seconds")).Int(), int32(m.Get(fields.ByName("nanos")).Int())
+}
+
+// setSecondsNanos sets the fields of a Timestamp or Duration.
+func setSecondsNanos(m protoreflect.Message, seconds int64, nanos int32) {
+	fields := m.Descriptor().Fields()
+	m.Set(fields.ByName("seconds"), protoreflect.ValueOfInt64(seconds))
+	m.Set(fields.ByName("nanos"), protoreflect.ValueOfInt32(nanos))
+}
+
+// isWrapper reports whether a message is a google.protobuf wrapper (e.g., StringValue).
+func isWrapper(md protoreflect.MessageDescriptor) bool {
+	switch md.FullName() {
+	case "google.protobuf.DoubleValue", "google.protobuf.FloatValue",
+		"google.protobuf.Int64Value", "google.protobuf.UInt64Value",
+		"google.protobuf.Int32Value", "google.protobuf.UInt32Value",
+		"google.protobuf.BoolValue", "google.protobuf.StringValue", "google.protobuf.BytesValue":
+		return true
+	default:
+		return false
+	}
+}
+
+func typeError(want string, av types.AttributeValue) error {
+	return fmt.Errorf("expected a %s attribute, got %T", want, av)
+}
+
+// goCamelCase returns the Go field name of a proto field, as protoc-gen-go
+// does: "user_id" -> "UserId", "x509_cert" -> "X509Cert".
+func goCamelCase(s string) string {
+	var b []byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '.' && i+1 < len(s) && isASCIILower(s[i+1]):
+			// Skip over '.' in ".{{lowercase}}"
+		case c == '.':
+			b = append(b, '_')
+		case c == '_' && (i == 0 || s[i-1] == '.'):
+			// Convert initial '_' to ensure the result starts with an uppercase letter
+			b = append(b, 'X')
+		case c == '_' && i+1 < len(s) && isASCIILower(s[i+1]):
+			// Skip over '_' in "_{{lowercase}}"
+		case isASCIIDigit(c):
+			b = append(b, c)
+		default:
+			// Uppercase the first letter of each word
+			if isASCIILower(c) {
+				c -= 'a' - 'A'
+			}
+			b = append(b, c)
+			// Accept lowercase sequences as is
+			for ; i+1 < len(s) && isASCIILower(s[i+1]); i++ {
+				b = append(b, s[i+1])
+			}
+		}
+	}
+	return string(b)
+}
+
+func isASCIILower(c byte) bool { return 'a' <= c && c <= 'z' }
+func isASCIIDigit(c byte) bool { return '0' <= c && c <= '9' }
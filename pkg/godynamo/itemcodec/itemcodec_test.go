@@ -0,0 +1,192 @@
+package itemcodec
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+const itemProto = `
+name: "item.proto"
+package: "test"
+dependency: ["google/protobuf/timestamp.proto", "google/protobuf/duration.proto", "google/protobuf/wrappers.proto"]
+syntax: "proto3"
+enum_type { name: "Color" value { name: "COLOR_UNSPECIFIED" number: 0 } value { name: "COLOR_RED" number: 1 } }
+message_type {
+  name: "Item"
+  field { name: "id" number: 1 type: TYPE_STRING label: LABEL_OPTIONAL }
+  field { name: "count" number: 2 type: TYPE_INT64 label: LABEL_OPTIONAL }
+  field { name: "color" number: 3 type: TYPE_ENUM type_name: ".test.Color" label: LABEL_OPTIONAL }
+  field { name: "data" number: 4 type: TYPE_BYTES label: LABEL_OPTIONAL }
+  field { name: "tags" number: 5 type: TYPE_STRING label: LABEL_REPEATED }
+  field { name: "scores" number: 6 type: TYPE_INT32 label: LABEL_REPEATED }
+  field { name: "children" number: 7 type: TYPE_MESSAGE type_name: ".test.Item.ChildrenEntry" label: LABEL_REPEATED }
+  field { name: "created_at" number: 8 type: TYPE_MESSAGE type_name: ".google.protobuf.Timestamp" label: LABEL_OPTIONAL }
+  field { name: "updated_at" number: 9 type: TYPE_MESSAGE type_name: ".google.protobuf.Timestamp" label: LABEL_OPTIONAL }
+  field { name: "ttl" number: 10 type: TYPE_MESSAGE type_name: ".google.protobuf.Duration" label: LABEL_OPTIONAL }
+  field { name: "nickname" number: 11 type: TYPE_MESSAGE type_name: ".google.protobuf.StringValue" label: LABEL_OPTIONAL }
+  field { name: "text" number: 12 type: TYPE_STRING label: LABEL_OPTIONAL oneof_index: 0 }
+  field { name: "child" number: 13 type: TYPE_MESSAGE type_name: ".test.Item" label: LABEL_OPTIONAL oneof_index: 0 }
+  nested_type {
+    name: "ChildrenEntry"
+    field { name: "key" number: 1 type: TYPE_STRING label: LABEL_OPTIONAL }
+    field { name: "value" number: 2 type: TYPE_MESSAGE type_name: ".test.Item" label: LABEL_OPTIONAL }
+    options { map_entry: true }
+  }
+  oneof_decl { name: "choice" }
+}`
+
+func newItem(t *testing.T) protoreflect.MessageDescriptor {
+	t.Helper()
+	var fdp descriptorpb.FileDescriptorProto
+	if err := prototext.Unmarshal([]byte(itemProto), &fdp); err != nil {
+		t.Fatal(err)
+	}
+	fd, err := protodesc.NewFile(&fdp, protoregistry.GlobalFiles)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fd.Messages().ByName("Item")
+}
+
+func TestMarshalUnmarshal(t *testing.T) {
+	md := newItem(t)
+	field := func(name string) protoreflect.FieldDescriptor { return md.Fields().ByName(protoreflect.Name(name)) }
+
+	child := dynamicpb.NewMessage(md)
+	child.Set(field("id"), protoreflect.ValueOfString("c1"))
+
+	item := dynamicpb.NewMessage(md)
+	item.Set(field("id"), protoreflect.ValueOfString("i1"))
+	item.Set(field("count"), protoreflect.ValueOfInt64(-3))
+	item.Set(field("color"), protoreflect.ValueOfEnum(1))
+	item.Set(field("data"), protoreflect.ValueOfBytes([]byte{1, 2}))
+	item.Mutable(field("tags")).List().Append(protoreflect.ValueOfString("a"))
+	item.Mutable(field("scores")).List().Append(protoreflect.ValueOfInt32(7))
+	item.Mutable(field("children")).Map().Set(protoreflect.ValueOfString("k").MapKey(), protoreflect.ValueOfMessage(child))
+	item.Set(field("created_at"), protoreflect.ValueOfMessage(timestamppb.New(time.Unix(1700000000, 0)).ProtoReflect()))
+	item.Set(field("updated_at"), protoreflect.ValueOfMessage(timestamppb.New(time.Unix(1700000000, 5)).ProtoReflect()))
+	item.Set(field("ttl"), protoreflect.ValueOfMessage(durationpb.New(time.Minute).ProtoReflect()))
+	item.Set(field("nickname"), protoreflect.ValueOfMessage(wrapperspb.String("nick").ProtoReflect()))
+	item.Set(field("child"), protoreflect.ValueOfMessage(child))
+
+	fields := []Field{
+		{Proto: "id", Name: "ID"},
+		{Proto: "tags", Name: "Tags", Set: true},
+		{Proto: "created_at", Name: "created", UnixTime: true},
+		{Proto: "text", Name: "Text", OmitEmpty: true},
+	}
+	av, err := Marshal(item, fields)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	checks := map[string]types.AttributeValue{
+		"ID":        &types.AttributeValueMemberS{Value: "i1"},
+		"Count":     &types.AttributeValueMemberN{Value: "-3"},
+		"Color":     &types.AttributeValueMemberN{Value: "1"},
+		"created":   &types.AttributeValueMemberN{Value: "1700000000"},
+		"UpdatedAt": &types.AttributeValueMemberS{Value: "2023-11-14T22:13:20.000000005Z"},
+		"Ttl":       &types.AttributeValueMemberN{Value: "60000000000"},
+		"Nickname":  &types.AttributeValueMemberS{Value: "nick"},
+	}
+	for name, want := range checks {
+		if got := av[name]; !equalScalar(got, want) {
+			t.Errorf("%s = %#v, want %#v", name, got, want)
+		}
+	}
+	if ss, ok := av["Tags"].(*types.AttributeValueMemberSS); !ok || len(ss.Value) != 1 {
+		t.Errorf("Tags = %#v, want a string set", av["Tags"])
+	}
+	if _, ok := av["Text"]; ok {
+		t.Error("unset oneof member was stored")
+	}
+	if m, ok := av["Child"].(*types.AttributeValueMemberM); !ok || !equalScalar(m.Value["Id"], &types.AttributeValueMemberS{Value: "c1"}) {
+		t.Errorf("Child = %#v, want a map with Id", av["Child"])
+	}
+
+	got := dynamicpb.NewMessage(md)
+	if err := Unmarshal(av, got, fields); err != nil {
+		t.Fatal(err)
+	}
+	if !proto.Equal(got, item) {
+		t.Errorf("Unmarshal() = %v, want %v", got, item)
+	}
+
+	if err := Unmarshal(map[string]types.AttributeValue{"Count": &types.AttributeValueMemberS{Value: "x"}}, got, fields); err == nil {
+		t.Error("Unmarshal() of a mistyped attribute succeeded")
+	}
+}
+
+func TestMarshalZeroValues(t *testing.T) {
+	md := newItem(t)
+	item := dynamicpb.NewMessage(md)
+
+	fields := []Field{
+		{Proto: "id", Name: "ID", Key: true, OmitEmpty: true},
+		{Proto: "color", Name: "Color", OmitEmpty: true},
+		{Proto: "tags", Name: "Tags", Set: true},
+	}
+	av, err := Marshal(item, fields)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Fields without presence are stored, except with OmitEmpty and for sets;
+	// unset messages and oneof members are not
+	want := []string{"ID", "Count", "Data", "Scores", "Children"}
+	if len(av) != len(want) {
+		t.Errorf("Marshal() = %v, want the attributes %v", av, want)
+	}
+	for _, name := range want {
+		if _, ok := av[name]; !ok {
+			t.Errorf("%s was not stored", name)
+		}
+	}
+	if !equalScalar(av["ID"], &types.AttributeValueMemberS{Value: ""}) {
+		t.Errorf("ID = %#v, want the empty key", av["ID"])
+	}
+	if !equalScalar(av["Count"], &types.AttributeValueMemberN{Value: "0"}) {
+		t.Errorf("Count = %#v, want 0", av["Count"])
+	}
+
+	got := dynamicpb.NewMessage(md)
+	if err := Unmarshal(av, got, fields); err != nil {
+		t.Fatal(err)
+	}
+	if !proto.Equal(got, item) {
+		t.Errorf("Unmarshal() = %v, want %v", got, item)
+	}
+}
+
+func equalScalar(a, b types.AttributeValue) bool {
+	switch a := a.(type) {
+	case *types.AttributeValueMemberS:
+		b, ok := b.(*types.AttributeValueMemberS)
+		return ok && a.Value == b.Value
+	case *types.AttributeValueMemberN:
+		b, ok := b.(*types.AttributeValueMemberN)
+		return ok && a.Value == b.Value
+	default:
+		return false
+	}
+}
+
+func TestGoCamelCase(t *testing.T) {
+	for in, want := range map[string]string{"id": "Id", "user_id": "UserId", "x509_cert": "X509Cert", "_x": "XX", "a2b": "A2B"} {
+		if got := goCamelCase(in); got != want {
+			t.Errorf("goCamelCase(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
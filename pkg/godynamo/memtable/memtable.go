@@ -0,0 +1,294 @@
+// Package memtable is an in-memory DynamoDB table of proto messages.
+//
+// It backs the in-memory repositories generated by protoc-gen-go-dynamo
+// (repository=true), so code using a repository can be tested without
+// DynamoDB or DynamoDB Local. Like DynamoDB, the table:
+// - Stores one item per primary key (hash key, plus range key if any)
+// - Upserts on Update, setting or removing the given attributes only
+// - Returns the items of an index sorted by its range key
+// - Leaves items without the index key attributes out of the index (sparse indexes)
+// - Checks and increments version fields on PutVersion and UpdateVersion
+package memtable
+
+import (
+	"cmp"
+	"fmt"
+	"sort"
+	"sync"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"github.com/getfrontierhq/buf-public-apis/pkg/godynamo/version"
+)
+
+// Table stores messages by primary key. Items are copied in and out, so
+// callers never share messages with the table.
+type Table[T proto.Message] struct {
+	hashField  protoreflect.Name
+	rangeField protoreflect.Name
+
+	mu    sync.Mutex
+	items map[itemKey]T
+}
+
+// itemKey is the primary key of an item, with normalized values.
+type itemKey struct {
+	hash any
+	rng  any
+}
+
+// New returns an empty table keyed by the given proto fields.
+// rangeField is empty for tables without range key.
+func New[T proto.Message](hashField, rangeField string) *Table[T] {
+	return &Table[T]{
+		hashField:  protoreflect.Name(hashField),
+		rangeField: protoreflect.Name(rangeField),
+		items:      make(map[itemKey]T),
+	}
+}
+
+// Get returns the item with the given key. rng is ignored for tables
+// without range key.
+func (t *Table[T]) Get(hash, rng any) (T, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	item, ok := t.items[t.key(hash, rng)]
+	if !ok {
+		var zero T
+		return zero, false
+	}
+	return clone(item), true
+}
+
+// Put stores the item, replacing any item with the same key.
+func (t *Table[T]) Put(item T) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.items[t.itemKey(item)] = clone(item)
+}
+
+// PutVersion stores the item like Put if the stored version of the item is
+// the version of item (field is the proto name of the version field, a
+// missing item has version 0), and returns the stored item, whose version is
+// incremented. It returns a *version.ConflictError otherwise.
+func (t *Table[T]) PutVersion(item T, field string) (T, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	key := t.itemKey(item)
+	expected := version.Get(item, field)
+	if err := t.checkVersion(key, field, expected); err != nil {
+		var zero T
+		return zero, err
+	}
+	stored := clone(item)
+	version.Set(stored, field, expected+1)
+	t.items[key] = stored
+	return clone(stored), nil
+}
+
+// checkVersion returns a *version.ConflictError if the stored version of the
+// item with the given key is not expected.
+func (t *Table[T]) checkVersion(key itemKey, field string, expected int64) error {
+	var current int64
+	if stored, ok := t.items[key]; ok {
+		current = version.Get(stored, field)
+	}
+	if current != expected {
+		return &version.ConflictError{Attribute: field, Expected: expected}
+	}
+	return nil
+}
+
+// Delete removes the item with the given key, if any.
+func (t *Table[T]) Delete(hash, rng any) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	delete(t.items, t.key(hash, rng))
+}
+
+// Update copies the given fields (proto names) of item into the stored item
+// with the same key, creating it if needed, and returns the updated item.
+// Fields unset in item are removed. Key fields cannot be updated.
+func (t *Table[T]) Update(item T, fields ...string) (T, error) {
+	return t.update(item, fields, "")
+}
+
+// UpdateVersion is Update checking and incrementing the version field (proto
+// name) like PutVersion. The version field is not one of the updated fields.
+func (t *Table[T]) UpdateVersion(item T, field string, fields ...string) (T, error) {
+	return t.update(item, fields, field)
+}
+
+// update implements Update, and UpdateVersion if versionField is not empty.
+func (t *Table[T]) update(item T, fields []string, versionField string) (T, error) {
+	var zero T
+	src := item.ProtoReflect()
+	desc := src.Descriptor()
+	for _, name := range fields {
+		fd := desc.Fields().ByName(protoreflect.Name(name))
+		if fd == nil {
+			return zero, fmt.Errorf("%s has no field %q", desc.FullName(), name)
+		}
+		if fd.Name() == t.hashField || fd.Name() == t.rangeField {
+			return zero, fmt.Errorf("key field %q cannot be updated", name)
+		}
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	key := t.itemKey(item)
+	var expected int64
+	if versionField != "" {
+		expected = version.Get(item, versionField)
+		if err := t.checkVersion(key, versionField, expected); err != nil {
+			return zero, err
+		}
+	}
+	stored, ok := t.items[key]
+	if !ok {
+		// Upsert: a new item holding the key attributes only
+		stored = src.New().Interface().(T)
+		for _, name := range []protoreflect.Name{t.hashField, t.rangeField} {
+			if fd := desc.Fields().ByName(name); fd != nil {
+				stored.ProtoReflect().Set(fd, src.Get(fd))
+			}
+		}
+	}
+
+	dst := stored.ProtoReflect()
+	for _, name := range fields {
+		if name == versionField {
+			continue
+		}
+		fd := desc.Fields().ByName(protoreflect.Name(name))
+		if src.Has(fd) {
+			dst.Set(fd, src.Get(fd))
+		} else {
+			dst.Clear(fd)
+		}
+	}
+	if versionField != "" {
+		version.Set(stored, versionField, expected+1)
+	}
+
+	stored = clone(stored)
+	t.items[key] = stored
+	return clone(stored), nil
+}
+
+// Query returns the items of an index whose hashField equals hash, sorted by
+// rangeField (empty for indexes without range key). Items missing an index
+// key attribute are not in the index.
+func (t *Table[T]) Query(hashField string, hash any, rangeField string) []T {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	hash = normalize(hash)
+
+	var items []T
+	for _, item := range t.items {
+		msg := item.ProtoReflect()
+		hashFd := msg.Descriptor().Fields().ByName(protoreflect.Name(hashField))
+		if hashFd == nil || !msg.Has(hashFd) || normalize(msg.Get(hashFd).Interface()) != hash {
+			continue
+		}
+		if rangeField != "" {
+			rangeFd := msg.Descriptor().Fields().ByName(protoreflect.Name(rangeField))
+			if rangeFd == nil || !msg.Has(rangeFd) {
+				continue
+			}
+		}
+		items = append(items, clone(item))
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		if rangeField != "" {
+			if c := compare(fieldValue(items[i], rangeField), fieldValue(items[j], rangeField)); c != 0 {
+				return c < 0
+			}
+		}
+		// Deterministic order among equal range keys
+		ki, kj := t.itemKey(items[i]), t.itemKey(items[j])
+		if c := compare(ki.hash, kj.hash); c != 0 {
+			return c < 0
+		}
+		return compare(ki.rng, kj.rng) < 0
+	})
+	return items
+}
+
+// key returns the normalized primary key of the given values.
+func (t *Table[T]) key(hash, rng any) itemKey {
+	if t.rangeField == "" {
+		rng = nil
+	}
+	return itemKey{hash: normalize(hash), rng: normalize(rng)}
+}
+
+// itemKey returns the primary key of an item.
+func (t *Table[T]) itemKey(item T) itemKey {
+	var rng any
+	if t.rangeField != "" {
+		rng = fieldValue(item, string(t.rangeField))
+	}
+	return itemKey{hash: fieldValue(item, string(t.hashField)), rng: rng}
+}
+
+// fieldValue returns the normalized value of a field of item.
+func fieldValue[T proto.Message](item T, name string) any {
+	msg := item.ProtoReflect()
+	fd := msg.Descriptor().Fields().ByName(protoreflect.Name(name))
+	if fd == nil {
+		return nil
+	}
+	return normalize(msg.Get(fd).Interface())
+}
+
+// normalize converts key values to comparable values of a single type per
+// DynamoDB type: string (S and B), int64/uint64/float64 (N).
+// Enums, either generated Go enums or enum numbers, are converted to int64.
+func normalize(v any) any {
+	switch v := v.(type) {
+	case []byte:
+		return string(v)
+	case int32:
+		return int64(v)
+	case uint32:
+		return uint64(v)
+	case float32:
+		return float64(v)
+	case protoreflect.EnumNumber:
+		return int64(v)
+	case protoreflect.Enum:
+		return int64(v.Number())
+	default:
+		return v
+	}
+}
+
+// compare orders two normalized values of the same type.
+func compare(a, b any) int {
+	switch a := a.(type) {
+	case string:
+		return cmp.Compare(a, b.(string))
+	case int64:
+		return cmp.Compare(a, b.(int64))
+	case uint64:
+		return cmp.Compare(a, b.(uint64))
+	case float64:
+		return cmp.Compare(a, b.(float64))
+	default:
+		return 0
+	}
+}
+
+// clone returns a deep copy of item.
+func clone[T proto.Message](item T) T {
+	return proto.Clone(item).(T)
+}
@@ -0,0 +1,100 @@
+package memtable
+
+import (
+	"errors"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+
+	"github.com/getfrontierhq/buf-public-apis/pkg/godynamo/version"
+	gohttperrors "github.com/getfrontierhq/buf-public-apis/pkg/gohttp/errors"
+)
+
+func field(name string, number int32, typeName string, label descriptorpb.FieldDescriptorProto_Label) *descriptorpb.FieldDescriptorProto {
+	f := &descriptorpb.FieldDescriptorProto{Name: proto.String(name), Number: proto.Int32(number), Label: label.Enum()}
+	if typeName != "" {
+		f.TypeName = proto.String(typeName)
+	}
+	return f
+}
+
+func TestTable(t *testing.T) {
+	table := New[*descriptorpb.FieldDescriptorProto]("name", "number")
+	optional, repeated := descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL, descriptorpb.FieldDescriptorProto_LABEL_REPEATED
+
+	item := field("a", 2, ".pkg.B", optional)
+	table.Put(item)
+	table.Put(field("a", 1, ".pkg.A", repeated))
+	table.Put(field("b", 1, ".pkg.A", optional))
+	table.Put(field("c", 1, "", optional))
+	item.Number = proto.Int32(3) // the table holds a copy
+
+	got, ok := table.Get("a", int32(2))
+	if !ok || got.GetTypeName() != ".pkg.B" {
+		t.Fatalf("Get() = %v, %v", got, ok)
+	}
+	if _, ok := table.Get("a", int32(3)); ok {
+		t.Error("Get() found an item mutated after Put")
+	}
+
+	// Index on type_name: items without type_name are not in the index
+	names := func(items []*descriptorpb.FieldDescriptorProto) (names []string) {
+		for _, item := range items {
+			names = append(names, item.GetName())
+		}
+		return names
+	}
+	if got := names(table.Query("type_name", ".pkg.A", "name")); len(got) != 2 || got[0] != "a" || got[1] != "b" {
+		t.Errorf("Query(type_name) = %v, want [a b]", got)
+	}
+	if got := table.Query("label", optional, "type_name"); len(got) != 2 || got[0].GetTypeName() != ".pkg.A" {
+		t.Errorf("Query(label) = %v, want b then a", names(got))
+	}
+
+	// Update upserts and removes unset fields
+	updated, err := table.Update(&descriptorpb.FieldDescriptorProto{Name: proto.String("a"), Number: proto.Int32(2), JsonName: proto.String("aa")}, "json_name", "type_name")
+	if err != nil || updated.GetJsonName() != "aa" || updated.TypeName != nil || updated.GetLabel() != optional {
+		t.Errorf("Update() = %v, %v", updated, err)
+	}
+	created, err := table.Update(&descriptorpb.FieldDescriptorProto{Name: proto.String("d"), Number: proto.Int32(1), JsonName: proto.String("d")}, "json_name")
+	if err != nil || created.GetName() != "d" || created.GetNumber() != 1 || created.GetJsonName() != "d" {
+		t.Errorf("Update() = %v, %v", created, err)
+	}
+	if _, err := table.Update(item, "number"); err == nil {
+		t.Error("Update() of a key field succeeded")
+	}
+	if _, err := table.Update(item, "missing"); err == nil {
+		t.Error("Update() of an unknown field succeeded")
+	}
+
+	table.Delete("a", int32(2))
+	if _, ok := table.Get("a", int32(2)); ok {
+		t.Error("Get() found a deleted item")
+	}
+}
+
+func TestTableVersion(t *testing.T) {
+	table := New[*descriptorpb.FieldDescriptorProto]("name", "")
+	optional := descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL
+
+	stored, err := table.PutVersion(field("a", 0, ".pkg.A", optional), "number")
+	if err != nil || stored.GetNumber() != 1 {
+		t.Fatalf("PutVersion() = %v, %v", stored, err)
+	}
+	if _, err := table.PutVersion(field("a", 0, ".pkg.B", optional), "number"); !errors.Is(err, gohttperrors.ErrGeneralConflict) {
+		t.Fatalf("PutVersion() of a stale item = %v, want a conflict", err)
+	}
+
+	updated, err := table.UpdateVersion(field("a", 1, ".pkg.C", optional), "number", "type_name", "number")
+	if err != nil || updated.GetNumber() != 2 || updated.GetTypeName() != ".pkg.C" {
+		t.Fatalf("UpdateVersion() = %v, %v", updated, err)
+	}
+	var conflict *version.ConflictError
+	if _, err := table.UpdateVersion(field("a", 1, ".pkg.D", optional), "number", "type_name"); !errors.As(err, &conflict) || conflict.Expected != 1 {
+		t.Fatalf("UpdateVersion() of a stale item = %v, want a conflict", err)
+	}
+	if got, _ := table.Get("a", nil); got.GetTypeName() != ".pkg.C" {
+		t.Errorf("Get() = %v after conflicts", got)
+	}
+}
@@ -0,0 +1,210 @@
+// Package version implements optimistic locking of DynamoDB items.
+//
+// It backs the versioned writes generated by protoc-gen-go-dynamo for
+// messages with a (dynamo.attribute) = {version: true} field. A write expects
+// the stored version of the item to be the version of the message, and
+// stores the message with its version incremented:
+// - Version 0 creates the item: it must not exist, or have no version or 0
+// - Version n replaces an item of version n
+//
+// A failed expectation is a *ConflictError, which unwraps to
+// errors.ErrGeneralConflict of pkg/gohttp/errors, so gohttp handlers returning
+// it respond 409 Conflict.
+package version
+
+import (
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"github.com/getfrontierhq/buf-public-apis/pkg/godynamo/expr"
+	"github.com/getfrontierhq/buf-public-apis/pkg/godynamo/itemcodec"
+	"github.com/getfrontierhq/buf-public-apis/pkg/gohttp/errors"
+)
+
+// ConflictError is the error of a write whose expected version is not the
+// stored version of the item.
+type ConflictError struct {
+	Attribute string // Version attribute name
+	Expected  int64  // Version expected by the write
+	Err       error  // Cause, e.g. a *types.ConditionalCheckFailedException (nil if none)
+}
+
+// Error implements error.
+func (e *ConflictError) Error() string {
+	if e.Expected == 0 {
+		return fmt.Sprintf("version conflict: item already exists with a %s", e.Attribute)
+	}
+	return fmt.Sprintf("version conflict: item %s is not %d", e.Attribute, e.Expected)
+}
+
+// Unwrap returns errors.ErrGeneralConflict and the cause of e, if any.
+func (e *ConflictError) Unwrap() []error {
+	errs := []error{errors.ErrGeneralConflict}
+	if e.Err != nil {
+		errs = append(errs, e.Err)
+	}
+	return errs
+}
+
+// MarshalJSON encodes e as errors.ErrGeneralConflict, so responses do not
+// leak attribute names.
+func (e *ConflictError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(errors.ErrGeneralConflict)
+}
+
+// Condition returns the condition of a write expecting the given version of
+// the attribute name.
+func Condition(name string, expected int64) expr.Condition {
+	attr := expr.NewAttr[int64](name)
+	if expected == 0 {
+		return expr.Or(attr.NotExists(), attr.Equal(0))
+	}
+	return attr.Equal(expected)
+}
+
+// Check returns a *ConflictError wrapping err if err is a failed condition
+// check of a write expecting the given version, and err otherwise.
+func Check(err error, name string, expected int64) error {
+	var failed *types.ConditionalCheckFailedException
+	if stderrors.As(err, &failed) {
+		return &ConflictError{Attribute: name, Expected: expected, Err: err}
+	}
+	return err
+}
+
+// Get returns the version field (proto name) of msg.
+func Get(msg proto.Message, field string) int64 {
+	m := msg.ProtoReflect()
+	fd := versionField(m, field)
+	switch fd.Kind() {
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind, protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return int64(m.Get(fd).Uint())
+	default:
+		return m.Get(fd).Int()
+	}
+}
+
+// Set sets the version field (proto name) of msg.
+func Set(msg proto.Message, field string, version int64) {
+	m := msg.ProtoReflect()
+	fd := versionField(m, field)
+	switch fd.Kind() {
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		m.Set(fd, protoreflect.ValueOfInt32(int32(version)))
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		m.Set(fd, protoreflect.ValueOfUint32(uint32(version)))
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		m.Set(fd, protoreflect.ValueOfUint64(uint64(version)))
+	default:
+		m.Set(fd, protoreflect.ValueOfInt64(version))
+	}
+}
+
+func versionField(m protoreflect.Message, field string) protoreflect.FieldDescriptor {
+	fd := m.Descriptor().Fields().ByName(protoreflect.Name(field))
+	if fd == nil {
+		panic(fmt.Sprintf("%s has no version field %s", m.Descriptor().FullName(), field))
+	}
+	return fd
+}
+
+// Item is a message with generated item marshalers.
+type Item interface {
+	proto.Message
+	itemcodec.Marshaler
+	itemcodec.Unmarshaler
+}
+
+// Field is the version field of a message.
+// Example: Field{Proto: "version", Name: "Version"}
+type Field struct {
+	Proto string // Proto field name
+	Name  string // Attribute name
+}
+
+// PutItemAPI is the PutItem method of *dynamodb.Client.
+type PutItemAPI interface {
+	PutItem(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error)
+}
+
+// UpdateItemAPI is the UpdateItem method of *dynamodb.Client.
+type UpdateItemAPI interface {
+	UpdateItem(ctx context.Context, params *dynamodb.UpdateItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error)
+}
+
+// Put stores msg in a table if the stored version of the item is the version
+// of msg, which is then incremented.
+func Put(ctx context.Context, client PutItemAPI, table string, msg Item, field Field) error {
+	expected := Get(msg, field.Proto)
+	Set(msg, field.Proto, expected+1)
+	item, err := msg.MarshalDynamoItem()
+	if err == nil {
+		err = put(ctx, client, table, item, field, expected)
+	}
+	if err != nil {
+		Set(msg, field.Proto, expected)
+		return err
+	}
+	return nil
+}
+
+func put(ctx context.Context, client PutItemAPI, table string, item map[string]types.AttributeValue, field Field, expected int64) error {
+	e, err := expr.Builder{Condition: Condition(field.Name, expected)}.Build()
+	if err != nil {
+		return err
+	}
+	_, err = client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName:                 aws.String(table),
+		Item:                      item,
+		ConditionExpression:       e.Condition,
+		ExpressionAttributeNames:  e.Names,
+		ExpressionAttributeValues: e.Values,
+	})
+	return Check(err, field.Name, expected)
+}
+
+// Update applies update to the item of msg in a table, identified by the key
+// attributes of msg, if the stored version of the item is the version of msg.
+// The update increments the version, and msg is set to the updated item.
+func Update(ctx context.Context, client UpdateItemAPI, table string, msg Item, field Field, keys []string, update expr.Update) error {
+	item, err := msg.MarshalDynamoItem()
+	if err != nil {
+		return err
+	}
+	key := make(map[string]types.AttributeValue, len(keys))
+	for _, name := range keys {
+		av, ok := item[name]
+		if !ok {
+			return fmt.Errorf("%s: missing key attribute %s", msg.ProtoReflect().Descriptor().FullName(), name)
+		}
+		key[name] = av
+	}
+
+	expected := Get(msg, field.Proto)
+	update = append(update[:len(update):len(update)], expr.NewAttr[int64](field.Name).Set(expected+1))
+	e, err := expr.Builder{Condition: Condition(field.Name, expected), Update: update}.Build()
+	if err != nil {
+		return err
+	}
+	out, err := client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:                 aws.String(table),
+		Key:                       key,
+		UpdateExpression:          e.Update,
+		ConditionExpression:       e.Condition,
+		ExpressionAttributeNames:  e.Names,
+		ExpressionAttributeValues: e.Values,
+		ReturnValues:              types.ReturnValueAllNew,
+	})
+	if err != nil {
+		return Check(err, field.Name, expected)
+	}
+	return msg.UnmarshalDynamoItem(out.Attributes)
+}
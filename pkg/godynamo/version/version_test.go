@@ -0,0 +1,152 @@
+package version
+
+import (
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"strconv"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"github.com/getfrontierhq/buf-public-apis/pkg/godynamo/expr"
+	"github.com/getfrontierhq/buf-public-apis/pkg/godynamo/itemcodec"
+	"github.com/getfrontierhq/buf-public-apis/pkg/gohttp/errors"
+)
+
+const docProto = `
+name: "doc.proto"
+package: "test"
+syntax: "proto3"
+message_type {
+  name: "Doc"
+  field { name: "id" number: 1 type: TYPE_STRING label: LABEL_OPTIONAL }
+  field { name: "version" number: 2 type: TYPE_INT32 label: LABEL_OPTIONAL }
+  field { name: "title" number: 3 type: TYPE_STRING label: LABEL_OPTIONAL }
+}
+`
+
+var docField = Field{Proto: "version", Name: "Version"}
+
+// doc is a dynamic test.Doc with item marshalers.
+type doc struct {
+	*dynamicpb.Message
+}
+
+func (d doc) MarshalDynamoItem() (map[string]types.AttributeValue, error) {
+	return itemcodec.Marshal(d, []itemcodec.Field{{Proto: "id", Name: "ID"}})
+}
+
+func (d doc) UnmarshalDynamoItem(item map[string]types.AttributeValue) error {
+	return itemcodec.Unmarshal(item, d, []itemcodec.Field{{Proto: "id", Name: "ID"}})
+}
+
+func newDoc(t *testing.T, id string, version int64) doc {
+	t.Helper()
+	fdp := &descriptorpb.FileDescriptorProto{}
+	if err := prototext.Unmarshal([]byte(docProto), fdp); err != nil {
+		t.Fatal(err)
+	}
+	fd, err := protodesc.NewFile(fdp, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := doc{dynamicpb.NewMessage(fd.Messages().ByName("Doc"))}
+	d.Set(d.Descriptor().Fields().ByName("id"), protoreflect.ValueOfString(id))
+	Set(d, "version", version)
+	return d
+}
+
+// fakeClient stores the version of one item, checking the version
+// conditions, whose value is the first value of the expressions.
+type fakeClient struct {
+	stored  int64 // Stored version, 0 if none
+	updates []string
+}
+
+func (c *fakeClient) check(values map[string]types.AttributeValue) error {
+	if number(values[":v0"]) != c.stored {
+		return &types.ConditionalCheckFailedException{}
+	}
+	return nil
+}
+
+func (c *fakeClient) PutItem(ctx context.Context, in *dynamodb.PutItemInput, _ ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+	if err := c.check(in.ExpressionAttributeValues); err != nil {
+		return nil, err
+	}
+	c.stored = number(in.Item["Version"])
+	return &dynamodb.PutItemOutput{}, nil
+}
+
+func (c *fakeClient) UpdateItem(ctx context.Context, in *dynamodb.UpdateItemInput, _ ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error) {
+	c.updates = append(c.updates, *in.UpdateExpression+" IF "+*in.ConditionExpression)
+	if err := c.check(in.ExpressionAttributeValues); err != nil {
+		return nil, err
+	}
+	c.stored++
+	return &dynamodb.UpdateItemOutput{Attributes: map[string]types.AttributeValue{
+		"ID":      in.Key["ID"],
+		"Version": &types.AttributeValueMemberN{Value: strconv.FormatInt(c.stored, 10)},
+		"Title":   &types.AttributeValueMemberS{Value: "new"},
+	}}, nil
+}
+
+func number(av types.AttributeValue) int64 {
+	n, _ := av.(*types.AttributeValueMemberN)
+	if n == nil {
+		return 0
+	}
+	v, _ := strconv.ParseInt(n.Value, 10, 64)
+	return v
+}
+
+func TestPut(t *testing.T) {
+	client := &fakeClient{}
+	d := newDoc(t, "d1", 0)
+	if err := Put(context.Background(), client, "docs", d, docField); err != nil {
+		t.Fatal(err)
+	}
+	if Get(d, "version") != 1 || client.stored != 1 {
+		t.Fatalf("version = %d, stored %d, want 1", Get(d, "version"), client.stored)
+	}
+
+	stale := newDoc(t, "d1", 0)
+	err := Put(context.Background(), client, "docs", stale, docField)
+	var conflict *ConflictError
+	if !stderrors.As(err, &conflict) || conflict.Expected != 0 || !stderrors.Is(err, errors.ErrGeneralConflict) {
+		t.Fatalf("Put() = %v, want a conflict", err)
+	}
+	if Get(stale, "version") != 0 {
+		t.Errorf("version = %d after conflict, want 0", Get(stale, "version"))
+	}
+	if body, _ := json.Marshal(err); string(body) != `{"data":null,"message":"general error, Conflict"}` {
+		t.Errorf("JSON = %s", body)
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	client := &fakeClient{stored: 1}
+	d := newDoc(t, "d1", 1)
+	title := expr.NewAttr[string]("Title")
+	if err := Update(context.Background(), client, "docs", d, docField, []string{"ID"}, expr.Update{title.Set("new")}); err != nil {
+		t.Fatal(err)
+	}
+	if Get(d, "version") != 2 || d.Get(d.Descriptor().Fields().ByName("title")).String() != "new" {
+		t.Fatalf("Update() set %v", d)
+	}
+	if want := "SET #n1 = :v1, #n0 = :v2 IF #n0 = :v0"; client.updates[0] != want {
+		t.Errorf("update = %q, want %q", client.updates[0], want)
+	}
+
+	stale := newDoc(t, "d1", 1)
+	if err := Update(context.Background(), client, "docs", stale, docField, []string{"ID"}, nil); !stderrors.Is(err, errors.ErrGeneralConflict) {
+		t.Fatalf("Update() = %v, want a conflict", err)
+	}
+}
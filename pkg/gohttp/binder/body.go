@@ -38,6 +38,16 @@ func (d *ResponseDecoder) BindBody(v interface{}) error {
 			return err
 		}
 
+		// Decode the value at the unwrap pointer, if any, keeping the envelope
+		if d.Opts != nil && d.Opts.UnwrapResponse != "" {
+			if d.Opts.Envelope != nil {
+				*d.Opts.Envelope = body
+			}
+			if body, err = JSONPointer(body, d.Opts.UnwrapResponse); err != nil {
+				return fmt.Errorf("unwrap response: %w", err)
+			}
+		}
+
 		if protoMessage, ok := v.(protoreflect.ProtoMessage); ok {
 			return protojson.Unmarshal(body, protoMessage)
 		} else {
@@ -1,11 +1,23 @@
 package binder
 
-import "net/http"
+import (
+	"net/http"
+
+	"github.com/getfrontierhq/buf-public-apis/pkg/gohttp/option"
+)
 
 type RequestDecoder struct {
 	Request *http.Request
 }
 
 type ResponseDecoder struct {
+	Opts     *option.BinderOptions
 	Response *http.Response
 }
+
+func NewResponseDecoder(r *http.Response, opts ...option.BinderOption) *ResponseDecoder {
+	return &ResponseDecoder{
+		Opts:     option.NewBinderOptions(opts...),
+		Response: r,
+	}
+}
@@ -0,0 +1,56 @@
+package binder
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// JSONPointer returns the JSON value at pointer (RFC 6901) in doc, e.g. "/data"
+// or "/result/items/0". The empty pointer returns doc itself.
+//
+// It fails when the pointer is malformed or does not resolve to a value.
+func JSONPointer(doc []byte, pointer string) (json.RawMessage, error) {
+	if pointer == "" {
+		return doc, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("json pointer %q must start with /", pointer)
+	}
+
+	value := json.RawMessage(doc)
+	for _, token := range strings.Split(pointer[1:], "/") {
+		// ~1 and ~0 escape "/" and "~", decoded left to right so "~01" is "~1"
+		token = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+
+		trimmed := bytes.TrimSpace(value)
+		switch {
+		case len(trimmed) > 0 && trimmed[0] == '{':
+			var object map[string]json.RawMessage
+			if err := json.Unmarshal(trimmed, &object); err != nil {
+				return nil, fmt.Errorf("json pointer %q: %w", pointer, err)
+			}
+			next, ok := object[token]
+			if !ok {
+				return nil, fmt.Errorf("json pointer %q: no member %q", pointer, token)
+			}
+			value = next
+		case len(trimmed) > 0 && trimmed[0] == '[':
+			var array []json.RawMessage
+			if err := json.Unmarshal(trimmed, &array); err != nil {
+				return nil, fmt.Errorf("json pointer %q: %w", pointer, err)
+			}
+			index, err := strconv.Atoi(token)
+			if err != nil || index < 0 || index >= len(array) || (len(token) > 1 && token[0] == '0') {
+				return nil, fmt.Errorf("json pointer %q: no element %q", pointer, token)
+			}
+			value = array[index]
+		default:
+			return nil, fmt.Errorf("json pointer %q: cannot resolve %q in a scalar value", pointer, token)
+		}
+	}
+
+	return value, nil
+}
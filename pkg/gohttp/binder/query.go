@@ -2,7 +2,9 @@ package binder
 
 import (
 	"fmt"
+	"net/url"
 	"reflect"
+	"strings"
 )
 
 func (d *RequestDecoder) BindQuery(v interface{}) error {
@@ -37,13 +39,33 @@ func (d *RequestDecoder) BindQuery(v interface{}) error {
 }
 
 func (d *RequestEncoder) BindQuery(v interface{}) error {
+	values, err := EncodeQuery(v)
+	if err != nil {
+		return err
+	}
+
+	query := d.Request.URL.Query()
+	for key, vals := range values {
+		for _, val := range vals {
+			query.Add(key, val)
+		}
+	}
+
+	d.Request.URL.RawQuery = query.Encode()
+	return nil
+}
+
+// EncodeQuery returns the query parameters of the query-tagged fields of v,
+// in the format read back by RequestDecoder.BindQuery. Slices are joined with
+// the default value delimiter.
+func EncodeQuery(v interface{}) (url.Values, error) {
 	val := reflect.ValueOf(v)
 	if val.Kind() != reflect.Ptr || val.Elem().Kind() != reflect.Struct {
-		return fmt.Errorf("out must be a pointer to a struct")
+		return nil, fmt.Errorf("out must be a pointer to a struct")
 	}
 	val = val.Elem()
 
-	query := d.Request.URL.Query()
+	query := url.Values{}
 	for i := 0; i < val.NumField(); i++ {
 		field := val.Type().Field(i)
 		fieldVal := val.Field(i)
@@ -53,9 +75,20 @@ func (d *RequestEncoder) BindQuery(v interface{}) error {
 		}
 
 		fieldName := parseTag(tag)
-		query.Add(fieldName, fmt.Sprintf("%v", fieldVal.Interface()))
+		query.Add(fieldName, formatQueryValue(fieldVal))
 	}
 
-	d.Request.URL.RawQuery = query.Encode()
-	return nil
+	return query, nil
+}
+
+func formatQueryValue(fieldVal reflect.Value) string {
+	if fieldVal.Kind() != reflect.Slice || fieldVal.Type().Elem().Kind() == reflect.Uint8 {
+		return fmt.Sprintf("%v", fieldVal.Interface())
+	}
+
+	parts := make([]string, fieldVal.Len())
+	for i := range parts {
+		parts[i] = fmt.Sprintf("%v", fieldVal.Index(i).Interface())
+	}
+	return strings.Join(parts, structTagDefaultValueDelimiter)
 }
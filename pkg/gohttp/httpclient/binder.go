@@ -0,0 +1,40 @@
+package httpclient
+
+import (
+	"fmt"
+
+	"github.com/getfrontierhq/buf-public-apis/pkg/gohttp/errors"
+	"github.com/getfrontierhq/buf-public-apis/pkg/gohttp/option"
+)
+
+// BinderCallOptions converts the option.BinderOption values accepted by the
+// protoc-gen-go-http clients into call options.
+//
+// Headers are sent as is, the operation names the call and is sent as the
+// X-Operation header, and the request ID is sent as the X-Request-ID header.
+// The unwrap pointer and the envelope map to UnwrapResponse and CaptureEnvelope.
+// Only JSON is supported as content type.
+func BinderCallOptions(opts ...option.BinderOption) ([]CallOption, error) {
+	o := option.NewBinderOptions(opts...)
+	if o.ContentType != option.ContentTypeApplicationJson {
+		return nil, fmt.Errorf("content-type %s is not supported, %w", o.ContentType, errors.ErrGeneralUnsupportedMediaType)
+	}
+
+	callOpts := make([]CallOption, 0, len(o.Headers)+4)
+	for key, val := range o.Headers {
+		callOpts = append(callOpts, Header(key, fmt.Sprintf("%v", val)))
+	}
+	if o.Operation != "" {
+		callOpts = append(callOpts, Operation(o.Operation), Header("X-Operation", o.Operation))
+	}
+	if o.RequestID != "" {
+		callOpts = append(callOpts, Header(option.XRequestIDHeader, o.RequestID))
+	}
+	if o.UnwrapResponse != "" {
+		callOpts = append(callOpts, UnwrapResponse(o.UnwrapResponse))
+	}
+	if o.Envelope != nil {
+		callOpts = append(callOpts, CaptureEnvelope(o.Envelope))
+	}
+	return callOpts, nil
+}
@@ -0,0 +1,1061 @@
+// Package httpclient is the HTTP client runtime of the generated clients.
+//
+// Both protoc-gen-go-http-client (the hierarchical clients) and
+// protoc-gen-go-http (the <Svc>HTTPClient interfaces) generate thin wrappers
+// around HTTPClient, so they share the same behavior:
+// - JSON marshaling/unmarshaling with protojson
+// - Consistent error handling (see StatusError)
+// - Support for all HTTP methods (GET, POST, PUT, PATCH, DELETE)
+// - Retries with exponential backoff, Retry-After and idempotency keys
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	mathrand "math/rand"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/getfrontierhq/buf-public-apis/pkg/gohttp/binder"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// Supported HTTP methods
+const (
+	MethodGET    = "GET"
+	MethodPOST   = "POST"
+	MethodPUT    = "PUT"
+	MethodPATCH  = "PATCH"
+	MethodDELETE = "DELETE"
+)
+
+// DefaultIdempotencyKeyHeader is the header used to send idempotency keys.
+const DefaultIdempotencyKeyHeader = "Idempotency-Key"
+
+// RetryPolicy configures how failed requests are retried.
+//
+// A request is retried when it fails with a timeout or a temporary network
+// error, or with one of the retryable status codes, as long as the HTTP method is idempotent (GET, PUT,
+// DELETE) or the request carries an idempotency key.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts, including the first one.
+	// Values lower than 2 disable retries.
+	MaxAttempts int
+
+	// InitialBackoff is the backoff before the first retry.
+	InitialBackoff time.Duration
+
+	// MaxBackoff caps the backoff between two attempts. A response asking to
+	// retry after a longer delay (Retry-After) is not retried.
+	MaxBackoff time.Duration
+
+	// BackoffMultiplier is applied to the backoff after every attempt.
+	BackoffMultiplier float64
+
+	// RetryableStatusCodes lists the status codes that trigger a retry.
+	// If empty, 429 (Too Many Requests) and every 5xx status but 501 (Not
+	// Implemented) are retried.
+	RetryableStatusCodes []int
+
+	// Idempotent allows retrying non-idempotent methods (POST, PATCH).
+	Idempotent bool
+
+	// NotIdempotent turns off Idempotent in an override, so a method can opt
+	// out of the client policy.
+	NotIdempotent bool
+
+	// IdempotencyKey generates an idempotency key for every call, which makes
+	// any method retryable. The same key is sent on every attempt.
+	IdempotencyKey bool
+
+	// NoIdempotencyKey turns off IdempotencyKey in an override.
+	NoIdempotencyKey bool
+
+	// IdempotencyKeyHeader is the header used to send the idempotency key.
+	// Defaults to DefaultIdempotencyKeyHeader.
+	IdempotencyKeyHeader string
+}
+
+// DefaultRetryPolicy returns a policy with 3 attempts and an exponential
+// backoff starting at 100ms and capped at 5s.
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts:          3,
+		InitialBackoff:       100 * time.Millisecond,
+		MaxBackoff:           5 * time.Second,
+		BackoffMultiplier:    2,
+		IdempotencyKeyHeader: DefaultIdempotencyKeyHeader,
+	}
+}
+
+// merge returns a copy of the policy with the non-zero fields of override applied.
+//
+// A false boolean in override can't be told from an unset one, so
+// NotIdempotent and NoIdempotencyKey turn Idempotent and IdempotencyKey off.
+func (p RetryPolicy) merge(override *RetryPolicy) *RetryPolicy {
+	if override == nil {
+		return &p
+	}
+	if override.MaxAttempts != 0 {
+		p.MaxAttempts = override.MaxAttempts
+	}
+	if override.InitialBackoff != 0 {
+		p.InitialBackoff = override.InitialBackoff
+	}
+	if override.MaxBackoff != 0 {
+		p.MaxBackoff = override.MaxBackoff
+	}
+	if override.BackoffMultiplier != 0 {
+		p.BackoffMultiplier = override.BackoffMultiplier
+	}
+	if len(override.RetryableStatusCodes) > 0 {
+		p.RetryableStatusCodes = override.RetryableStatusCodes
+	}
+	if override.IdempotencyKeyHeader != "" {
+		p.IdempotencyKeyHeader = override.IdempotencyKeyHeader
+	}
+	switch {
+	case override.NotIdempotent:
+		p.Idempotent = false
+	case override.Idempotent:
+		p.Idempotent = true
+	}
+	switch {
+	case override.NoIdempotencyKey:
+		p.IdempotencyKey = false
+	case override.IdempotencyKey:
+		p.IdempotencyKey = true
+	}
+	return &p
+}
+
+// retryableStatus reports whether a response status should be retried.
+func (p *RetryPolicy) retryableStatus(code int) bool {
+	if len(p.RetryableStatusCodes) == 0 {
+		return code == http.StatusTooManyRequests || (code >= 500 && code != http.StatusNotImplemented)
+	}
+	for _, c := range p.RetryableStatusCodes {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
+
+// backoff returns the delay before the given retry (1-based), with jitter.
+func (p *RetryPolicy) backoff(retry int) time.Duration {
+	multiplier := p.BackoffMultiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+	d := float64(p.InitialBackoff) * math.Pow(multiplier, float64(retry-1))
+	if p.MaxBackoff > 0 && d > float64(p.MaxBackoff) {
+		d = float64(p.MaxBackoff)
+	}
+	if d <= 0 {
+		return 0
+	}
+	// Equal jitter: wait at least half of the backoff.
+	half := d / 2
+	return time.Duration(half + mathrand.Float64()*half)
+}
+
+// CallOption configures a single request.
+type CallOption func(*callOptions)
+
+type callOptions struct {
+	operation      string
+	retry          *RetryPolicy
+	idempotencyKey string
+	headers        http.Header
+	query          url.Values
+	timeout        time.Duration
+	metadata       *ResponseMetadata
+	unwrap         string
+	envelope       *json.RawMessage
+}
+
+// ResponseMetadata describes the raw HTTP response of a call.
+// It is filled by the CaptureResponse call option, including for failed calls.
+type ResponseMetadata struct {
+	// StatusCode is the HTTP status code of the last attempt.
+	StatusCode int
+
+	// Header holds the response headers of the last attempt.
+	Header http.Header
+
+	// Attempts is the number of attempts made, including retries.
+	Attempts int
+
+	// RateLimit holds the parsed rate-limit headers, or nil if none were sent.
+	RateLimit *RateLimit
+}
+
+// RateLimit holds rate-limit information parsed from the X-RateLimit-* or
+// RateLimit-* response headers.
+type RateLimit struct {
+	Limit     int       // Requests allowed in the current window
+	Remaining int       // Requests left in the current window
+	Reset     time.Time // When the window resets (zero if unknown)
+}
+
+// Header adds a header to a single request.
+func Header(key, value string) CallOption {
+	return func(o *callOptions) {
+		if o.headers == nil {
+			o.headers = make(http.Header)
+		}
+		o.headers.Add(key, value)
+	}
+}
+
+// Query adds query parameters to a single request.
+func Query(values url.Values) CallOption {
+	return func(o *callOptions) {
+		if o.query == nil {
+			o.query = make(url.Values)
+		}
+		for key, vals := range values {
+			o.query[key] = append(o.query[key], vals...)
+		}
+	}
+}
+
+// Timeout bounds a single call, including retries.
+func Timeout(timeout time.Duration) CallOption {
+	return func(o *callOptions) {
+		o.timeout = timeout
+	}
+}
+
+// CaptureResponse stores the raw response metadata (status, headers,
+// rate-limit info) of the call into metadata.
+func CaptureResponse(metadata *ResponseMetadata) CallOption {
+	return func(o *callOptions) {
+		o.metadata = metadata
+	}
+}
+
+// UnwrapResponse decodes the response message from the JSON value at the given
+// JSON pointer (RFC 6901) of the response body, e.g. "/data" for
+// {"data": {...}, "meta": {...}}. It is the inverse of wrap_response_into and
+// is applied first when both are set. Generated methods pass the
+// unwrap_response option of the RPC.
+func UnwrapResponse(pointer string) CallOption {
+	return func(o *callOptions) {
+		o.unwrap = pointer
+	}
+}
+
+// CaptureEnvelope stores the full response body into envelope when the
+// response is unwrapped (see UnwrapResponse), e.g. to read pagination metadata.
+func CaptureEnvelope(envelope *json.RawMessage) CallOption {
+	return func(o *callOptions) {
+		o.envelope = envelope
+	}
+}
+
+// Operation names the RPC a call belongs to, as reported to the client
+// Instrumentation. Generated methods pass their Operation_<Svc>_<Method> constant.
+func Operation(name string) CallOption {
+	return func(o *callOptions) {
+		o.operation = name
+	}
+}
+
+// RetryOverride overrides the client retry policy for a single request.
+// Zero fields of the override inherit the client policy.
+func RetryOverride(policy *RetryPolicy) CallOption {
+	return func(o *callOptions) {
+		o.retry = policy
+	}
+}
+
+// IdempotencyKey sends the given idempotency key with the request,
+// which makes it retryable regardless of its HTTP method.
+func IdempotencyKey(key string) CallOption {
+	return func(o *callOptions) {
+		o.idempotencyKey = key
+	}
+}
+
+// HTTPClient wraps the standard http.Client with proto+JSON support.
+//
+// This is the core building block for service-specific clients.
+// It's intentionally minimal and designed for code generation.
+type HTTPClient struct {
+	// BaseURL is the API base URL (e.g., "https://data.sandbox.iniciador.com.br")
+	BaseURL string
+
+	// HTTPClient is the underlying HTTP client (configure timeout, transport, etc.)
+	HTTPClient *http.Client
+
+	// RetryPolicy configures retries. A nil policy sends every request once,
+	// unless a RetryOverride call option is given.
+	RetryPolicy *RetryPolicy
+
+	// Headers are sent with every request.
+	Headers http.Header
+
+	// UserAgent is sent as the User-Agent header when non-empty.
+	UserAgent string
+
+	// MarshalOptions configures request encoding. Nil uses camelCase JSON
+	// names and omits zero values.
+	MarshalOptions *protojson.MarshalOptions
+
+	// UnmarshalOptions configures response decoding. Nil discards unknown fields.
+	UnmarshalOptions *protojson.UnmarshalOptions
+
+	// RequestHooks are called before every attempt, after headers are set.
+	RequestHooks []RequestHook
+
+	// ResponseHooks are called after every attempt that received a response.
+	ResponseHooks []ResponseHook
+
+	// ServiceBaseURLs overrides BaseURL per service group (see ForService).
+	ServiceBaseURLs map[string]string
+
+	// Instrumentation observes every call (nil disables instrumentation).
+	Instrumentation Instrumentation
+}
+
+// Instrumentation observes the calls made by an HTTPClient, e.g. to create
+// spans and record metrics.
+type Instrumentation interface {
+	// StartCall is called once per call, before the first attempt. The returned
+	// context is used for every attempt and end is called when the call returns.
+	StartCall(ctx context.Context, call CallInfo) (_ context.Context, end func(CallResult))
+
+	// InjectHeader propagates the call context (e.g., W3C trace context)
+	// into the headers of every attempt.
+	InjectHeader(ctx context.Context, header http.Header)
+}
+
+// CallInfo describes a call before it is sent.
+type CallInfo struct {
+	Operation   string // Operation_<Svc>_<Method> value, or the HTTP method when unknown
+	Method      string // HTTP method
+	URL         string // Full request URL
+	RequestSize int    // Size of the encoded request body
+}
+
+// CallResult describes a finished call.
+type CallResult struct {
+	StatusCode   int   // HTTP status of the last attempt (0 if no response was received)
+	ResponseSize int   // Size of the last response body
+	Attempts     int   // Number of attempts made, including retries
+	Err          error // Error returned to the caller, if any
+}
+
+// RequestHook inspects or mutates an outgoing request. Returning an error aborts the call.
+type RequestHook func(req *http.Request) error
+
+// ResponseHook inspects a received response before it is decoded.
+// Returning an error aborts the call. The body must not be consumed.
+type ResponseHook func(resp *http.Response) error
+
+// ClientOptions holds the configuration of a generated root client.
+type ClientOptions struct {
+	Timeout          time.Duration
+	Transport        http.RoundTripper
+	HTTPClient       *http.Client
+	Headers          http.Header
+	UserAgent        string
+	ServiceBaseURLs  map[string]string
+	MarshalOptions   *protojson.MarshalOptions
+	UnmarshalOptions *protojson.UnmarshalOptions
+	RequestHooks     []RequestHook
+	ResponseHooks    []ResponseHook
+	RetryPolicy      *RetryPolicy
+	Middlewares      []Middleware
+	Instrumentation  Instrumentation
+}
+
+// ClientOption configures a generated root client.
+type ClientOption func(*ClientOptions)
+
+// NewClientOptions applies the options on top of the defaults (30s timeout,
+// http.DefaultTransport).
+func NewClientOptions(options ...ClientOption) *ClientOptions {
+	o := ClientOptions{
+		Timeout:         30 * time.Second,
+		Headers:         make(http.Header),
+		ServiceBaseURLs: make(map[string]string),
+	}
+
+	for _, option := range options {
+		option(&o)
+	}
+
+	return &o
+}
+
+// WithTimeout sets the timeout of the underlying http.Client.
+// It is ignored when WithHTTPClient is used.
+func WithTimeout(timeout time.Duration) ClientOption {
+	return func(o *ClientOptions) {
+		o.Timeout = timeout
+	}
+}
+
+// WithTransport sets the RoundTripper of the underlying http.Client.
+// It is ignored when WithHTTPClient is used.
+func WithTransport(transport http.RoundTripper) ClientOption {
+	return func(o *ClientOptions) {
+		o.Transport = transport
+	}
+}
+
+// WithHTTPClient uses a fully configured http.Client, taking precedence
+// over WithTimeout and WithTransport.
+func WithHTTPClient(client *http.Client) ClientOption {
+	return func(o *ClientOptions) {
+		o.HTTPClient = client
+	}
+}
+
+// WithHeader adds a header sent with every request.
+func WithHeader(key, value string) ClientOption {
+	return func(o *ClientOptions) {
+		o.Headers.Add(key, value)
+	}
+}
+
+// WithUserAgent sets the User-Agent header sent with every request.
+func WithUserAgent(userAgent string) ClientOption {
+	return func(o *ClientOptions) {
+		o.UserAgent = userAgent
+	}
+}
+
+// WithServiceBaseURL overrides the base URL for a service group.
+//
+// The group name is the name of the root client accessor without the
+// "Get" prefix (e.g., "Auth" for GetAuth, "Investments" for GetInvestments).
+func WithServiceBaseURL(group, baseURL string) ClientOption {
+	return func(o *ClientOptions) {
+		o.ServiceBaseURLs[group] = baseURL
+	}
+}
+
+// WithMarshalOptions sets the protojson options used to encode requests.
+func WithMarshalOptions(opts protojson.MarshalOptions) ClientOption {
+	return func(o *ClientOptions) {
+		o.MarshalOptions = &opts
+	}
+}
+
+// WithUnmarshalOptions sets the protojson options used to decode responses.
+func WithUnmarshalOptions(opts protojson.UnmarshalOptions) ClientOption {
+	return func(o *ClientOptions) {
+		o.UnmarshalOptions = &opts
+	}
+}
+
+// WithRequestHook adds a hook called before every attempt.
+func WithRequestHook(hook RequestHook) ClientOption {
+	return func(o *ClientOptions) {
+		o.RequestHooks = append(o.RequestHooks, hook)
+	}
+}
+
+// WithResponseHook adds a hook called after every attempt that received a response.
+func WithResponseHook(hook ResponseHook) ClientOption {
+	return func(o *ClientOptions) {
+		o.ResponseHooks = append(o.ResponseHooks, hook)
+	}
+}
+
+// WithRetryPolicy enables retries (see DefaultRetryPolicy).
+func WithRetryPolicy(policy *RetryPolicy) ClientOption {
+	return func(o *ClientOptions) {
+		o.RetryPolicy = policy
+	}
+}
+
+// WithInstrumentation observes every call with the given instrumentation.
+func WithInstrumentation(instrumentation Instrumentation) ClientOption {
+	return func(o *ClientOptions) {
+		o.Instrumentation = instrumentation
+	}
+}
+
+// NewHTTPClient creates an HTTPClient from client options.
+func NewHTTPClient(baseURL string, opts ...ClientOption) *HTTPClient {
+	options := NewClientOptions(opts...)
+
+	httpClient := options.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{
+			Timeout:   options.Timeout,
+			Transport: options.Transport,
+		}
+	}
+	if len(options.Middlewares) > 0 {
+		clone := *httpClient
+		clone.Transport = Chain(httpClient.Transport, options.Middlewares...)
+		httpClient = &clone
+	}
+
+	return &HTTPClient{
+		BaseURL:          baseURL,
+		HTTPClient:       httpClient,
+		RetryPolicy:      options.RetryPolicy,
+		Headers:          options.Headers,
+		UserAgent:        options.UserAgent,
+		MarshalOptions:   options.MarshalOptions,
+		UnmarshalOptions: options.UnmarshalOptions,
+		RequestHooks:     options.RequestHooks,
+		ResponseHooks:    options.ResponseHooks,
+		ServiceBaseURLs:  options.ServiceBaseURLs,
+		Instrumentation:  options.Instrumentation,
+	}
+}
+
+// ForService returns the client to use for a service group.
+//
+// If a base URL override was configured for the group, a copy of the client
+// using that base URL is returned. Otherwise the client itself is returned.
+func (c *HTTPClient) ForService(group string) *HTTPClient {
+	baseURL, ok := c.ServiceBaseURLs[group]
+	if !ok {
+		return c
+	}
+
+	clone := *c
+	clone.BaseURL = baseURL
+	return &clone
+}
+
+// Do sends a request with the given HTTP method.
+//
+// The request is sent as the JSON body when non-nil; GET requests should pass
+// a nil req. Generated clients use the method helpers below, protoc-gen-go-http
+// clients call Do with the method and path of the HTTP rule.
+//
+// Returns a *StatusError if the response status is not 2xx.
+func (c *HTTPClient) Do(ctx context.Context, method, path string, req proto.Message, resp proto.Message, opts ...CallOption) error {
+	return c.do(ctx, method, path, req, resp, "", opts...)
+}
+
+// Post sends a POST request with a JSON-encoded proto message body.
+//
+// The request is marshaled to JSON using protojson with camelCase field names.
+// The response is unmarshaled from JSON back to a proto message.
+//
+// Parameters:
+//   - ctx: Context for cancellation and timeouts
+//   - path: API path (e.g., "/v1/data/auth")
+//   - req: Proto message to send as JSON body
+//   - resp: Proto message to unmarshal response into
+//   - opts: Per-call options (e.g., Header, Timeout, IdempotencyKey, CaptureResponse)
+//
+// Returns an error if the request fails, a *StatusError if the response status is not 2xx.
+func (c *HTTPClient) Post(ctx context.Context, path string, req proto.Message, resp proto.Message, opts ...CallOption) error {
+	return c.do(ctx, "POST", path, req, resp, "", opts...)
+}
+
+// PostWithWrap sends a POST request and wraps the response into a specified field before unmarshaling.
+//
+// This is useful when the API returns a plain array but proto requires a message wrapper.
+//
+// Parameters:
+//   - ctx: Context for cancellation and timeouts
+//   - path: API path (e.g., "/v1/data/participants")
+//   - req: Proto message to send as JSON body
+//   - resp: Proto message to unmarshal response into
+//   - wrapField: Field name to wrap the response array into (e.g., "response")
+//   - opts: Per-call options (e.g., Header, Timeout, IdempotencyKey, CaptureResponse)
+//
+// Returns an error if the request fails, a *StatusError if the response status is not 2xx.
+func (c *HTTPClient) PostWithWrap(ctx context.Context, path string, req proto.Message, resp proto.Message, wrapField string, opts ...CallOption) error {
+	return c.do(ctx, "POST", path, req, resp, wrapField, opts...)
+}
+
+// Get sends a GET request and unmarshals the JSON response.
+//
+// Parameters:
+//   - ctx: Context for cancellation and timeouts
+//   - path: API path (e.g., "/v1/data/links/123")
+//   - resp: Proto message to unmarshal response into
+//   - opts: Per-call options (e.g., Header, Timeout, IdempotencyKey, CaptureResponse)
+//
+// Returns an error if the request fails, a *StatusError if the response status is not 2xx.
+func (c *HTTPClient) Get(ctx context.Context, path string, resp proto.Message, opts ...CallOption) error {
+	return c.do(ctx, "GET", path, nil, resp, "", opts...)
+}
+
+// GetWithWrap sends a GET request and wraps the response into a specified field before unmarshaling.
+//
+// This is useful when the API returns a plain array but proto requires a message wrapper.
+//
+// Parameters:
+//   - ctx: Context for cancellation and timeouts
+//   - path: API path (e.g., "/v1/data/participants")
+//   - resp: Proto message to unmarshal response into
+//   - wrapField: Field name to wrap the response array into (e.g., "response")
+//   - opts: Per-call options (e.g., Header, Timeout, IdempotencyKey, CaptureResponse)
+//
+// Returns an error if the request fails, a *StatusError if the response status is not 2xx.
+func (c *HTTPClient) GetWithWrap(ctx context.Context, path string, resp proto.Message, wrapField string, opts ...CallOption) error {
+	return c.do(ctx, "GET", path, nil, resp, wrapField, opts...)
+}
+
+// Put sends a PUT request with a JSON-encoded proto message body.
+//
+// The request is marshaled to JSON using protojson with camelCase field names.
+// The response is unmarshaled from JSON back to a proto message.
+//
+// Parameters:
+//   - ctx: Context for cancellation and timeouts
+//   - path: API path (e.g., "/v1/data/resource/123")
+//   - req: Proto message to send as JSON body
+//   - resp: Proto message to unmarshal response into
+//   - opts: Per-call options (e.g., Header, Timeout, IdempotencyKey, CaptureResponse)
+//
+// Returns an error if the request fails, a *StatusError if the response status is not 2xx.
+func (c *HTTPClient) Put(ctx context.Context, path string, req proto.Message, resp proto.Message, opts ...CallOption) error {
+	return c.do(ctx, "PUT", path, req, resp, "", opts...)
+}
+
+// PutWithWrap sends a PUT request and wraps the response into a specified field before unmarshaling.
+//
+// This is useful when the API returns a plain array but proto requires a message wrapper.
+//
+// Parameters:
+//   - ctx: Context for cancellation and timeouts
+//   - path: API path (e.g., "/v1/data/resources")
+//   - req: Proto message to send as JSON body
+//   - resp: Proto message to unmarshal response into
+//   - wrapField: Field name to wrap the response array into (e.g., "response")
+//   - opts: Per-call options (e.g., Header, Timeout, IdempotencyKey, CaptureResponse)
+//
+// Returns an error if the request fails, a *StatusError if the response status is not 2xx.
+func (c *HTTPClient) PutWithWrap(ctx context.Context, path string, req proto.Message, resp proto.Message, wrapField string, opts ...CallOption) error {
+	return c.do(ctx, "PUT", path, req, resp, wrapField, opts...)
+}
+
+// Patch sends a PATCH request with a JSON-encoded proto message body.
+//
+// The request is marshaled to JSON using protojson with camelCase field names.
+// The response is unmarshaled from JSON back to a proto message.
+//
+// Parameters:
+//   - ctx: Context for cancellation and timeouts
+//   - path: API path (e.g., "/v1/data/resource/123")
+//   - req: Proto message to send as JSON body
+//   - resp: Proto message to unmarshal response into
+//   - opts: Per-call options (e.g., Header, Timeout, IdempotencyKey, CaptureResponse)
+//
+// Returns an error if the request fails, a *StatusError if the response status is not 2xx.
+func (c *HTTPClient) Patch(ctx context.Context, path string, req proto.Message, resp proto.Message, opts ...CallOption) error {
+	return c.do(ctx, "PATCH", path, req, resp, "", opts...)
+}
+
+// PatchWithWrap sends a PATCH request and wraps the response into a specified field before unmarshaling.
+//
+// This is useful when the API returns a plain array but proto requires a message wrapper.
+//
+// Parameters:
+//   - ctx: Context for cancellation and timeouts
+//   - path: API path (e.g., "/v1/data/resources")
+//   - req: Proto message to send as JSON body
+//   - resp: Proto message to unmarshal response into
+//   - wrapField: Field name to wrap the response array into (e.g., "response")
+//   - opts: Per-call options (e.g., Header, Timeout, IdempotencyKey, CaptureResponse)
+//
+// Returns an error if the request fails, a *StatusError if the response status is not 2xx.
+func (c *HTTPClient) PatchWithWrap(ctx context.Context, path string, req proto.Message, resp proto.Message, wrapField string, opts ...CallOption) error {
+	return c.do(ctx, "PATCH", path, req, resp, wrapField, opts...)
+}
+
+// Delete sends a DELETE request.
+//
+// Parameters:
+//   - ctx: Context for cancellation and timeouts
+//   - path: API path (e.g., "/v1/data/resource/123")
+//   - req: Proto message to send as JSON body (can be nil)
+//   - resp: Proto message to unmarshal response into
+//   - opts: Per-call options (e.g., Header, Timeout, IdempotencyKey, CaptureResponse)
+//
+// Returns an error if the request fails, a *StatusError if the response status is not 2xx.
+func (c *HTTPClient) Delete(ctx context.Context, path string, req proto.Message, resp proto.Message, opts ...CallOption) error {
+	return c.do(ctx, "DELETE", path, req, resp, "", opts...)
+}
+
+// DeleteWithWrap sends a DELETE request and wraps the response into a specified field before unmarshaling.
+//
+// This is useful when the API returns a plain array but proto requires a message wrapper.
+//
+// Parameters:
+//   - ctx: Context for cancellation and timeouts
+//   - path: API path (e.g., "/v1/data/resources")
+//   - req: Proto message to send as JSON body (can be nil)
+//   - resp: Proto message to unmarshal response into
+//   - wrapField: Field name to wrap the response array into (e.g., "response")
+//   - opts: Per-call options (e.g., Header, Timeout, IdempotencyKey, CaptureResponse)
+//
+// Returns an error if the request fails, a *StatusError if the response status is not 2xx.
+func (c *HTTPClient) DeleteWithWrap(ctx context.Context, path string, req proto.Message, resp proto.Message, wrapField string, opts ...CallOption) error {
+	return c.do(ctx, "DELETE", path, req, resp, wrapField, opts...)
+}
+
+// do performs the actual HTTP request with proto message marshaling.
+//
+// This is the core method that handles:
+// 1. Request marshaling (proto → JSON with camelCase)
+// 2. HTTP request execution with proper headers, retrying transient failures
+// 3. Response unwrapping (if the UnwrapResponse call option is given)
+// 4. Response wrapping (if wrapField is specified)
+// 5. Response unmarshaling (JSON → proto)
+// 6. Error handling
+//
+// Parameters:
+//   - wrapField: If non-empty, wraps the response JSON into this field name before unmarshaling
+//   - opts: Per-call options
+func (c *HTTPClient) do(ctx context.Context, method, path string, req proto.Message, resp proto.Message, wrapField string, opts ...CallOption) (err error) {
+	// Validate HTTP method
+	validMethods := map[string]bool{
+		MethodGET: true, MethodPOST: true, MethodPUT: true,
+		MethodPATCH: true, MethodDELETE: true,
+	}
+	if !validMethods[method] {
+		return fmt.Errorf("unsupported HTTP method: %s", method)
+	}
+
+	callOpts := &callOptions{}
+	for _, opt := range opts {
+		opt(callOpts)
+	}
+
+	if callOpts.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, callOpts.timeout)
+		defer cancel()
+	}
+
+	url := c.BaseURL + path
+	if len(callOpts.query) > 0 {
+		sep := "?"
+		if strings.Contains(path, "?") {
+			sep = "&"
+		}
+		url += sep + callOpts.query.Encode()
+	}
+
+	// Marshal request body if provided
+	var reqBytes []byte
+	if req != nil {
+		var err error
+		marshaler := protojson.MarshalOptions{
+			UseProtoNames:   false, // Use JSON names (camelCase: clientId, accessToken)
+			EmitUnpopulated: false, // Don't include zero values
+		}
+		if c.MarshalOptions != nil {
+			marshaler = *c.MarshalOptions
+		}
+		reqBytes, err = marshaler.Marshal(req)
+		if err != nil {
+			return fmt.Errorf("marshal request: %w", err)
+		}
+	}
+
+	// Report the call to the instrumentation, if any
+	var result CallResult
+	if c.Instrumentation != nil {
+		operation := callOpts.operation
+		if operation == "" {
+			operation = method
+		}
+		var end func(CallResult)
+		ctx, end = c.Instrumentation.StartCall(ctx, CallInfo{
+			Operation:   operation,
+			Method:      method,
+			URL:         url,
+			RequestSize: len(reqBytes),
+		})
+		defer func() {
+			result.Err = err
+			end(result)
+		}()
+	}
+
+	// Resolve the retry policy and the idempotency key shared by all attempts
+	policy := c.retryPolicy(callOpts.retry)
+	idempotencyKey := callOpts.idempotencyKey
+	if idempotencyKey == "" && policy != nil && policy.IdempotencyKey {
+		key, err := newIdempotencyKey()
+		if err != nil {
+			return fmt.Errorf("generate idempotency key: %w", err)
+		}
+		idempotencyKey = key
+	}
+
+	maxAttempts := 1
+	if policy != nil && policy.MaxAttempts > 1 && (isIdempotent(method) || policy.Idempotent || idempotencyKey != "") {
+		maxAttempts = policy.MaxAttempts
+	}
+
+	var respBytes []byte
+	for attempt := 1; ; attempt++ {
+		var body io.Reader
+		if req != nil {
+			body = bytes.NewReader(reqBytes)
+		}
+
+		// Create HTTP request
+		httpReq, err := http.NewRequestWithContext(ctx, method, url, body)
+		if err != nil {
+			return fmt.Errorf("create request: %w", err)
+		}
+
+		// Set headers
+		for key, values := range c.Headers {
+			for _, value := range values {
+				httpReq.Header.Add(key, value)
+			}
+		}
+		if c.UserAgent != "" {
+			httpReq.Header.Set("User-Agent", c.UserAgent)
+		}
+		for key, values := range callOpts.headers {
+			httpReq.Header.Del(key)
+			for _, value := range values {
+				httpReq.Header.Add(key, value)
+			}
+		}
+		httpReq.Header.Set("Accept", "application/json")
+		if req != nil {
+			httpReq.Header.Set("Content-Type", "application/json")
+		}
+		if idempotencyKey != "" {
+			header := DefaultIdempotencyKeyHeader
+			if policy != nil && policy.IdempotencyKeyHeader != "" {
+				header = policy.IdempotencyKeyHeader
+			}
+			httpReq.Header.Set(header, idempotencyKey)
+		}
+
+		if c.Instrumentation != nil {
+			c.Instrumentation.InjectHeader(ctx, httpReq.Header)
+		}
+
+		for _, hook := range c.RequestHooks {
+			if err := hook(httpReq); err != nil {
+				return fmt.Errorf("request hook: %w", err)
+			}
+		}
+
+		// Execute request
+		result.Attempts = attempt
+		httpResp, err := c.HTTPClient.Do(httpReq)
+		if err != nil {
+			if attempt < maxAttempts && ctx.Err() == nil && retryableError(err) {
+				if err := sleep(ctx, policy.backoff(attempt)); err != nil {
+					return fmt.Errorf("send request: %w", err)
+				}
+				continue
+			}
+			return fmt.Errorf("send request: %w", err)
+		}
+
+		result.StatusCode = httpResp.StatusCode
+		if callOpts.metadata != nil {
+			*callOpts.metadata = ResponseMetadata{
+				StatusCode: httpResp.StatusCode,
+				Header:     httpResp.Header,
+				Attempts:   attempt,
+				RateLimit:  parseRateLimit(httpResp.Header),
+			}
+		}
+
+		for _, hook := range c.ResponseHooks {
+			if err := hook(httpResp); err != nil {
+				httpResp.Body.Close()
+				return fmt.Errorf("response hook: %w", err)
+			}
+		}
+
+		// Read response body
+		respBytes, err = io.ReadAll(httpResp.Body)
+		httpResp.Body.Close()
+		result.ResponseSize = len(respBytes)
+		if err != nil {
+			return fmt.Errorf("read response: %w", err)
+		}
+
+		// Check HTTP status
+		if httpResp.StatusCode < 200 || httpResp.StatusCode >= 300 {
+			if attempt < maxAttempts && policy.retryableStatus(httpResp.StatusCode) {
+				delay, ok := retryAfter(httpResp.Header.Get("Retry-After"))
+				if !ok {
+					delay = policy.backoff(attempt)
+				}
+				if policy.MaxBackoff <= 0 || delay <= policy.MaxBackoff {
+					if err := sleep(ctx, delay); err != nil {
+						return fmt.Errorf("HTTP %d: %w", httpResp.StatusCode, err)
+					}
+					continue
+				}
+			}
+
+			return newStatusError(httpResp, respBytes)
+		}
+
+		break
+	}
+
+	// Unmarshal response
+	if resp != nil {
+		// If an unwrap pointer is specified, extract the response from its envelope
+		finalRespBytes := respBytes
+		if callOpts.unwrap != "" {
+			if callOpts.envelope != nil {
+				*callOpts.envelope = respBytes
+			}
+			var err error
+			finalRespBytes, err = binder.JSONPointer(respBytes, callOpts.unwrap)
+			if err != nil {
+				return fmt.Errorf("unwrap response: %w (body: %s)", err, string(respBytes))
+			}
+		}
+
+		// If wrapField is specified, wrap the response JSON into that field
+		if wrapField != "" {
+			// Create a wrapper object: {"fieldName": <original response>}
+			wrapped := make(map[string]json.RawMessage)
+			wrapped[wrapField] = json.RawMessage(finalRespBytes)
+			var err error
+			finalRespBytes, err = json.Marshal(wrapped)
+			if err != nil {
+				return fmt.Errorf("wrap response: %w", err)
+			}
+		}
+
+		unmarshaler := protojson.UnmarshalOptions{
+			DiscardUnknown: true, // Ignore fields not in proto definition
+		}
+		if c.UnmarshalOptions != nil {
+			unmarshaler = *c.UnmarshalOptions
+		}
+		if err := unmarshaler.Unmarshal(finalRespBytes, resp); err != nil {
+			return fmt.Errorf("unmarshal response: %w (body: %s)", err, string(finalRespBytes))
+		}
+	}
+
+	return nil
+}
+
+// retryPolicy returns the effective retry policy for a request, or nil if
+// the request must not be retried.
+func (c *HTTPClient) retryPolicy(override *RetryPolicy) *RetryPolicy {
+	switch {
+	case override == nil && c.RetryPolicy == nil:
+		return nil
+	case c.RetryPolicy == nil:
+		return DefaultRetryPolicy().merge(override)
+	default:
+		return c.RetryPolicy.merge(override)
+	}
+}
+
+// retryableError reports whether a transport error may not happen again on a
+// new attempt: timeouts, refused or reset connections, connections closed by
+// the server and temporary DNS failures. Other errors (invalid URL, TLS
+// handshake, request hook...) are returned right away.
+func retryableError(err error) bool {
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return dnsErr.IsTemporary
+	}
+	return errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// isIdempotent reports whether the HTTP method can be safely retried.
+func isIdempotent(method string) bool {
+	return method == MethodGET || method == MethodPUT || method == MethodDELETE
+}
+
+// retryAfter parses a Retry-After header given in seconds or as an HTTP date.
+func retryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		delay := time.Until(date)
+		if delay < 0 {
+			delay = 0
+		}
+		return delay, true
+	}
+	return 0, false
+}
+
+// parseRateLimit reads the X-RateLimit-* headers, falling back to the
+// RateLimit-* headers. Reset is accepted as a Unix timestamp or as a number
+// of seconds from now.
+func parseRateLimit(header http.Header) *RateLimit {
+	for _, prefix := range []string{"X-RateLimit-", "RateLimit-"} {
+		limit, limitErr := strconv.Atoi(header.Get(prefix + "Limit"))
+		remaining, remainingErr := strconv.Atoi(header.Get(prefix + "Remaining"))
+		if limitErr != nil && remainingErr != nil {
+			continue
+		}
+
+		rateLimit := &RateLimit{Limit: limit, Remaining: remaining}
+		if reset, err := strconv.ParseInt(header.Get(prefix+"Reset"), 10, 64); err == nil {
+			if reset > 1000000000 {
+				rateLimit.Reset = time.Unix(reset, 0)
+			} else {
+				rateLimit.Reset = time.Now().Add(time.Duration(reset) * time.Second)
+			}
+		}
+		return rateLimit
+	}
+	return nil
+}
+
+// sleep waits for the given delay or until the context is done.
+func sleep(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// newIdempotencyKey returns a random 128-bit hex-encoded key.
+func newIdempotencyKey() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,326 @@
+package httpclient
+
+import (
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/getfrontierhq/buf-public-apis/pkg/gohttp/errors"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestExpandPath(t *testing.T) {
+	file := &descriptorpb.FileDescriptorProto{
+		Name:    proto.String("vendors/iniciador/links.proto"),
+		Package: proto.String("vendors.iniciador"),
+		Options: &descriptorpb.FileOptions{GoPackage: proto.String("example.com/go pkg")},
+	}
+
+	tests := []struct {
+		template string
+		expected string // "" on error
+	}{
+		{"/v1/files", "/v1/files"},
+		{"/v1/packages/{package}", "/v1/packages/vendors.iniciador"},
+		{"/v1/files/{name}", "/v1/files/vendors%2Finiciador%2Flinks.proto"},
+		{"/v1/{name=files/**}", "/v1/vendors/iniciador/links.proto"},
+		{"/v1/go/{options.go_package}", "/v1/go/example.com%2Fgo%20pkg"},
+		{"/v1/go/{options.goPackage}:get", "/v1/go/example.com%2Fgo%20pkg:get"},
+		{"/v1/files/{missing}", ""},
+		{"/v1/files/{dependency}", ""},
+		{"/v1/files/{name", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.template, func(t *testing.T) {
+			result, err := ExpandPath(tt.template, file)
+			if tt.expected == "" {
+				if err == nil {
+					t.Fatalf("ExpandPath() = %q, want error", result)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result != tt.expected {
+				t.Errorf("ExpandPath() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestStatusError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/gohttp":
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"message": "link not found", "data": {"id": "l1"}}`))
+		default:
+			w.WriteHeader(http.StatusTeapot)
+			w.Write([]byte(`["unexpected"]`))
+		}
+	}))
+	defer srv.Close()
+
+	client := NewHTTPClient(srv.URL)
+	ctx := context.Background()
+
+	err := client.Do(ctx, http.MethodGet, "/gohttp", nil, &emptypb.Empty{})
+	var statusErr *StatusError
+	if !stderrors.As(err, &statusErr) || statusErr.StatusCode != http.StatusNotFound {
+		t.Fatalf("Do() = %v, want a 404 StatusError", err)
+	}
+	if err.Error() != "HTTP 404: link not found" {
+		t.Errorf("Error() = %q", err.Error())
+	}
+	if !stderrors.Is(err, errors.ErrGeneralNotFound) {
+		t.Errorf("Do() = %v, want errors.ErrGeneralNotFound", err)
+	}
+	var body *errors.Error
+	if !stderrors.As(err, &body) || body.Message != "link not found" {
+		t.Errorf("Do() body = %v, want the decoded error", body)
+	}
+
+	err = client.Do(ctx, http.MethodPost, "/other", wrapperspb.String("x"), &emptypb.Empty{})
+	if !stderrors.As(err, &statusErr) || statusErr.Err != nil || !stderrors.Is(err, errors.ErrGeneralTeapot) {
+		t.Errorf("Do() = %v, want errors.ErrGeneralTeapot without body error", err)
+	}
+	if err.Error() != `HTTP 418: ["unexpected"]` {
+		t.Errorf("Error() = %q", err.Error())
+	}
+}
+
+func TestUnwrapResponse(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data": "l1", "meta": {"next": "2"}, "pages/items": [{"~id": "l2"}]}`))
+	}))
+	defer srv.Close()
+
+	client := NewHTTPClient(srv.URL)
+	ctx := context.Background()
+
+	tests := []struct {
+		pointer  string
+		expected string // "" on error
+	}{
+		{"/data", "l1"},
+		{"/meta/next", "2"},
+		{"/pages~1items/0/~0id", "l2"},
+		{"/missing", ""},
+		{"/pages~1items/1", ""},
+		{"/data/id", ""},
+		{"data", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pointer, func(t *testing.T) {
+			var envelope json.RawMessage
+			resp := &wrapperspb.StringValue{}
+			err := client.Do(ctx, http.MethodGet, "/links", nil, resp, UnwrapResponse(tt.pointer), CaptureEnvelope(&envelope))
+			if tt.expected == "" {
+				if err == nil {
+					t.Fatalf("Do() = %q, want error", resp.Value)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.Value != tt.expected {
+				t.Errorf("Do() = %q, want %q", resp.Value, tt.expected)
+			}
+			var meta struct {
+				Meta struct{ Next string } `json:"meta"`
+			}
+			if err := json.Unmarshal(envelope, &meta); err != nil || meta.Meta.Next != "2" {
+				t.Errorf("envelope = %s, want the full response body", envelope)
+			}
+		})
+	}
+}
+
+// retryServer answers the statuses in order, then 200, recording the
+// idempotency key and body of every attempt.
+type retryServer struct {
+	statuses   []int
+	retryAfter string
+	keys       []string
+	bodies     []string
+}
+
+func (s *retryServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	s.keys = append(s.keys, r.Header.Get(DefaultIdempotencyKeyHeader)+r.Header.Get("X-Request-Key"))
+	s.bodies = append(s.bodies, string(body))
+	w.Header().Set("Content-Type", "application/json")
+	if attempt := len(s.keys); attempt <= len(s.statuses) {
+		if s.retryAfter != "" {
+			w.Header().Set("Retry-After", s.retryAfter)
+		}
+		w.WriteHeader(s.statuses[attempt-1])
+		w.Write([]byte(`{"message": "try again"}`))
+		return
+	}
+	w.Write([]byte(`"ok"`))
+}
+
+func fastRetryPolicy(maxAttempts int) *RetryPolicy {
+	return &RetryPolicy{MaxAttempts: maxAttempts, InitialBackoff: time.Millisecond, MaxBackoff: 10 * time.Millisecond}
+}
+
+func TestRetry(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		statuses   []int
+		retryAfter string
+		policy     *RetryPolicy
+		opts       []CallOption
+		attempts   int
+		status     int // Status of the returned StatusError (0 on success)
+	}{
+		{"5xx", MethodGET, []int{503, 500}, "", fastRetryPolicy(3), nil, 3, 0},
+		{"attempts exhausted", MethodGET, []int{503, 502, 500}, "", fastRetryPolicy(3), nil, 3, 500},
+		{"429 Retry-After", MethodDELETE, []int{429}, "0", fastRetryPolicy(2), nil, 2, 0},
+		{"Retry-After over MaxBackoff", MethodGET, []int{429}, "86400", fastRetryPolicy(3), nil, 1, 429},
+		{"not retryable", MethodGET, []int{404}, "", fastRetryPolicy(3), nil, 1, 404},
+		{"501", MethodGET, []int{501}, "", fastRetryPolicy(3), nil, 1, 501},
+		{"POST", MethodPOST, []int{503}, "", fastRetryPolicy(3), nil, 1, 503},
+		{"POST with key", MethodPOST, []int{503, 503}, "", fastRetryPolicy(3), []CallOption{IdempotencyKey("k1")}, 3, 0},
+		{"no policy", MethodGET, []int{503}, "", nil, nil, 1, 503},
+		{"override", MethodGET, []int{503, 503}, "", fastRetryPolicy(2), []CallOption{RetryOverride(&RetryPolicy{MaxAttempts: 3})}, 3, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := &retryServer{statuses: tt.statuses, retryAfter: tt.retryAfter}
+			ts := httptest.NewServer(srv)
+			defer ts.Close()
+
+			client := NewHTTPClient(ts.URL, WithRetryPolicy(tt.policy))
+			var req proto.Message
+			if tt.method != MethodGET {
+				req = wrapperspb.String("payload")
+			}
+			var metadata ResponseMetadata
+			err := client.Do(context.Background(), tt.method, "/links", req, &wrapperspb.StringValue{}, append(tt.opts, CaptureResponse(&metadata))...)
+
+			var statusErr *StatusError
+			switch {
+			case tt.status == 0 && err != nil:
+				t.Fatalf("Do() = %v", err)
+			case tt.status != 0 && (!stderrors.As(err, &statusErr) || statusErr.StatusCode != tt.status):
+				t.Fatalf("Do() = %v, want HTTP %d", err, tt.status)
+			}
+			if len(srv.keys) != tt.attempts || metadata.Attempts != tt.attempts {
+				t.Errorf("attempts = %d (metadata %d), want %d", len(srv.keys), metadata.Attempts, tt.attempts)
+			}
+			for i := range srv.bodies {
+				if srv.bodies[i] != srv.bodies[0] || srv.keys[i] != srv.keys[0] {
+					t.Errorf("attempt %d sent key %q body %q, want the key and body of the first attempt %q %q",
+						i+1, srv.keys[i], srv.bodies[i], srv.keys[0], srv.bodies[0])
+				}
+			}
+		})
+	}
+}
+
+func TestRetryTransportErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		attempts int
+	}{
+		{"timeout", &net.OpError{Op: "dial", Net: "tcp", Err: os.ErrDeadlineExceeded}, 3},
+		{"connection refused", &net.OpError{Op: "dial", Net: "tcp", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}, 3},
+		{"connection reset", &net.OpError{Op: "read", Net: "tcp", Err: os.NewSyscallError("read", syscall.ECONNRESET)}, 3},
+		{"closed by server", io.EOF, 3},
+		{"temporary DNS failure", &net.DNSError{Err: "server misbehaving", Name: "api.example.com", IsTemporary: true}, 3},
+		{"unknown host", &net.DNSError{Err: "no such host", Name: "api.example.com", IsNotFound: true}, 1},
+		{"TLS handshake", stderrors.New("tls: failed to verify certificate"), 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attempts := 0
+			transport := RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				attempts++
+				return nil, tt.err
+			})
+
+			client := NewHTTPClient("http://api.example.com", WithTransport(transport), WithRetryPolicy(fastRetryPolicy(3)))
+			if err := client.Get(context.Background(), "/links", &wrapperspb.StringValue{}); !stderrors.Is(err, tt.err) {
+				t.Errorf("Get() = %v, want %v", err, tt.err)
+			}
+			if attempts != tt.attempts {
+				t.Errorf("attempts = %d, want %d", attempts, tt.attempts)
+			}
+		})
+	}
+}
+
+func TestRetryIdempotencyKey(t *testing.T) {
+	srv := &retryServer{statuses: []int{503, 503}}
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	client := NewHTTPClient(ts.URL, WithRetryPolicy(fastRetryPolicy(3)))
+	override := RetryOverride(&RetryPolicy{IdempotencyKey: true, IdempotencyKeyHeader: "X-Request-Key"})
+	if err := client.Post(context.Background(), "/links", wrapperspb.String("payload"), &wrapperspb.StringValue{}, override); err != nil {
+		t.Fatal(err)
+	}
+	if len(srv.keys) != 3 || len(srv.keys[0]) != 32 || srv.keys[1] != srv.keys[0] || srv.keys[2] != srv.keys[0] {
+		t.Errorf("keys = %q, want the same generated key on 3 attempts", srv.keys)
+	}
+	if srv.bodies[2] != `"payload"` {
+		t.Errorf("body of the last attempt = %q", srv.bodies[2])
+	}
+}
+
+func TestRetryPolicyMerge(t *testing.T) {
+	client := &HTTPClient{RetryPolicy: &RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Second, RetryableStatusCodes: []int{503}}}
+	got := client.retryPolicy(&RetryPolicy{MaxBackoff: time.Minute, Idempotent: true})
+	want := &RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Second, MaxBackoff: time.Minute, RetryableStatusCodes: []int{503}, Idempotent: true}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("retryPolicy() = %+v, want %+v", got, want)
+	}
+	if client.RetryPolicy.MaxBackoff != 0 {
+		t.Error("retryPolicy() modified the client policy")
+	}
+
+	client.RetryPolicy.Idempotent = true
+	client.RetryPolicy.IdempotencyKey = true
+	got = client.retryPolicy(&RetryPolicy{NotIdempotent: true, NoIdempotencyKey: true})
+	if got.Idempotent || got.IdempotencyKey {
+		t.Errorf("retryPolicy() = %+v, want Idempotent and IdempotencyKey turned off", got)
+	}
+	got = client.retryPolicy(&RetryPolicy{MaxAttempts: 3})
+	if !got.Idempotent || !got.IdempotencyKey {
+		t.Errorf("retryPolicy() = %+v, want Idempotent and IdempotencyKey inherited", got)
+	}
+
+	got = (&HTTPClient{}).retryPolicy(&RetryPolicy{MaxAttempts: 5})
+	want = DefaultRetryPolicy()
+	want.MaxAttempts = 5
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("retryPolicy() = %+v, want the default policy with 5 attempts", got)
+	}
+	if (&HTTPClient{}).retryPolicy(nil) != nil {
+		t.Error("retryPolicy(nil) without client policy is not nil")
+	}
+}
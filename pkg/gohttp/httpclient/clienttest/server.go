@@ -0,0 +1,352 @@
+// Package clienttest is the runtime of the fake servers generated by
+// protoc-gen-go-http-client with fakeserver=true.
+//
+// The generated clienttest package of a client embeds Server and registers
+// its typed Handle<Service><Method> methods as Routes. Requests go through
+// the real client, so tests exercise the actual HTTP serialization.
+package clienttest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Server is a fake HTTP server for the generated client.
+//
+// Routes without a registered handler reply 404 Not Found, or 405 Method Not
+// Allowed when a handler is registered for another method on the same path.
+type Server struct {
+	*httptest.Server
+
+	mux *http.ServeMux
+
+	mu       sync.Mutex
+	handlers map[string]Route // by pattern
+	calls    []Call
+}
+
+// Call records a request received by the server.
+type Call struct {
+	Operation string        // Operation name (e.g., "/vendors.iniciador.LinksService/GetLink")
+	Method    string        // HTTP method
+	Path      string        // Request path
+	Header    http.Header   // Request headers
+	Request   proto.Message // Decoded request, path parameters included
+}
+
+// StatusError makes a handler reply with the given HTTP status and JSON body.
+type StatusError struct {
+	StatusCode int
+	Body       any // Encoded as JSON; nil replies {"message": http.StatusText(StatusCode)}
+}
+
+// Error implements error.
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("HTTP %d", e.StatusCode)
+}
+
+// NewServer starts a fake server, closed when the test ends.
+func NewServer(tb testing.TB) *Server {
+	s := &Server{
+		mux:      http.NewServeMux(),
+		handlers: make(map[string]Route),
+	}
+	s.Server = httptest.NewServer(s.mux)
+	tb.Cleanup(s.Close)
+	return s
+}
+
+// Calls returns all the recorded calls, in order.
+func (s *Server) Calls() []Call {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Call(nil), s.calls...)
+}
+
+// CallsTo returns the recorded calls to the given operation, in order.
+func (s *Server) CallsTo(operation string) []Call {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var calls []Call
+	for _, call := range s.calls {
+		if call.Operation == operation {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+// Reset clears the recorded calls. Handlers are kept.
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls = nil
+}
+
+// Route is an RPC served by the server.
+type Route struct {
+	Operation  string               // Operation name (e.g., "/vendors.iniciador.LinksService/GetLink")
+	Method     string               // HTTP method
+	Path       string               // Path template (e.g., "/v1/links/{link.id}")
+	WrapField  string               // wrap_response_into option of the RPC ("" if none)
+	Unwrap     string               // unwrap_response option of the RPC ("" if none)
+	NewRequest func() proto.Message // Returns an empty request
+	Handler    func(ctx context.Context, req proto.Message) (proto.Message, error)
+}
+
+// Handle registers or replaces the handler of a route.
+func (s *Server) Handle(r Route) {
+	pattern, _ := muxPattern(r.Method, r.Path)
+
+	s.mu.Lock()
+	_, registered := s.handlers[pattern]
+	s.handlers[pattern] = r
+	s.mu.Unlock()
+
+	if !registered {
+		s.mux.HandleFunc(pattern, func(w http.ResponseWriter, req *http.Request) {
+			s.mu.Lock()
+			r := s.handlers[pattern]
+			s.mu.Unlock()
+			s.serve(r, w, req)
+		})
+	}
+}
+
+// serve decodes the request, records the call and writes the handler response.
+func (s *Server) serve(r Route, w http.ResponseWriter, httpReq *http.Request) {
+	req := r.NewRequest()
+
+	body, err := io.ReadAll(httpReq.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if len(body) > 0 {
+		if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(body, req); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("decode request: %w", err))
+			return
+		}
+	}
+	_, params := muxPattern(r.Method, r.Path)
+	for i, name := range params {
+		if err := setField(req.ProtoReflect(), name, httpReq.PathValue(wildcard(i))); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("path parameter %s: %w", name, err))
+			return
+		}
+	}
+
+	s.mu.Lock()
+	s.calls = append(s.calls, Call{
+		Operation: r.Operation,
+		Method:    httpReq.Method,
+		Path:      httpReq.URL.Path,
+		Header:    httpReq.Header.Clone(),
+		Request:   req,
+	})
+	s.mu.Unlock()
+
+	resp, err := r.Handler(httpReq.Context(), req)
+	if err != nil {
+		var statusErr *StatusError
+		if errors.As(err, &statusErr) {
+			writeJSON(w, statusErr.StatusCode, statusErr.Body)
+			return
+		}
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	out, err := protojson.Marshal(resp)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, fmt.Errorf("encode response: %w", err))
+		return
+	}
+	if r.WrapField != "" {
+		// The client wraps the raw response into WrapField: reply with its value only
+		out, err = unwrapField(resp, out, r.WrapField)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+	}
+	if r.Unwrap != "" {
+		// The client unwraps the response from its envelope: reply with the envelope
+		out = envelope(out, r.Unwrap)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(out)
+}
+
+// muxPattern returns the http.ServeMux pattern of a route and the field
+// paths of its path parameters. Parameters become positional wildcards, since
+// field paths such as "link.id" are not valid wildcard names.
+// Example: "GET", "/v1/links/{link.id}" -> "GET /v1/links/{p0}", ["link.id"]
+func muxPattern(method, path string) (string, []string) {
+	var params []string
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			name, rest := strings.CutSuffix(segment[1:len(segment)-1], "...")
+			segments[i] = "{" + wildcard(len(params))
+			if rest {
+				segments[i] += "..."
+			}
+			segments[i] += "}"
+			params = append(params, name)
+		}
+	}
+	return method + " " + strings.Join(segments, "/"), params
+}
+
+// wildcard returns the name of the i-th wildcard of a mux pattern.
+func wildcard(i int) string {
+	return "p" + strconv.Itoa(i)
+}
+
+// setField sets a scalar field of msg, possibly nested (e.g., "link.id"),
+// from its string representation.
+func setField(msg protoreflect.Message, name, value string) error {
+	parts := strings.Split(name, ".")
+	for _, part := range parts[:len(parts)-1] {
+		fd := msg.Descriptor().Fields().ByName(protoreflect.Name(part))
+		if fd == nil || fd.Message() == nil {
+			return fmt.Errorf("no message field %q", part)
+		}
+		msg = msg.Mutable(fd).Message()
+	}
+
+	fd := msg.Descriptor().Fields().ByName(protoreflect.Name(parts[len(parts)-1]))
+	if fd == nil {
+		return fmt.Errorf("no field %q", name)
+	}
+
+	var v protoreflect.Value
+	switch fd.Kind() {
+	case protoreflect.StringKind:
+		v = protoreflect.ValueOfString(value)
+	case protoreflect.BoolKind:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		v = protoreflect.ValueOfBool(b)
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		i, err := strconv.ParseInt(value, 10, 32)
+		if err != nil {
+			return err
+		}
+		v = protoreflect.ValueOfInt32(int32(i))
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		i, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return err
+		}
+		v = protoreflect.ValueOfInt64(i)
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		u, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			return err
+		}
+		v = protoreflect.ValueOfUint32(uint32(u))
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		u, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return err
+		}
+		v = protoreflect.ValueOfUint64(u)
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return err
+		}
+		if fd.Kind() == protoreflect.FloatKind {
+			v = protoreflect.ValueOfFloat32(float32(f))
+		} else {
+			v = protoreflect.ValueOfFloat64(f)
+		}
+	case protoreflect.EnumKind:
+		ev := fd.Enum().Values().ByName(protoreflect.Name(value))
+		if ev == nil {
+			return fmt.Errorf("unknown enum value %q", value)
+		}
+		v = protoreflect.ValueOfEnum(ev.Number())
+	default:
+		return fmt.Errorf("unsupported kind %s", fd.Kind())
+	}
+
+	msg.Set(fd, v)
+	return nil
+}
+
+// unwrapField returns the JSON value of the wrapField field of resp.
+func unwrapField(resp proto.Message, encoded []byte, wrapField string) ([]byte, error) {
+	fields := resp.ProtoReflect().Descriptor().Fields()
+	fd := fields.ByName(protoreflect.Name(wrapField))
+	if fd == nil {
+		fd = fields.ByJSONName(wrapField)
+	}
+	if fd == nil {
+		return nil, fmt.Errorf("response has no field %q to unwrap", wrapField)
+	}
+
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(encoded, &object); err != nil {
+		return nil, fmt.Errorf("unwrap response: %w", err)
+	}
+	if value, ok := object[fd.JSONName()]; ok {
+		return value, nil
+	}
+	return []byte("null"), nil
+}
+
+// envelope nests the JSON value at the JSON pointer of an empty envelope,
+// e.g. {"data": value} for "/data". Array indexes are padded with nulls.
+func envelope(value []byte, pointer string) []byte {
+	tokens := strings.Split(pointer[1:], "/")
+	for i := len(tokens) - 1; i >= 0; i-- {
+		token := strings.NewReplacer("~1", "/", "~0", "~").Replace(tokens[i])
+		if index, err := strconv.Atoi(token); err == nil && index >= 0 {
+			array := make([]json.RawMessage, index+1)
+			for j := range array {
+				array[j] = json.RawMessage("null")
+			}
+			array[index] = value
+			value, _ = json.Marshal(array)
+			continue
+		}
+		value, _ = json.Marshal(map[string]json.RawMessage{token: value})
+	}
+	return value
+}
+
+// writeError replies with the status and {"message": err}.
+func writeError(w http.ResponseWriter, statusCode int, err error) {
+	writeJSON(w, statusCode, map[string]string{"message": err.Error()})
+}
+
+// writeJSON replies with the status and the JSON-encoded body.
+func writeJSON(w http.ResponseWriter, statusCode int, body any) {
+	if body == nil {
+		body = map[string]string{"message": http.StatusText(statusCode)}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,67 @@
+package clienttest
+
+import (
+	"context"
+	stderrors "errors"
+	"net/http"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/getfrontierhq/buf-public-apis/pkg/gohttp/httpclient"
+)
+
+func TestServer(t *testing.T) {
+	srv := NewServer(t)
+	srv.Handle(Route{
+		Operation:  "/test.FileService/GetGoPackage",
+		Method:     http.MethodGet,
+		Path:       "/v1/files/{name}/go/{options.go_package}",
+		NewRequest: func() proto.Message { return &descriptorpb.FileDescriptorProto{} },
+		Handler: func(ctx context.Context, req proto.Message) (proto.Message, error) {
+			file := req.(*descriptorpb.FileDescriptorProto)
+			if file.GetName() == "missing.proto" {
+				return nil, &StatusError{StatusCode: http.StatusNotFound}
+			}
+			return wrapperspb.String(file.GetName() + ":" + file.GetOptions().GetGoPackage()), nil
+		},
+	})
+
+	client := httpclient.NewHTTPClient(srv.URL)
+	resp := &wrapperspb.StringValue{}
+	if err := client.Get(context.Background(), "/v1/files/links.proto/go/example.com", resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Value != "links.proto:example.com" {
+		t.Errorf("response = %q, want the path parameters", resp.Value)
+	}
+
+	err := client.Get(context.Background(), "/v1/files/missing.proto/go/example.com", resp)
+	var statusErr *httpclient.StatusError
+	if !stderrors.As(err, &statusErr) || statusErr.StatusCode != http.StatusNotFound {
+		t.Errorf("Get() = %v, want HTTP 404", err)
+	}
+	if err := client.Post(context.Background(), "/v1/files/links.proto/go/example.com", nil, resp); err == nil {
+		t.Error("Post() succeeded on a GET route")
+	}
+
+	calls := srv.CallsTo("/test.FileService/GetGoPackage")
+	if len(calls) != 2 || calls[0].Path != "/v1/files/links.proto/go/example.com" {
+		t.Fatalf("calls = %v, want the 2 GET calls", calls)
+	}
+	if file := calls[0].Request.(*descriptorpb.FileDescriptorProto); file.GetOptions().GetGoPackage() != "example.com" {
+		t.Errorf("recorded request = %v", file)
+	}
+}
+
+func TestMuxPattern(t *testing.T) {
+	pattern, params := muxPattern("GET", "/v1/{parent.name}/links/{id}/{path...}")
+	if pattern != "GET /v1/{p0}/links/{p1}/{p2...}" {
+		t.Errorf("pattern = %q", pattern)
+	}
+	if len(params) != 3 || params[0] != "parent.name" || params[1] != "id" || params[2] != "path" {
+		t.Errorf("params = %q", params)
+	}
+}
@@ -0,0 +1,76 @@
+package httpclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/getfrontierhq/buf-public-apis/pkg/gohttp/errors"
+)
+
+// maxErrorBody bounds the response body quoted by StatusError.Error.
+const maxErrorBody = 512
+
+// StatusError is returned when a response status is not 2xx.
+//
+// It unwraps to the errors.ErrorMap sentinel of its status code, so callers
+// can test the status with errors.Is (e.g., errors.Is(err, errors.ErrGeneralNotFound)),
+// and to the *errors.Error sent by gohttp servers, if any.
+type StatusError struct {
+	// StatusCode is the HTTP status code of the response.
+	StatusCode int
+
+	// Header holds the response headers.
+	Header http.Header
+
+	// Body is the raw response body.
+	Body []byte
+
+	// Err is the {"message", "data"} error decoded from the body, or nil.
+	Err *errors.Error
+}
+
+// newStatusError returns the error of a non-2xx response whose body was read.
+func newStatusError(resp *http.Response, body []byte) *StatusError {
+	e := &StatusError{
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+		Body:       body,
+	}
+
+	var decoded errors.Error
+	if json.Unmarshal(body, &decoded) == nil && decoded.Message != "" {
+		e.Err = &decoded
+	}
+	return e
+}
+
+// Error implements error.
+func (e *StatusError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("HTTP %d: %s", e.StatusCode, e.Err.Message)
+	}
+
+	body := bytes.TrimSpace(e.Body)
+	if len(body) == 0 {
+		return fmt.Sprintf("HTTP %d: %s", e.StatusCode, http.StatusText(e.StatusCode))
+	}
+	if len(body) > maxErrorBody {
+		body = append(body[:maxErrorBody:maxErrorBody], "..."...)
+	}
+	return fmt.Sprintf("HTTP %d: %s", e.StatusCode, body)
+}
+
+// Unwrap returns the decoded body error and the errors.ErrorMap sentinel of
+// the status code, when they exist.
+func (e *StatusError) Unwrap() []error {
+	var errs []error
+	if e.Err != nil {
+		errs = append(errs, e.Err)
+	}
+	if sentinel := errors.ErrorMap[e.StatusCode]; sentinel != nil {
+		errs = append(errs, sentinel)
+	}
+	return errs
+}
@@ -0,0 +1,262 @@
+package httpclient
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Middleware wraps a RoundTripper with additional behavior.
+type Middleware func(next http.RoundTripper) http.RoundTripper
+
+// RoundTripperFunc adapts a function to the http.RoundTripper interface.
+type RoundTripperFunc func(req *http.Request) (*http.Response, error)
+
+// RoundTrip implements http.RoundTripper.
+func (f RoundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// Chain wraps base with the given middlewares.
+//
+// The first middleware is the outermost one: it sees the request first and
+// the response last. A nil base uses http.DefaultTransport.
+//
+// Example:
+//
+//	transport := Chain(http.DefaultTransport,
+//		RequestIDMiddleware(""),
+//		RedactionMiddleware("X-Api-Key"),
+//		LoggingMiddleware(slog.Default()),
+//	)
+func Chain(base http.RoundTripper, middlewares ...Middleware) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		base = middlewares[i](base)
+	}
+	return base
+}
+
+// WithMiddleware wraps the client transport with the given middlewares (see Chain).
+//
+// When used together with WithHTTPClient, the provided client is copied and
+// its transport is wrapped.
+func WithMiddleware(middlewares ...Middleware) ClientOption {
+	return func(o *ClientOptions) {
+		o.Middlewares = append(o.Middlewares, middlewares...)
+	}
+}
+
+// DefaultRequestIDHeader is the header used to propagate request IDs.
+const DefaultRequestIDHeader = "X-Request-ID"
+
+type requestIDKey struct{}
+
+// ContextWithRequestID returns a context carrying the given request ID.
+func ContextWithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestIDFromContext returns the request ID carried by the context.
+func RequestIDFromContext(ctx context.Context) (string, bool) {
+	requestID, ok := ctx.Value(requestIDKey{}).(string)
+	return requestID, ok && requestID != ""
+}
+
+// RequestIDMiddleware propagates a request ID on every outgoing request.
+//
+// The ID is taken from the request context (see ContextWithRequestID) or
+// generated when missing. Requests that already carry the header are left
+// untouched. An empty header uses DefaultRequestIDHeader.
+func RequestIDMiddleware(header string) Middleware {
+	if header == "" {
+		header = DefaultRequestIDHeader
+	}
+
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if req.Header.Get(header) != "" {
+				return next.RoundTrip(req)
+			}
+
+			requestID, ok := RequestIDFromContext(req.Context())
+			if !ok {
+				b := make([]byte, 16)
+				if _, err := rand.Read(b); err != nil {
+					return nil, err
+				}
+				requestID = hex.EncodeToString(b)
+			}
+
+			req = req.Clone(ContextWithRequestID(req.Context(), requestID))
+			req.Header.Set(header, requestID)
+			return next.RoundTrip(req)
+		})
+	}
+}
+
+// RequestMetrics describes a single round trip.
+type RequestMetrics struct {
+	Method     string        // HTTP method
+	Host       string        // Target host
+	Path       string        // URL path
+	StatusCode int           // Response status (0 if the request failed)
+	Duration   time.Duration // Time until the response headers were received
+	Err        error         // Transport error, if any
+}
+
+// MetricsRecorder receives the metrics of every round trip.
+type MetricsRecorder interface {
+	RecordRequest(ctx context.Context, metrics RequestMetrics)
+}
+
+// MetricsRecorderFunc adapts a function to the MetricsRecorder interface.
+type MetricsRecorderFunc func(ctx context.Context, metrics RequestMetrics)
+
+// RecordRequest implements MetricsRecorder.
+func (f MetricsRecorderFunc) RecordRequest(ctx context.Context, metrics RequestMetrics) {
+	f(ctx, metrics)
+}
+
+// MetricsMiddleware reports every round trip to the recorder.
+func MetricsMiddleware(recorder MetricsRecorder) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			start := time.Now()
+			resp, err := next.RoundTrip(req)
+
+			metrics := RequestMetrics{
+				Method:   req.Method,
+				Host:     req.URL.Host,
+				Path:     req.URL.Path,
+				Duration: time.Since(start),
+				Err:      err,
+			}
+			if resp != nil {
+				metrics.StatusCode = resp.StatusCode
+			}
+			recorder.RecordRequest(req.Context(), metrics)
+
+			return resp, err
+		})
+	}
+}
+
+// defaultRedactedNames are always redacted by LoggingMiddleware.
+var defaultRedactedNames = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie"}
+
+type redactionKey struct{}
+
+// RedactionMiddleware marks header and query parameter names as sensitive.
+//
+// Their values are replaced with "[REDACTED]" by LoggingMiddleware, which
+// must come after this middleware in the chain. Names are case-insensitive.
+// Authorization, Proxy-Authorization, Cookie and Set-Cookie are always redacted.
+func RedactionMiddleware(names ...string) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			redacted, _ := req.Context().Value(redactionKey{}).([]string)
+			redacted = append(append([]string(nil), redacted...), names...)
+			ctx := context.WithValue(req.Context(), redactionKey{}, redacted)
+			return next.RoundTrip(req.WithContext(ctx))
+		})
+	}
+}
+
+// isRedacted reports whether a header or query parameter name is sensitive.
+func isRedacted(ctx context.Context, name string) bool {
+	for _, candidate := range defaultRedactedNames {
+		if strings.EqualFold(candidate, name) {
+			return true
+		}
+	}
+	redacted, _ := ctx.Value(redactionKey{}).([]string)
+	for _, candidate := range redacted {
+		if strings.EqualFold(candidate, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactURL returns the URL with sensitive query parameter values redacted.
+func redactURL(ctx context.Context, u *url.URL) string {
+	query := u.Query()
+	for name := range query {
+		if isRedacted(ctx, name) {
+			query.Set(name, "[REDACTED]")
+		}
+	}
+
+	redacted := *u
+	redacted.RawQuery = query.Encode()
+	redacted.User = nil
+	return redacted.String()
+}
+
+// redactHeader returns the headers as a log group with sensitive values redacted.
+func redactHeader(ctx context.Context, header http.Header) slog.Attr {
+	attrs := make([]any, 0, len(header))
+	for name, values := range header {
+		value := strings.Join(values, ", ")
+		if isRedacted(ctx, name) {
+			value = "[REDACTED]"
+		}
+		attrs = append(attrs, slog.String(name, value))
+	}
+	return slog.Group("headers", attrs...)
+}
+
+// LoggingMiddleware logs every round trip with the given logger.
+//
+// Successful requests are logged at Info level, 4xx and 5xx responses at Warn
+// level and transport errors at Error level. Request headers are included at
+// Debug level. Sensitive values are redacted (see RedactionMiddleware).
+// A nil logger uses slog.Default().
+func LoggingMiddleware(logger *slog.Logger) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			log := logger
+			if log == nil {
+				log = slog.Default()
+			}
+
+			ctx := req.Context()
+			start := time.Now()
+			resp, err := next.RoundTrip(req)
+
+			attrs := []slog.Attr{
+				slog.String("method", req.Method),
+				slog.String("url", redactURL(ctx, req.URL)),
+				slog.Duration("duration", time.Since(start)),
+			}
+			if requestID, ok := RequestIDFromContext(ctx); ok {
+				attrs = append(attrs, slog.String("request_id", requestID))
+			}
+			if log.Enabled(ctx, slog.LevelDebug) {
+				attrs = append(attrs, redactHeader(ctx, req.Header))
+			}
+
+			switch {
+			case err != nil:
+				attrs = append(attrs, slog.String("error", err.Error()))
+				log.LogAttrs(ctx, slog.LevelError, "http request failed", attrs...)
+			case resp.StatusCode >= 400:
+				attrs = append(attrs, slog.Int("status", resp.StatusCode))
+				log.LogAttrs(ctx, slog.LevelWarn, "http request", attrs...)
+			default:
+				attrs = append(attrs, slog.Int("status", resp.StatusCode))
+				log.LogAttrs(ctx, slog.LevelInfo, "http request", attrs...)
+			}
+
+			return resp, err
+		})
+	}
+}
@@ -0,0 +1,180 @@
+package httpclient
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName identifies the spans and metrics emitted by the client.
+const instrumentationName = "github.com/getfrontierhq/buf-public-apis/pkg/gohttp/httpclient"
+
+// OperationKey is the attribute holding the operation name on spans and metrics.
+const OperationKey = attribute.Key("gohttp.operation")
+
+// OTelOption configures the OpenTelemetry instrumentation.
+type OTelOption func(*otelConfig)
+
+type otelConfig struct {
+	tracerProvider trace.TracerProvider
+	meterProvider  metric.MeterProvider
+	propagator     propagation.TextMapPropagator
+}
+
+// WithTracerProvider sets the tracer provider (default: the global one).
+func WithTracerProvider(provider trace.TracerProvider) OTelOption {
+	return func(c *otelConfig) {
+		c.tracerProvider = provider
+	}
+}
+
+// WithMeterProvider sets the meter provider (default: the global one).
+func WithMeterProvider(provider metric.MeterProvider) OTelOption {
+	return func(c *otelConfig) {
+		c.meterProvider = provider
+	}
+}
+
+// WithPropagator sets the propagator used to inject the trace context
+// (default: W3C trace context).
+func WithPropagator(propagator propagation.TextMapPropagator) OTelOption {
+	return func(c *otelConfig) {
+		c.propagator = propagator
+	}
+}
+
+// WithOpenTelemetry instruments the client with OpenTelemetry tracing and metrics.
+//
+// Example:
+//
+//	client := NewIniciadorClient(baseURL,
+//		httpclient.WithOpenTelemetry(httpclient.WithTracerProvider(tp)),
+//	)
+func WithOpenTelemetry(opts ...OTelOption) ClientOption {
+	return WithInstrumentation(NewOTelInstrumentation(opts...))
+}
+
+type otelInstrumentation struct {
+	tracer     trace.Tracer
+	propagator propagation.TextMapPropagator
+
+	duration     metric.Float64Histogram
+	requestSize  metric.Int64Histogram
+	responseSize metric.Int64Histogram
+}
+
+// NewOTelInstrumentation returns an Instrumentation that creates a client
+// span per call and records the http.client.request.duration,
+// http.client.request.body.size and http.client.response.body.size histograms.
+func NewOTelInstrumentation(opts ...OTelOption) Instrumentation {
+	cfg := otelConfig{
+		tracerProvider: otel.GetTracerProvider(),
+		meterProvider:  otel.GetMeterProvider(),
+		propagator:     propagation.TraceContext{},
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	meter := cfg.meterProvider.Meter(instrumentationName)
+	i := &otelInstrumentation{
+		tracer:     cfg.tracerProvider.Tracer(instrumentationName),
+		propagator: cfg.propagator,
+	}
+	// Instrument creation only fails on invalid names; the returned
+	// instruments are still usable no-ops.
+	i.duration, _ = meter.Float64Histogram("http.client.request.duration",
+		metric.WithUnit("s"),
+		metric.WithDescription("Duration of HTTP client calls, including retries."))
+	i.requestSize, _ = meter.Int64Histogram("http.client.request.body.size",
+		metric.WithUnit("By"),
+		metric.WithDescription("Size of HTTP client request bodies."))
+	i.responseSize, _ = meter.Int64Histogram("http.client.response.body.size",
+		metric.WithUnit("By"),
+		metric.WithDescription("Size of HTTP client response bodies."))
+	return i
+}
+
+// StartCall implements Instrumentation.
+func (i *otelInstrumentation) StartCall(ctx context.Context, call CallInfo) (context.Context, func(CallResult)) {
+	start := time.Now()
+	attrs := []attribute.KeyValue{
+		OperationKey.String(call.Operation),
+		semconv.HTTPRequestMethodKey.String(call.Method),
+	}
+	spanAttrs := []attribute.KeyValue{
+		semconv.HTTPRequestBodySize(call.RequestSize),
+	}
+	if u, err := url.Parse(call.URL); err == nil {
+		attrs = append(attrs, semconv.ServerAddress(u.Hostname()))
+		if port, err := strconv.Atoi(u.Port()); err == nil {
+			attrs = append(attrs, semconv.ServerPort(port))
+		}
+		spanAttrs = append(spanAttrs, semconv.URLFull(urlWithoutQuery(u)))
+	}
+
+	ctx, span := i.tracer.Start(ctx, call.Operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...),
+		trace.WithAttributes(spanAttrs...),
+	)
+
+	return ctx, func(result CallResult) {
+		defer span.End()
+
+		if result.StatusCode != 0 {
+			attrs = append(attrs, semconv.HTTPResponseStatusCode(result.StatusCode))
+		}
+		switch {
+		case result.StatusCode >= 400:
+			attrs = append(attrs, semconv.ErrorTypeKey.String(strconv.Itoa(result.StatusCode)))
+		case result.Err != nil:
+			attrs = append(attrs, semconv.ErrorTypeKey.String(fmt.Sprintf("%T", result.Err)))
+		}
+
+		span.SetAttributes(attrs...)
+		span.SetAttributes(semconv.HTTPResponseBodySize(result.ResponseSize))
+		if result.Attempts > 1 {
+			span.SetAttributes(semconv.HTTPRequestResendCount(result.Attempts - 1))
+		}
+		if result.Err != nil {
+			span.RecordError(result.Err)
+			span.SetStatus(codes.Error, result.Err.Error())
+		}
+
+		set := metric.WithAttributes(attrs...)
+		i.duration.Record(ctx, time.Since(start).Seconds(), set)
+		i.requestSize.Record(ctx, int64(call.RequestSize), set)
+		i.responseSize.Record(ctx, int64(result.ResponseSize), set)
+	}
+}
+
+// InjectHeader implements Instrumentation.
+func (i *otelInstrumentation) InjectHeader(ctx context.Context, header http.Header) {
+	i.propagator.Inject(ctx, propagation.HeaderCarrier(header))
+}
+
+// urlWithoutQuery returns the URL without its query string, fragment and
+// credentials, which may carry tokens or API keys. Unlike LoggingMiddleware,
+// spans don't know the names redacted by RedactionMiddleware, so the whole
+// query is dropped.
+func urlWithoutQuery(u *url.URL) string {
+	redacted := *u
+	redacted.User = nil
+	redacted.RawQuery = ""
+	redacted.ForceQuery = false
+	redacted.Fragment = ""
+	redacted.RawFragment = ""
+	return redacted.String()
+}
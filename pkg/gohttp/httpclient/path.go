@@ -0,0 +1,99 @@
+package httpclient
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// ExpandPath fills the variables of a google.api.http path template with the
+// fields of req.
+//
+// Variables name a field by its proto or JSON name, possibly nested
+// (e.g., "{link.id}"). Values are path-escaped, except for variables with a
+// multi-segment pattern (e.g., "{name=accounts/*}"), whose slashes are kept.
+//
+// Example:
+//
+//	ExpandPath("/v1/links/{id}/accounts/{account_id}", &pb.GetAccountRequest{Id: "l 1", AccountId: "a1"})
+//	// "/v1/links/l%201/accounts/a1"
+func ExpandPath(template string, req proto.Message) (string, error) {
+	var b strings.Builder
+	rest := template
+	for {
+		start := strings.IndexByte(rest, '{')
+		if start < 0 {
+			b.WriteString(rest)
+			return b.String(), nil
+		}
+		end := strings.IndexByte(rest[start:], '}')
+		if end < 0 {
+			return "", fmt.Errorf("path %q: unterminated variable", template)
+		}
+		end += start
+
+		name, pattern, _ := strings.Cut(rest[start+1:end], "=")
+		value, err := fieldValue(req.ProtoReflect(), name)
+		if err != nil {
+			return "", fmt.Errorf("path %q: %w", template, err)
+		}
+
+		b.WriteString(rest[:start])
+		if strings.Contains(pattern, "/") || strings.Contains(pattern, "**") {
+			segments := strings.Split(value, "/")
+			for i, segment := range segments {
+				segments[i] = url.PathEscape(segment)
+			}
+			b.WriteString(strings.Join(segments, "/"))
+		} else {
+			b.WriteString(url.PathEscape(value))
+		}
+		rest = rest[end+1:]
+	}
+}
+
+// fieldValue returns the string representation of a scalar field of msg,
+// possibly nested (e.g., "link.id").
+func fieldValue(msg protoreflect.Message, name string) (string, error) {
+	parts := strings.Split(name, ".")
+	for i, part := range parts {
+		fields := msg.Descriptor().Fields()
+		fd := fields.ByName(protoreflect.Name(part))
+		if fd == nil {
+			fd = fields.ByJSONName(part)
+		}
+		if fd == nil {
+			return "", fmt.Errorf("no field %q in %s", name, msg.Descriptor().FullName())
+		}
+		if fd.IsList() || fd.IsMap() {
+			return "", fmt.Errorf("field %q is not a scalar", name)
+		}
+
+		if i < len(parts)-1 {
+			if fd.Message() == nil {
+				return "", fmt.Errorf("field %q is not a message", part)
+			}
+			msg = msg.Get(fd).Message()
+			continue
+		}
+
+		value := msg.Get(fd)
+		switch fd.Kind() {
+		case protoreflect.MessageKind, protoreflect.GroupKind:
+			return "", fmt.Errorf("field %q is not a scalar", name)
+		case protoreflect.EnumKind:
+			if ev := fd.Enum().Values().ByNumber(value.Enum()); ev != nil {
+				return string(ev.Name()), nil
+			}
+			return fmt.Sprint(int32(value.Enum())), nil
+		case protoreflect.BytesKind:
+			return string(value.Bytes()), nil
+		default:
+			return value.String(), nil
+		}
+	}
+	return "", fmt.Errorf("empty path variable")
+}
@@ -1,10 +1,14 @@
 package option
 
+import "encoding/json"
+
 type BinderOptions struct {
-	Headers     map[string]any
-	ContentType ContentType
-	Operation   string
-	RequestID   string
+	Headers        map[string]any
+	ContentType    ContentType
+	Operation      string
+	RequestID      string
+	UnwrapResponse string
+	Envelope       *json.RawMessage
 }
 
 type BinderOption func(*BinderOptions)
@@ -51,3 +55,19 @@ func WithRequestID(requestID string) BinderOption {
 		o.RequestID = requestID
 	}
 }
+
+// WithUnwrapResponse decodes the response message from the JSON value at the
+// given JSON pointer (e.g., "/data") of the response body.
+func WithUnwrapResponse(pointer string) BinderOption {
+	return func(o *BinderOptions) {
+		o.UnwrapResponse = pointer
+	}
+}
+
+// WithCaptureEnvelope stores the full response body into envelope when the
+// response is unwrapped (see WithUnwrapResponse).
+func WithCaptureEnvelope(envelope *json.RawMessage) BinderOption {
+	return func(o *BinderOptions) {
+		o.Envelope = envelope
+	}
+}
@@ -0,0 +1,64 @@
+package option
+
+import (
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ServerOptions configures the handlers registered by gohttp.RegisterService.
+//
+// Instrumentation is opt-in: spans are only created when a TracerProvider is
+// set and metrics are only recorded when a MeterProvider is set.
+type ServerOptions struct {
+	TracerProvider trace.TracerProvider
+	MeterProvider  metric.MeterProvider
+	Propagator     propagation.TextMapPropagator
+}
+
+type ServerOption func(*ServerOptions)
+
+func NewServerOptions(options ...ServerOption) *ServerOptions {
+	o := ServerOptions{}
+
+	for _, option := range options {
+		option(&o)
+	}
+
+	if o.Propagator == nil {
+		o.Propagator = propagation.TraceContext{}
+	}
+
+	return &o
+}
+
+// WithTelemetry enables tracing and metrics with the global OpenTelemetry
+// providers. The trace context is still extracted with WithPropagator's value,
+// W3C trace context by default.
+func WithTelemetry() ServerOption {
+	return func(o *ServerOptions) {
+		o.TracerProvider = otel.GetTracerProvider()
+		o.MeterProvider = otel.GetMeterProvider()
+	}
+}
+
+func WithTracerProvider(provider trace.TracerProvider) ServerOption {
+	return func(o *ServerOptions) {
+		o.TracerProvider = provider
+	}
+}
+
+func WithMeterProvider(provider metric.MeterProvider) ServerOption {
+	return func(o *ServerOptions) {
+		o.MeterProvider = provider
+	}
+}
+
+// WithPropagator sets the propagator used to extract the incoming trace
+// context. Defaults to W3C trace context.
+func WithPropagator(propagator propagation.TextMapPropagator) ServerOption {
+	return func(o *ServerOptions) {
+		o.Propagator = propagator
+	}
+}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/getfrontierhq/buf-public-apis/pkg/gohttp/binder"
 	potErrors "github.com/getfrontierhq/buf-public-apis/pkg/gohttp/errors"
+	"github.com/getfrontierhq/buf-public-apis/pkg/gohttp/option"
 	"github.com/go-chi/chi/v5"
 )
 
@@ -21,6 +22,7 @@ type (
 
 	MethodDescriptor struct {
 		MethodName string
+		Operation  string
 
 		HttpMethod string
 		HttpPath   string
@@ -72,7 +74,7 @@ func httpHandlerWrapper(impl interface{}, handler MethodHandlerFunc) http.Handle
 	}
 }
 
-func RegisterServiceWithChi(desc *ServiceDescriptor, impl interface{}, router chi.Router) http.Handler {
+func RegisterServiceWithChi(desc *ServiceDescriptor, impl interface{}, router chi.Router, opts ...option.ServerOption) http.Handler {
 	if impl != nil {
 		ht := reflect.TypeOf(desc.HandlerType).Elem()
 		st := reflect.TypeOf(impl)
@@ -81,18 +83,22 @@ func RegisterServiceWithChi(desc *ServiceDescriptor, impl interface{}, router ch
 		}
 	}
 
+	tel := newTelemetry(option.NewServerOptions(opts...))
+
 	for _, method := range desc.Methods {
+		handler := tel.wrap(method, httpHandlerWrapper(impl, method.Handler))
+
 		switch method.HttpMethod {
 		case http.MethodGet:
-			router.Get(method.HttpPath, httpHandlerWrapper(impl, method.Handler))
+			router.Get(method.HttpPath, handler)
 		case http.MethodPost:
-			router.Post(method.HttpPath, httpHandlerWrapper(impl, method.Handler))
+			router.Post(method.HttpPath, handler)
 		case http.MethodPut:
-			router.Put(method.HttpPath, httpHandlerWrapper(impl, method.Handler))
+			router.Put(method.HttpPath, handler)
 		case http.MethodPatch:
-			router.Patch(method.HttpPath, httpHandlerWrapper(impl, method.Handler))
+			router.Patch(method.HttpPath, handler)
 		case http.MethodDelete:
-			router.Delete(method.HttpPath, httpHandlerWrapper(impl, method.Handler))
+			router.Delete(method.HttpPath, handler)
 		default:
 			panic("pot: RegisterService found unsupported HTTP method: " + method.HttpMethod)
 		}
@@ -101,7 +107,7 @@ func RegisterServiceWithChi(desc *ServiceDescriptor, impl interface{}, router ch
 	return router
 }
 
-func RegisterService(desc *ServiceDescriptor, impl interface{}) http.Handler {
+func RegisterService(desc *ServiceDescriptor, impl interface{}, opts ...option.ServerOption) http.Handler {
 	router := chi.NewRouter()
-	return RegisterServiceWithChi(desc, impl, router)
+	return RegisterServiceWithChi(desc, impl, router, opts...)
 }
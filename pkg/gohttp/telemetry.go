@@ -0,0 +1,171 @@
+package gohttp
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/getfrontierhq/buf-public-apis/pkg/gohttp/option"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName identifies the spans and metrics emitted by gohttp.
+const instrumentationName = "github.com/getfrontierhq/buf-public-apis/pkg/gohttp"
+
+// OperationKey is the attribute holding the operation name
+// (Operation_<Svc>_<Method>) on spans and metrics.
+const OperationKey = attribute.Key("gohttp.operation")
+
+type telemetry struct {
+	tracer     trace.Tracer
+	propagator propagation.TextMapPropagator
+
+	duration     metric.Float64Histogram
+	requestSize  metric.Int64Histogram
+	responseSize metric.Int64Histogram
+}
+
+// newTelemetry returns nil when neither tracing nor metrics are enabled.
+func newTelemetry(options *option.ServerOptions) *telemetry {
+	if options.TracerProvider == nil && options.MeterProvider == nil {
+		return nil
+	}
+
+	t := &telemetry{propagator: options.Propagator}
+	if options.TracerProvider != nil {
+		t.tracer = options.TracerProvider.Tracer(instrumentationName)
+	}
+	if options.MeterProvider != nil {
+		meter := options.MeterProvider.Meter(instrumentationName)
+		// Instrument creation only fails on invalid names; a failed
+		// instrument is a no-op and must not break the server.
+		t.duration, _ = meter.Float64Histogram("http.server.request.duration",
+			metric.WithUnit("s"),
+			metric.WithDescription("Duration of HTTP server requests."))
+		t.requestSize, _ = meter.Int64Histogram("http.server.request.body.size",
+			metric.WithUnit("By"),
+			metric.WithDescription("Size of HTTP server request bodies."))
+		t.responseSize, _ = meter.Int64Histogram("http.server.response.body.size",
+			metric.WithUnit("By"),
+			metric.WithDescription("Size of HTTP server response bodies."))
+	}
+
+	return t
+}
+
+// wrap instruments next with a server span named after the method operation
+// and records the duration and body sizes of every request.
+func (t *telemetry) wrap(method MethodDescriptor, next http.HandlerFunc) http.HandlerFunc {
+	if t == nil {
+		return next
+	}
+
+	name := method.Operation
+	if name == "" {
+		name = method.HttpMethod + " " + method.HttpPath
+	}
+	attrs := []attribute.KeyValue{
+		OperationKey.String(name),
+		semconv.HTTPRequestMethodKey.String(method.HttpMethod),
+		semconv.HTTPRoute(method.HttpPath),
+	}
+
+	return func(rw http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		ctx := t.propagator.Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+
+		var span trace.Span
+		if t.tracer != nil {
+			host := r.Host
+			if h, _, err := net.SplitHostPort(host); err == nil {
+				host = h
+			}
+			ctx, span = t.tracer.Start(ctx, name,
+				trace.WithSpanKind(trace.SpanKindServer),
+				trace.WithAttributes(attrs...),
+				trace.WithAttributes(
+					semconv.URLPath(r.URL.Path),
+					semconv.ServerAddress(host),
+					semconv.UserAgentOriginal(r.UserAgent()),
+				))
+			defer span.End()
+		}
+
+		body := &countingReader{ReadCloser: r.Body}
+		if r.Body != nil && r.Body != http.NoBody {
+			r.Body = body
+		}
+		w := &recordingWriter{ResponseWriter: rw, statusCode: http.StatusOK}
+
+		next(w, r.WithContext(ctx))
+
+		statusAttrs := append(attrs[:len(attrs):len(attrs)], semconv.HTTPResponseStatusCode(w.statusCode))
+		if w.statusCode >= 500 {
+			statusAttrs = append(statusAttrs, semconv.ErrorTypeKey.String(strconv.Itoa(w.statusCode)))
+		}
+
+		if span != nil {
+			span.SetAttributes(
+				semconv.HTTPResponseStatusCode(w.statusCode),
+				semconv.HTTPRequestBodySize(int(body.n)),
+				semconv.HTTPResponseBodySize(int(w.n)),
+			)
+			if w.statusCode >= 500 {
+				span.SetStatus(codes.Error, http.StatusText(w.statusCode))
+			}
+		}
+
+		if t.duration != nil {
+			set := metric.WithAttributes(statusAttrs...)
+			t.duration.Record(ctx, time.Since(start).Seconds(), set)
+			t.requestSize.Record(ctx, body.n, set)
+			t.responseSize.Record(ctx, w.n, set)
+		}
+	}
+}
+
+// countingReader counts the bytes read from a request body.
+type countingReader struct {
+	io.ReadCloser
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.ReadCloser.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// recordingWriter records the status code and the bytes written to a response.
+type recordingWriter struct {
+	http.ResponseWriter
+	statusCode  int
+	wroteHeader bool
+	n           int64
+}
+
+func (w *recordingWriter) WriteHeader(statusCode int) {
+	if !w.wroteHeader {
+		w.statusCode = statusCode
+		w.wroteHeader = true
+	}
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *recordingWriter) Write(p []byte) (int, error) {
+	w.wroteHeader = true
+	n, err := w.ResponseWriter.Write(p)
+	w.n += int64(n)
+	return n, err
+}
+
+func (w *recordingWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,136 @@
+package gohttp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/getfrontierhq/buf-public-apis/pkg/gohttp/option"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type echoServer interface {
+	Echo(ctx context.Context, in map[string]any) (map[string]any, error)
+}
+
+type echoImpl struct{}
+
+func (echoImpl) Echo(_ context.Context, in map[string]any) (map[string]any, error) {
+	return in, nil
+}
+
+var echoDesc = ServiceDescriptor{
+	ServiceName: "test.EchoService",
+	HandlerType: (*echoServer)(nil),
+	Methods: []MethodDescriptor{
+		{
+			MethodName: "Echo",
+			Operation:  "/test.EchoService/Echo",
+			HttpMethod: http.MethodPost,
+			HttpPath:   "/echo/{id}",
+			Handler: func(ctx context.Context, srv interface{}, dec DecoderFunc, _ MiddlewareFunc) (interface{}, error) {
+				return srv.(echoServer).Echo(ctx, map[string]any{"ok": true})
+			},
+		},
+	},
+}
+
+func TestTelemetry(t *testing.T) {
+	spans := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans))
+	reader := sdkmetric.NewManualReader()
+	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+
+	handler := RegisterService(&echoDesc, echoImpl{},
+		option.WithTracerProvider(tp),
+		option.WithMeterProvider(mp),
+	)
+
+	parent := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{1},
+		SpanID:     trace.SpanID{2},
+		TraceFlags: trace.FlagsSampled,
+	})
+	req := httptest.NewRequest(http.MethodPost, "/echo/42", strings.NewReader(`{"a":1}`))
+	propagation.TraceContext{}.Inject(trace.ContextWithSpanContext(context.Background(), parent), propagation.HeaderCarrier(req.Header))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+
+	ended := spans.Ended()
+	if len(ended) != 1 {
+		t.Fatalf("got %d spans, want 1", len(ended))
+	}
+	span := ended[0]
+	if span.Name() != "/test.EchoService/Echo" {
+		t.Errorf("span name = %q", span.Name())
+	}
+	if span.SpanKind() != trace.SpanKindServer {
+		t.Errorf("span kind = %v", span.SpanKind())
+	}
+	if span.Parent().TraceID() != parent.TraceID() || span.Parent().SpanID() != parent.SpanID() {
+		t.Errorf("span parent = %v, want %v", span.Parent(), parent)
+	}
+	if span.Status().Code == codes.Error {
+		t.Errorf("span status = %v", span.Status())
+	}
+	want := map[attribute.Key]attribute.Value{
+		"http.request.method":       attribute.StringValue("POST"),
+		"http.route":                attribute.StringValue("/echo/{id}"),
+		"url.path":                  attribute.StringValue("/echo/42"),
+		"http.response.status_code": attribute.IntValue(200),
+		OperationKey:                attribute.StringValue("/test.EchoService/Echo"),
+	}
+	got := map[attribute.Key]attribute.Value{}
+	for _, kv := range span.Attributes() {
+		got[kv.Key] = kv.Value
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("attribute %s = %v, want %v", k, got[k].Emit(), v.Emit())
+		}
+	}
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatal(err)
+	}
+	histograms := map[string]bool{}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			switch data := m.Data.(type) {
+			case metricdata.Histogram[float64]:
+				histograms[m.Name] = len(data.DataPoints) == 1 && data.DataPoints[0].Count == 1
+			case metricdata.Histogram[int64]:
+				histograms[m.Name] = len(data.DataPoints) == 1 && data.DataPoints[0].Count == 1
+			}
+		}
+	}
+	for _, name := range []string{
+		"http.server.request.duration",
+		"http.server.request.body.size",
+		"http.server.response.body.size",
+	} {
+		if !histograms[name] {
+			t.Errorf("histogram %s not recorded once", name)
+		}
+	}
+}
+
+func TestTelemetryDisabled(t *testing.T) {
+	if tel := newTelemetry(option.NewServerOptions()); tel != nil {
+		t.Fatalf("newTelemetry() = %v, want nil without providers", tel)
+	}
+}